@@ -1,15 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/nshmdayo/nft-platform-sample/internal/apikey"
 	"github.com/nshmdayo/nft-platform-sample/internal/config"
 	"github.com/nshmdayo/nft-platform-sample/internal/database"
 	"github.com/nshmdayo/nft-platform-sample/internal/handlers"
+	"github.com/nshmdayo/nft-platform-sample/internal/keyset"
+	"github.com/nshmdayo/nft-platform-sample/internal/mail"
+	"github.com/nshmdayo/nft-platform-sample/internal/middleware"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
 	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/revocation"
 	"github.com/nshmdayo/nft-platform-sample/internal/router"
 	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"github.com/nshmdayo/nft-platform-sample/internal/throttle"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/internal/worker"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
 	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
 )
 
@@ -37,32 +55,217 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(database.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(database.DB)
+	sessionRepo := repository.NewSessionRepository(database.DB)
+	nonceRepo := repository.NewWalletNonceRepository(database.DB)
+	emailTokenRepo := repository.NewEmailTokenRepository(database.DB)
+	loginFailureRepo := repository.NewLoginFailureRepository(database.DB)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(database.DB)
+	mfaChallengeRepo := repository.NewMFAChallengeRepository(database.DB)
+	settingRepo := repository.NewSettingRepository(database.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(database.DB)
 	paperRepo := repository.NewPaperRepository(database.DB)
 	reviewRepo := repository.NewReviewRepository(database.DB)
+	decisionRepo := repository.NewEditorialDecisionRepository(database.DB)
+	assignmentRepo := repository.NewReviewAssignmentRepository(database.DB)
+	nftRepo := repository.NewNFTRepository(database.DB)
+	mintJobRepo := repository.NewMintJobRepository(database.DB)
+	revocations := revocation.NewStore(repository.NewRevokedTokenRepository(database.DB), 0)
+	loginThrottle := throttle.New(repository.NewThrottleRepository(database.DB))
+	apiKeys := apikey.NewVerifier(apiKeyRepo, userRepo)
 	logger.Info("Repositories initialized")
 
+	// Initialize blockchain minter
+	var minter nft.Minter
+	switch cfg.Ethereum.Backend {
+	case "ethereum":
+		ethMinter, err := nft.NewEthereumMinter(cfg.Ethereum)
+		if err != nil {
+			log.Fatal("Failed to initialize Ethereum minter:", err)
+		}
+		minter = ethMinter
+		logger.Info("Ethereum minter initialized", "rpc_url", cfg.Ethereum.RPCURL, "from", ethMinter.From().Hex())
+	case "simulated":
+		minter = nft.NewSimulatedChain(cfg.Ethereum.PaperContractAddr, cfg.Ethereum.ReviewContractAddr)
+		logger.Info("Simulated chain minter initialized")
+	default:
+		log.Fatal("Unknown BLOCKCHAIN_BACKEND: ", cfg.Ethereum.Backend)
+	}
+
+	// Initialize file storage
+	fileStorage, err := storage.New(cfg.IPFS)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	logger.Info("File storage initialized", "backend", cfg.IPFS.Backend)
+
+	// Initialize outgoing mail
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+	}
+	logger.Info("Mailer initialized", "backend", cfg.Mail.Backend)
+	if _, err := time.ParseDuration(cfg.Account.VerificationTTL); err != nil {
+		log.Fatal("Invalid EMAIL_VERIFICATION_TTL:", err)
+	}
+	if _, err := time.ParseDuration(cfg.Account.PasswordResetTTL); err != nil {
+		log.Fatal("Invalid PASSWORD_RESET_TTL:", err)
+	}
+
+	// Validate login protection settings
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+	failureWindow, err := time.ParseDuration(cfg.Login.FailureWindow)
+	if err != nil {
+		log.Fatal("Invalid LOGIN_FAILURE_WINDOW:", err)
+	}
+	lockout, err := time.ParseDuration(cfg.Login.Lockout)
+	if err != nil {
+		log.Fatal("Invalid LOGIN_LOCKOUT:", err)
+	}
+	if _, err := time.ParseDuration(cfg.Login.BaseDelay); err != nil {
+		log.Fatal("Invalid LOGIN_BASE_DELAY:", err)
+	}
+	if _, err := time.ParseDuration(cfg.Login.MaxDelay); err != nil {
+		log.Fatal("Invalid LOGIN_MAX_DELAY:", err)
+	}
+	registrationWindow, err := time.ParseDuration(cfg.Login.RegistrationWindow)
+	if err != nil {
+		log.Fatal("Invalid REGISTER_WINDOW:", err)
+	}
+
+	if _, err := time.ParseDuration(cfg.MFA.ChallengeTTL); err != nil {
+		log.Fatal("Invalid MFA_CHALLENGE_TTL:", err)
+	}
+
+	// Initialize JWT signing keys
+	keys, err := keyset.New(cfg.JWT.KeysDir, cfg.JWT.Algorithm)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+	tokens := utils.JWTOptions{Keys: keys, Issuer: cfg.JWT.Issuer, Audience: cfg.JWT.Audience}
+	logger.Info("JWT signing keys loaded", "dir", cfg.JWT.KeysDir, "keys", len(keys.Keys()), "kid", keys.Signing().ID)
+
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg)
-	paperService := service.NewPaperService(paperRepo)
-	reviewService := service.NewReviewService(reviewRepo, paperRepo)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, sessionRepo, nonceRepo, mintJobRepo, emailTokenRepo, loginFailureRepo, recoveryCodeRepo, mfaChallengeRepo, settingRepo, apiKeyRepo, revocations, loginThrottle, tokens, mailer, cfg)
+	paperService := service.NewPaperService(paperRepo, userRepo, assignmentRepo, fileStorage, int64(cfg.IPFS.MaxUploadMB)<<20)
+	reviewRubrics := service.NewReviewRubrics(settingRepo)
+	reviewService := service.NewReviewService(reviewRepo, assignmentRepo, paperRepo, userRepo, reviewRubrics)
+	decisionService := service.NewDecisionService(decisionRepo, reviewRepo, paperRepo, settingRepo)
+	reviewPolicies := service.NewReviewPolicies(settingRepo, paperRepo, assignmentRepo)
+	nftService := service.NewNFTService(nftRepo, userRepo, paperRepo, reviewRepo, mintJobRepo, minter, fileStorage, cfg)
 	logger.Info("Services initialized")
 
+	// Start mint workers
+	pollInterval, err := time.ParseDuration(cfg.MintQueue.PollInterval)
+	if err != nil {
+		log.Fatal("Invalid MINT_POLL_INTERVAL:", err)
+	}
+	mintWorker := worker.NewMintWorker(worker.MintWorkerConfig{
+		Workers:      cfg.MintQueue.Workers,
+		PollInterval: pollInterval,
+	}, mintJobRepo, nftService)
+	mintWorker.Start()
+
+	// Start the signing key rotator
+	rotationInterval, err := time.ParseDuration(cfg.JWT.RotationInterval)
+	if err != nil {
+		log.Fatal("Invalid JWT_ROTATION_INTERVAL:", err)
+	}
+	keyRetention, err := time.ParseDuration(cfg.JWT.KeyRetention)
+	if err != nil {
+		log.Fatal("Invalid JWT_KEY_RETENTION:", err)
+	}
+	accessExpiresIn, err := time.ParseDuration(cfg.JWT.ExpiresIn)
+	if err != nil {
+		log.Fatal("Invalid JWT_EXPIRES_IN:", err)
+	}
+	if keyRetention < accessExpiresIn {
+		log.Fatal("JWT_KEY_RETENTION must be at least JWT_EXPIRES_IN")
+	}
+	keyRotator := worker.NewKeyRotator(worker.KeyRotatorConfig{
+		Interval:  rotationInterval,
+		Retention: keyRetention,
+	}, keys)
+	keyRotator.Start()
+
+	// Start pruning expired login throttling state
+	throttlePruner := worker.NewThrottlePruner(worker.ThrottlePrunerConfig{
+		MaxWindow: max(failureWindow, lockout, registrationWindow),
+	}, loginThrottle)
+	throttlePruner.Start()
+
+	// Start the chain indexer, which follows transfers made outside the API
+	var indexer *worker.ChainIndexer
+	if cfg.Ethereum.Backend == "ethereum" && cfg.Indexer.Enabled {
+		indexerInterval, err := time.ParseDuration(cfg.Indexer.PollInterval)
+		if err != nil {
+			log.Fatal("Invalid INDEXER_POLL_INTERVAL:", err)
+		}
+		indexer = worker.NewChainIndexer(worker.ChainIndexerConfig{
+			Contracts: map[string]string{
+				cfg.Ethereum.PaperContractAddr:  models.NFTTypePaper,
+				cfg.Ethereum.ReviewContractAddr: models.NFTTypeReview,
+			},
+			Confirmations: uint64(cfg.Indexer.Confirmations),
+			StartBlock:    uint64(cfg.Indexer.StartBlock),
+			BatchBlocks:   uint64(cfg.Indexer.BatchBlocks),
+			PollInterval:  indexerInterval,
+		}, ethereum.NewClient(cfg.Ethereum.RPCURL), nftRepo, repository.NewCheckpointRepository(database.DB))
+		indexer.Start()
+	}
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	paperHandler := handlers.NewPaperHandler(paperService)
-	reviewHandler := handlers.NewReviewHandler(reviewService)
+	paperHandler := handlers.NewPaperHandler(paperService, reviewPolicies)
+	reviewHandler := handlers.NewReviewHandler(reviewService, decisionService, reviewPolicies)
+	nftHandler := handlers.NewNFTHandler(nftService, reviewPolicies)
+	adminHandler := handlers.NewAdminHandler(authService, reviewPolicies, reviewRubrics, decisionService)
 	logger.Info("Handlers initialized")
 
 	// Initialize router
-	r := router.NewRouter(cfg, authHandler, paperHandler, reviewHandler)
+	r := router.NewRouter(cfg, trustedProxies, tokens, revocations, apiKeys, authHandler, paperHandler, reviewHandler, nftHandler, adminHandler)
 	handler := r.SetupRoutes()
-	logger.Info("Router setup completed")
+	logger.Info("Router setup completed", "routes", len(r.Routes()))
 
 	// Start server
 	port := ":" + cfg.Server.Port
-	logger.Info("Server starting", "port", port)
+	server := &http.Server{Addr: port, Handler: handler}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		logger.Info("Server starting", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
 
-	if err := http.ListenAndServe(port, handler); err != nil {
-		log.Fatal("Failed to start server:", err)
+	<-ctx.Done()
+	logger.Info("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down server", "error", err)
+	}
+	if err := mintWorker.Stop(shutdownCtx); err != nil {
+		logger.Error("Failed to stop mint worker", "error", err)
+	}
+	if err := keyRotator.Stop(shutdownCtx); err != nil {
+		logger.Error("Failed to stop key rotator", "error", err)
+	}
+	if err := throttlePruner.Stop(shutdownCtx); err != nil {
+		logger.Error("Failed to stop throttle pruner", "error", err)
+	}
+	if indexer != nil {
+		if err := indexer.Stop(shutdownCtx); err != nil {
+			logger.Error("Failed to stop chain indexer", "error", err)
+		}
 	}
+	logger.Info("Server stopped")
 }
@@ -2,58 +2,188 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nshmdayo/nft-platform-sample/internal/apikey"
 	"github.com/nshmdayo/nft-platform-sample/internal/config"
 	"github.com/nshmdayo/nft-platform-sample/internal/handlers"
+	"github.com/nshmdayo/nft-platform-sample/internal/keyset"
+	"github.com/nshmdayo/nft-platform-sample/internal/mail"
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
 	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/revocation"
 	"github.com/nshmdayo/nft-platform-sample/internal/router"
 	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"github.com/nshmdayo/nft-platform-sample/internal/throttle"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/internal/worker"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs/ipfstest"
 	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"github.com/nshmdayo/nft-platform-sample/pkg/siwe"
+	"github.com/nshmdayo/nft-platform-sample/pkg/totp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// testIPFS is a fake Kubo node shared by all tests
+var testIPFS *ipfstest.Server
+
+// testTokens signs and verifies access tokens with a keyset shared by all tests
+var testTokens utils.JWTOptions
+
+// testMailDir holds the mail outbox of every test app
+var testMailDir string
+
+// testOutbox receives the mail sent by the most recently set up test app
+var testOutbox *mail.Outbox
+
+// testMaxFileSize is the upload limit used in tests
+const testMaxFileSize = 4096
+
+func TestMain(m *testing.M) {
+	testIPFS = ipfstest.NewServer()
+
+	keysDir, err := os.MkdirTemp("", "jwt-keys")
+	if err != nil {
+		panic(err)
+	}
+	keys, err := keyset.New(keysDir, keyset.AlgEdDSA)
+	if err != nil {
+		panic(err)
+	}
+	testTokens = utils.JWTOptions{Keys: keys, Issuer: "test-issuer", Audience: "test-api"}
+
+	testMailDir, err = os.MkdirTemp("", "mail")
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	testIPFS.Close()
+	os.RemoveAll(keysDir)
+	os.RemoveAll(testMailDir)
+	os.Exit(code)
+}
+
 func setupTestRouter() http.Handler {
+	handler, _, _ := setupTestApp()
+	return handler
+}
+
+// setupTestApp builds the API handler, a mint worker that tests drive with
+// ProcessNext, and the database behind them
+func setupTestApp() (http.Handler, *worker.MintWorker, *gorm.DB) {
 	// Initialize logger for tests
 	logger.Init()
 	// Use in-memory SQLite for testing
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 
 	// Run migrations
-	db.AutoMigrate(&models.User{}, &models.Paper{}, &models.Review{}, &models.NFTMetadata{})
+	db.AutoMigrate(&models.User{}, &models.Paper{}, &models.Review{}, &models.ReviewAssignment{}, &models.EditorialDecision{}, &models.NFTMetadata{}, &models.NFTTransfer{}, &models.MintJob{}, &models.RefreshToken{}, &models.Session{}, &models.RevokedToken{}, &models.WalletNonce{}, &models.EmailToken{}, &models.ThrottleState{}, &models.LoginFailure{}, &models.RecoveryCode{}, &models.MFAChallenge{}, &models.Setting{}, &models.APIKey{})
 
 	// Initialize test configuration
 	cfg := &config.Config{
 		App: config.AppConfig{
 			Environment: "test",
+			PublicURL:   "https://papers.example.com",
+			AppURL:      "https://app.papers.example.com",
 		},
 		JWT: config.JWTConfig{
-			Secret:    "test-secret",
-			ExpiresIn: "24h",
+			ExpiresIn:        "15m",
+			RefreshExpiresIn: "720h",
+		},
+		MintQueue: config.MintQueueConfig{
+			MaxAttempts: 3,
+		},
+		SIWE: config.SIWEConfig{
+			Domain:   "papers.example.com",
+			Origin:   "https://papers.example.com",
+			NonceTTL: "10m",
+		},
+		Account: config.AccountConfig{
+			VerificationTTL:  "48h",
+			PasswordResetTTL: "1h",
+		},
+		Server: config.ServerConfig{
+			TrustedProxies: "10.0.0.0/8",
+		},
+		Login: config.LoginProtectionConfig{
+			MaxAccountFailures: 3,
+			FailureWindow:      "15m",
+			Lockout:            "15m",
+		},
+		MFA: config.MFAConfig{
+			Issuer:        "NFT Platform",
+			ChallengeTTL:  "5m",
+			MaxAttempts:   3,
+			RecoveryCodes: 4,
 		},
 	}
 
 	// Initialize repositories, services, and handlers
 	userRepo := repository.NewUserRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	nonceRepo := repository.NewWalletNonceRepository(db)
+	emailTokenRepo := repository.NewEmailTokenRepository(db)
+	loginFailureRepo := repository.NewLoginFailureRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	mfaChallengeRepo := repository.NewMFAChallengeRepository(db)
+	settingRepo := repository.NewSettingRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 	paperRepo := repository.NewPaperRepository(db)
 	reviewRepo := repository.NewReviewRepository(db)
+	decisionRepo := repository.NewEditorialDecisionRepository(db)
+	assignmentRepo := repository.NewReviewAssignmentRepository(db)
+	nftRepo := repository.NewNFTRepository(db)
+	mintJobRepo := repository.NewMintJobRepository(db)
+	revocations := revocation.NewStore(repository.NewRevokedTokenRepository(db), 0)
+	apiKeys := apikey.NewVerifier(apiKeyRepo, userRepo)
+	fileStorage := storage.NewIPFSStorage(ipfs.NewClient(testIPFS.URL))
+
+	mailDir, _ := os.MkdirTemp(testMailDir, "outbox")
+	testOutbox, _ = mail.NewOutbox(mailDir, "no-reply@papers.example.com")
 
-	authService := service.NewAuthService(userRepo, cfg)
-	paperService := service.NewPaperService(paperRepo)
-	reviewService := service.NewReviewService(reviewRepo, paperRepo)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, sessionRepo, nonceRepo, mintJobRepo, emailTokenRepo, loginFailureRepo, recoveryCodeRepo, mfaChallengeRepo, settingRepo, apiKeyRepo, revocations, throttle.New(repository.NewThrottleRepository(db)), testTokens, testOutbox, cfg)
+	paperService := service.NewPaperService(paperRepo, userRepo, assignmentRepo, fileStorage, testMaxFileSize)
+	reviewRubrics := service.NewReviewRubrics(settingRepo)
+	reviewService := service.NewReviewService(reviewRepo, assignmentRepo, paperRepo, userRepo, reviewRubrics)
+	decisionService := service.NewDecisionService(decisionRepo, reviewRepo, paperRepo, settingRepo)
+	reviewPolicies := service.NewReviewPolicies(settingRepo, paperRepo, assignmentRepo)
+	nftService := service.NewNFTService(nftRepo, userRepo, paperRepo, reviewRepo, mintJobRepo, nft.NewSimulatedChain("", ""), fileStorage, cfg)
+	mintWorker := worker.NewMintWorker(worker.MintWorkerConfig{}, mintJobRepo, nftService)
 
 	authHandler := handlers.NewAuthHandler(authService)
-	paperHandler := handlers.NewPaperHandler(paperService)
-	reviewHandler := handlers.NewReviewHandler(reviewService)
+	paperHandler := handlers.NewPaperHandler(paperService, reviewPolicies)
+	reviewHandler := handlers.NewReviewHandler(reviewService, decisionService, reviewPolicies)
+	nftHandler := handlers.NewNFTHandler(nftService, reviewPolicies)
+	adminHandler := handlers.NewAdminHandler(authService, reviewPolicies, reviewRubrics, decisionService)
 
-	r := router.NewRouter(cfg, authHandler, paperHandler, reviewHandler)
-	return r.SetupRoutes()
+	r := router.NewRouter(cfg, []netip.Prefix{netip.MustParsePrefix(cfg.Server.TrustedProxies)}, testTokens, revocations, apiKeys, authHandler, paperHandler, reviewHandler, nftHandler, adminHandler)
+	return r.SetupRoutes(), mintWorker, db
 }
 
 func TestHealthEndpoint(t *testing.T) {
@@ -100,3 +230,1931 @@ func TestRegisterEndpoint(t *testing.T) {
 	assert.Contains(t, data, "token")
 	assert.Contains(t, data, "user")
 }
+
+// lastMail returns the latest message sent to address by the current test app
+func lastMail(t *testing.T, address string) *mail.Message {
+	messages, err := testOutbox.Messages()
+	require.NoError(t, err)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To == address {
+			return messages[i]
+		}
+	}
+	t.Fatalf("no mail sent to %s", address)
+	return nil
+}
+
+// mailToken extracts the token of the link in the latest message sent to address
+func mailToken(t *testing.T, address string) string {
+	match := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(lastMail(t, address).Body)
+	require.NotNil(t, match)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+// registerAndGetToken registers a user, verifies its email address and returns the issued JWT
+func registerAndGetToken(t *testing.T, handler http.Handler, email string) string {
+	user := map[string]interface{}{
+		"email":    email,
+		"password": "password123",
+		"name":     "Test User",
+	}
+
+	jsonData, _ := json.Marshal(user)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, 201, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+
+	code, _ := doJSON(t, handler, "POST", "/api/v1/auth/verify-email", "", map[string]interface{}{"token": mailToken(t, email)})
+	require.Equal(t, 200, code)
+	return data["token"].(string)
+}
+
+// registerAs registers a user with role and returns a JWT carrying the role
+func registerAs(t *testing.T, handler http.Handler, db *gorm.DB, email, role string) string {
+	registerAndGetToken(t, handler, email)
+	require.NoError(t, db.Model(&models.User{}).Where("email = ?", email).Update("role", role).Error)
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": email, "password": "password123"})
+	require.Equal(t, 200, code)
+	return response["data"].(map[string]interface{})["token"].(string)
+}
+
+// registerAndGetTokens registers a user and returns the issued access and refresh tokens
+func registerAndGetTokens(t *testing.T, handler http.Handler, email string) (string, string) {
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/register", "", map[string]interface{}{
+		"email":    email,
+		"password": "password123",
+		"name":     "Test User",
+	})
+	assert.Equal(t, 201, code)
+
+	data := response["data"].(map[string]interface{})
+	return data["token"].(string), data["refresh_token"].(string)
+}
+
+// doJSON performs an authenticated JSON request and decodes the response envelope
+func doJSON(t *testing.T, handler http.Handler, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	var buf bytes.Buffer
+	if body != nil {
+		jsonData, _ := json.Marshal(body)
+		buf.Write(jsonData)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	handler.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if w.Body.Len() > 0 {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	handler := setupTestRouter()
+	_, refreshToken := registerAndGetTokens(t, handler, "refresh@example.com")
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 200, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(900), data["expires_in"])
+	rotated := data["refresh_token"].(string)
+	assert.NotEqual(t, refreshToken, rotated)
+
+	// The new access token works
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", data["token"].(string), nil)
+	assert.Equal(t, 200, code)
+
+	// Replaying the rotated-out token revokes the whole session, including its successor
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "INVALID_TOKEN", response["error"].(map[string]interface{})["code"])
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": rotated})
+	assert.Equal(t, 401, code)
+
+	// Other logins are unaffected
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": "refresh@example.com", "password": "password123"})
+	assert.Equal(t, 200, code)
+	loginRefresh := response["data"].(map[string]interface{})["refresh_token"].(string)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": loginRefresh})
+	assert.Equal(t, 200, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": "bogus"})
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{})
+	assert.Equal(t, 400, code)
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	handler := setupTestRouter()
+	_, refreshToken := registerAndGetTokens(t, handler, "logout@example.com")
+
+	code, _ := doJSON(t, handler, "POST", "/api/v1/auth/logout", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 204, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 401, code)
+
+	// Logging out again, or with an unknown token, is a no-op
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/logout", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 204, code)
+}
+
+func TestSessionManagement(t *testing.T) {
+	handler, _, db := setupTestApp()
+	firstToken, _ := registerAndGetTokens(t, handler, "sessions@example.com")
+
+	login := func() (string, string) {
+		code, response := doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": "sessions@example.com", "password": "password123"})
+		require.Equal(t, 200, code)
+		data := response["data"].(map[string]interface{})
+		return data["token"].(string), data["refresh_token"].(string)
+	}
+	secondToken, secondRefresh := login()
+
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/sessions", secondToken, nil)
+	require.Equal(t, 200, code)
+	sessions := response["data"].([]interface{})
+	require.Len(t, sessions, 2)
+
+	var firstSession float64
+	for _, s := range sessions {
+		session := s.(map[string]interface{})
+		if session["current"] == true {
+			continue
+		}
+		firstSession = session["id"].(float64)
+	}
+	require.NotZero(t, firstSession)
+
+	// Revoking the first session invalidates its access token immediately
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/auth/sessions/"+strconv.Itoa(int(firstSession)), secondToken, nil)
+	assert.Equal(t, 204, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/profile", firstToken, nil)
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "Token has been revoked", response["error"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/sessions", secondToken, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"].([]interface{}), 1)
+
+	// Refreshing replaces the session's access token
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": secondRefresh})
+	require.Equal(t, 200, code)
+	refreshedToken := response["data"].(map[string]interface{})["token"].(string)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", secondToken, nil)
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", refreshedToken, nil)
+	assert.Equal(t, 200, code)
+
+	// Sessions of other users cannot be revoked
+	otherToken := registerAndGetToken(t, handler, "other-sessions@example.com")
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/auth/sessions/"+strconv.Itoa(int(firstSession)+1), otherToken, nil)
+	assert.Equal(t, 404, code)
+
+	// Only admins can sign a user out everywhere
+	var user models.User
+	require.NoError(t, db.Where("email = ?", "sessions@example.com").First(&user).Error)
+	path := "/api/v1/admin/users/" + strconv.Itoa(int(user.ID)) + "/sessions"
+	code, _ = doJSON(t, handler, "DELETE", path, otherToken, nil)
+	assert.Equal(t, 403, code)
+
+	adminToken := registerAs(t, handler, db, "admin-sessions@example.com", models.RoleAdmin)
+
+	thirdToken, thirdRefresh := login()
+	code, response = doJSON(t, handler, "DELETE", path, adminToken, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, float64(2), response["data"].(map[string]interface{})["revoked"])
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", refreshedToken, nil)
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", thirdToken, nil)
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": thirdRefresh})
+	assert.Equal(t, 401, code)
+}
+
+func TestJWKSAndTokenValidation(t *testing.T) {
+	handler := setupTestRouter()
+	token := registerAndGetToken(t, handler, "jwks@example.com")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	handler.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var jwks keyset.JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.NotEmpty(t, jwks.Keys)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &utils.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+	assert.Equal(t, jwks.Keys[0].KeyID, parsed.Header["kid"])
+
+	code, _ := doJSON(t, handler, "GET", "/api/v1/auth/profile", token, nil)
+	assert.Equal(t, 200, code)
+
+	claims := parsed.Claims.(*utils.Claims)
+	signing := testTokens.Keys.Signing()
+
+	// alg "none"
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	unsigned.Header["kid"] = signing.ID
+	forged, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", forged, nil)
+	assert.Equal(t, 401, code)
+
+	// HS256 keyed with the published public key
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = signing.ID
+	forged, err = hmac.SignedString([]byte(signing.PublicKey().(ed25519.PublicKey)))
+	require.NoError(t, err)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", forged, nil)
+	assert.Equal(t, 401, code)
+
+	// Validly signed, but for another audience or issuer, or expired
+	for _, opts := range []utils.JWTOptions{
+		{Keys: testTokens.Keys, Issuer: testTokens.Issuer, Audience: "other-api"},
+		{Keys: testTokens.Keys, Issuer: "other-issuer", Audience: testTokens.Audience},
+	} {
+		other, _, err := utils.GenerateJWT(opts, claims.UserID, claims.Email, claims.Role, claims.SessionID, false, time.Minute)
+		require.NoError(t, err)
+		code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", other, nil)
+		assert.Equal(t, 401, code)
+	}
+	expired, _, err := utils.GenerateJWT(testTokens, claims.UserID, claims.Email, claims.Role, claims.SessionID, false, -time.Minute)
+	require.NoError(t, err)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", expired, nil)
+	assert.Equal(t, 401, code)
+}
+
+// signSIWE fetches a nonce and returns a SIWE message for domain and uri signed by key
+func signSIWE(t *testing.T, handler http.Handler, key *secp256k1.PrivateKey, domain, uri string, expiresAt time.Time) map[string]interface{} {
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/siwe/nonce", "", nil)
+	require.Equal(t, 200, code)
+	challenge := response["data"].(map[string]interface{})
+
+	msg := &siwe.Message{
+		Domain:         domain,
+		Address:        ethereum.PubkeyToAddress(key.PubKey()).Hex(),
+		Statement:      "Sign in to the paper platform",
+		URI:            uri,
+		Version:        "1",
+		ChainID:        1,
+		Nonce:          challenge["nonce"].(string),
+		IssuedAt:       time.Now(),
+		ExpirationTime: &expiresAt,
+	}
+	text := msg.String()
+	sig, err := ethereum.Sign(ethereum.HashPersonalMessage([]byte(text)), key)
+	require.NoError(t, err)
+	sig[64] += 27 // As returned by wallets
+
+	return map[string]interface{}{"message": text, "signature": ethereum.EncodeHex(sig)}
+}
+
+func TestSignInWithEthereum(t *testing.T) {
+	handler, _, db := setupTestApp()
+	token := registerAndGetToken(t, handler, "siwe@example.com")
+	key, err := ethereum.PrivateKeyFromHex("0x4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	wallet := ethereum.PubkeyToAddress(key.PubKey()).Hex()
+	domain := "papers.example.com"
+	uri := "https://papers.example.com/login"
+	expires := time.Now().Add(time.Hour)
+
+	// Unknown wallets cannot sign in
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signSIWE(t, handler, key, domain, uri, expires))
+	assert.Equal(t, 404, code)
+	assert.Equal(t, "USER_NOT_FOUND", response["error"].(map[string]interface{})["code"])
+
+	// A signed-in user links the wallet on first use
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", token, signSIWE(t, handler, key, domain, uri, expires))
+	assert.Equal(t, 200, code)
+	var user models.User
+	require.NoError(t, db.Where("email = ?", "siwe@example.com").First(&user).Error)
+	require.NotNil(t, user.WalletAddr)
+	assert.Equal(t, wallet, *user.WalletAddr)
+
+	// Afterwards the wallet alone logs in
+	signed := signSIWE(t, handler, key, domain, uri, expires)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signed)
+	require.Equal(t, 200, code)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "siwe@example.com", data["user"].(map[string]interface{})["email"])
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", data["token"].(string), nil)
+	assert.Equal(t, 200, code)
+
+	// Nonces are single-use
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signed)
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "INVALID_TOKEN", response["error"].(map[string]interface{})["code"])
+
+	// Messages for other domains or origins, expired messages and foreign signatures are rejected
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signSIWE(t, handler, key, "evil.example.com", uri, expires))
+	assert.Equal(t, 401, code)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signSIWE(t, handler, key, domain, "https://evil.example.com/login", expires))
+	assert.Equal(t, 401, code)
+	assert.Contains(t, response["error"].(map[string]interface{})["message"], "another origin")
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signSIWE(t, handler, key, domain, "http://papers.example.com/login", expires))
+	assert.Equal(t, 401, code)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", signSIWE(t, handler, key, domain, uri, time.Now().Add(-time.Minute)))
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "TOKEN_EXPIRED", response["error"].(map[string]interface{})["code"])
+
+	other, err := ethereum.PrivateKeyFromHex("0x0101010101010101010101010101010101010101010101010101010101010101")
+	require.NoError(t, err)
+	forged := signSIWE(t, handler, key, domain, uri, expires)
+	sig, err := ethereum.Sign(ethereum.HashPersonalMessage([]byte(forged["message"].(string))), other)
+	require.NoError(t, err)
+	forged["signature"] = ethereum.EncodeHex(sig)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", forged)
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "INVALID_SIGNATURE", response["error"].(map[string]interface{})["code"])
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", "", map[string]interface{}{"message": "hello", "signature": "0x00"})
+	assert.Equal(t, 400, code)
+
+	// The wallet cannot be linked to a second account
+	otherToken := registerAndGetToken(t, handler, "siwe-other@example.com")
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/siwe/verify", otherToken, signSIWE(t, handler, key, domain, uri, expires))
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "CONFLICT", response["error"].(map[string]interface{})["code"])
+}
+
+// linkWallet signs a fresh wallet challenge of the user with key and submits it
+func linkWallet(t *testing.T, handler http.Handler, token string, key *secp256k1.PrivateKey) (int, map[string]interface{}) {
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/wallet/challenge", token, nil)
+	require.Equal(t, 200, code)
+	challenge := response["data"].(map[string]interface{})
+
+	sig, err := ethereum.Sign(ethereum.HashPersonalMessage([]byte(challenge["message"].(string))), key)
+	require.NoError(t, err)
+
+	return doJSON(t, handler, "POST", "/api/v1/auth/wallet", token, map[string]interface{}{
+		"address":   ethereum.PubkeyToAddress(key.PubKey()).Hex(),
+		"signature": ethereum.EncodeHex(sig),
+		"nonce":     challenge["nonce"],
+	})
+}
+
+func TestWalletLinking(t *testing.T) {
+	handler, mintWorker, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "wallet@example.com")
+	reviewer := registerAs(t, handler, db, "wallet-reviewer@example.com", models.RoleReviewer)
+	admin := registerAs(t, handler, db, "wallet-admin@example.com", models.RoleAdmin)
+	key, err := ethereum.PrivateKeyFromHex("0x4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+	wallet := ethereum.PubkeyToAddress(key.PubKey()).Hex()
+
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/profile", author, nil)
+	require.Equal(t, 200, code)
+	assert.Nil(t, response["data"].(map[string]interface{})["wallet_address"])
+
+	code, response = linkWallet(t, handler, author, key)
+	require.Equal(t, 200, code)
+	assert.Equal(t, wallet, response["data"].(map[string]interface{})["wallet_address"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/profile", author, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, wallet, response["data"].(map[string]interface{})["wallet_address"])
+
+	// The same wallet cannot be linked to another account
+	code, response = linkWallet(t, handler, reviewer, key)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "CONFLICT", response["error"].(map[string]interface{})["code"])
+
+	// Challenges are bound to the user they were issued to and must be signed by the wallet
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/wallet/challenge", reviewer, nil)
+	require.Equal(t, 200, code)
+	challenge := response["data"].(map[string]interface{})
+	other, err := ethereum.PrivateKeyFromHex("0x0101010101010101010101010101010101010101010101010101010101010101")
+	require.NoError(t, err)
+	sig, err := ethereum.Sign(ethereum.HashPersonalMessage([]byte(challenge["message"].(string))), other)
+	require.NoError(t, err)
+	link := map[string]interface{}{
+		"address":   ethereum.PubkeyToAddress(other.PubKey()).Hex(),
+		"signature": ethereum.EncodeHex(sig),
+		"nonce":     challenge["nonce"],
+	}
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/wallet", author, link)
+	assert.Equal(t, 401, code)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/wallet", reviewer, map[string]interface{}{
+		"address": wallet, "signature": link["signature"], "nonce": link["nonce"],
+	})
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "INVALID_SIGNATURE", response["error"].(map[string]interface{})["code"])
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/wallet", reviewer, link)
+	assert.Equal(t, 200, code)
+
+	// Challenges are single-use
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/wallet", reviewer, link)
+	assert.Equal(t, 401, code)
+
+	// Unlinking waits for pending mints to the wallet
+	paperID := createPaper(t, handler, author)
+	reviewAndPublish(t, handler, paperID, author, reviewer, admin)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/mint", admin, nil)
+	require.Equal(t, 202, code)
+
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/auth/wallet", author, nil)
+	assert.Equal(t, 409, code)
+
+	drainMintJobs(t, mintWorker)
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/auth/wallet", author, nil)
+	assert.Equal(t, 204, code)
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/auth/wallet", author, nil)
+	assert.Equal(t, 404, code)
+
+	// Once unlinked, the wallet can be linked again
+	code, _ = linkWallet(t, handler, author, key)
+	assert.Equal(t, 200, code)
+}
+
+func TestEmailVerificationAndPasswordReset(t *testing.T) {
+	handler := setupTestRouter()
+	const email = "verify@example.com"
+	token, refreshToken := registerAndGetTokens(t, handler, email)
+
+	verification := lastMail(t, email)
+	assert.Equal(t, "Verify your email address", verification.Subject)
+	assert.Contains(t, verification.Body, "https://app.papers.example.com/verify-email?token=")
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/profile", token, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, false, response["data"].(map[string]interface{})["email_verified"])
+
+	// Unverified users can write papers but not submit them
+	paperID := createPaper(t, handler, token)
+	submitPath := "/api/v1/papers/" + strconv.Itoa(int(paperID)) + "/submit"
+	code, response = doJSON(t, handler, "POST", submitPath, token, nil)
+	assert.Equal(t, 403, code)
+	assert.Equal(t, "EMAIL_NOT_VERIFIED", response["error"].(map[string]interface{})["code"])
+
+	// Resending supersedes the first link
+	firstLink := mailToken(t, email)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/verify-email/resend", token, nil)
+	assert.Equal(t, 202, code)
+	secondLink := mailToken(t, email)
+	assert.NotEqual(t, firstLink, secondLink)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/verify-email", "", map[string]interface{}{"token": firstLink})
+	assert.Equal(t, 401, code)
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/verify-email", "", map[string]interface{}{"token": secondLink})
+	require.Equal(t, 200, code)
+	assert.Equal(t, true, response["data"].(map[string]interface{})["email_verified"])
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/verify-email", "", map[string]interface{}{"token": secondLink})
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/verify-email/resend", token, nil)
+	assert.Equal(t, 409, code)
+
+	code, _ = doJSON(t, handler, "POST", submitPath, token, nil)
+	assert.Equal(t, 200, code)
+
+	// Unknown addresses get the same answer and no mail
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/forgot-password", "", map[string]interface{}{"email": "nobody@example.com"})
+	assert.Equal(t, 202, code)
+	messages, err := testOutbox.Messages()
+	require.NoError(t, err)
+	for _, msg := range messages {
+		assert.NotEqual(t, "nobody@example.com", msg.To)
+	}
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/forgot-password", "", map[string]interface{}{"email": email})
+	assert.Equal(t, 202, code)
+	assert.Equal(t, "Reset your password", lastMail(t, email).Subject)
+	resetLink := mailToken(t, email)
+
+	// Verification links cannot reset passwords, and passwords must be long enough
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/reset-password", "", map[string]interface{}{"token": secondLink, "password": "new-password"})
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/reset-password", "", map[string]interface{}{"token": resetLink, "password": "short"})
+	assert.Equal(t, 400, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/reset-password", "", map[string]interface{}{"token": resetLink, "password": "new-password"})
+	assert.Equal(t, 204, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/reset-password", "", map[string]interface{}{"token": resetLink, "password": "other-password"})
+	assert.Equal(t, 401, code)
+
+	// Existing sessions are signed out and only the new password works
+	code, _ = doJSON(t, handler, "GET", "/api/v1/auth/profile", token, nil)
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": refreshToken})
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": email, "password": "password123"})
+	assert.Equal(t, 401, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": email, "password": "new-password"})
+	assert.Equal(t, 200, code)
+}
+
+func TestRoleManagement(t *testing.T) {
+	handler, _, db := setupTestApp()
+	userToken, userRefresh := registerAndGetTokens(t, handler, "promoted@example.com")
+	admin := registerAs(t, handler, db, "admin@example.com", models.RoleAdmin)
+
+	// Researchers can neither review nor administer
+	code, response := doJSON(t, handler, "GET", "/api/v1/reviews/pending", userToken, nil)
+	assert.Equal(t, 403, code)
+	assert.Equal(t, "FORBIDDEN", response["error"].(map[string]interface{})["code"])
+	assert.Equal(t, "Insufficient permissions", response["error"].(map[string]interface{})["message"])
+	code, _ = doJSON(t, handler, "GET", "/api/v1/admin/users", userToken, nil)
+	assert.Equal(t, 403, code)
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/admin/users", admin, nil)
+	require.Equal(t, 200, code)
+	users := response["data"].([]interface{})
+	require.Len(t, users, 2)
+	user := users[0].(map[string]interface{})
+	assert.Equal(t, "promoted@example.com", user["email"])
+	assert.Equal(t, models.RoleResearcher, user["role"])
+	path := "/api/v1/admin/users/" + strconv.Itoa(int(user["id"].(float64))) + "/role"
+
+	code, _ = doJSON(t, handler, "PUT", path, admin, map[string]interface{}{"role": "editor"})
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/users/999/role", admin, map[string]interface{}{"role": models.RoleReviewer})
+	assert.Equal(t, 404, code)
+	code, _ = doJSON(t, handler, "PUT", path, userToken, map[string]interface{}{"role": models.RoleAdmin})
+	assert.Equal(t, 403, code)
+
+	code, response = doJSON(t, handler, "PUT", path, admin, map[string]interface{}{"role": models.RoleReviewer})
+	require.Equal(t, 200, code)
+	assert.Equal(t, models.RoleReviewer, response["data"].(map[string]interface{})["role"])
+
+	// The token carrying the old role stops working; refreshing picks up the new one
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/profile", userToken, nil)
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "Token has been revoked", response["error"])
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/refresh", "", map[string]interface{}{"refresh_token": userRefresh})
+	require.Equal(t, 200, code)
+	reviewerToken := response["data"].(map[string]interface{})["token"].(string)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/reviews/pending", reviewerToken, nil)
+	assert.Equal(t, 200, code)
+
+	// The platform always keeps an admin
+	var adminUser models.User
+	require.NoError(t, db.Where("email = ?", "admin@example.com").First(&adminUser).Error)
+	adminPath := "/api/v1/admin/users/" + strconv.Itoa(int(adminUser.ID)) + "/role"
+	code, _ = doJSON(t, handler, "PUT", adminPath, admin, map[string]interface{}{"role": models.RoleResearcher})
+	assert.Equal(t, 409, code)
+
+	code, _ = doJSON(t, handler, "PUT", path, admin, map[string]interface{}{"role": models.RoleAdmin})
+	require.Equal(t, 200, code)
+	code, _ = doJSON(t, handler, "PUT", adminPath, admin, map[string]interface{}{"role": models.RoleResearcher})
+	assert.Equal(t, 200, code)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/admin/users", admin, nil)
+	assert.Equal(t, 401, code)
+}
+
+func TestLoginLockout(t *testing.T) {
+	handler, _, db := setupTestApp()
+	registerAndGetToken(t, handler, "locked@example.com")
+	admin := registerAs(t, handler, db, "lockout-admin@example.com", models.RoleAdmin)
+
+	// Logins reaching the API through a trusted proxy are attributed to the forwarded address
+	login := func(password, forwardedFor string) (int, *httptest.ResponseRecorder) {
+		jsonData, _ := json.Marshal(map[string]interface{}{"email": "locked@example.com", "password": password})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = "10.0.0.2:4321"
+		handler.ServeHTTP(w, req)
+		return w.Code, w
+	}
+
+	for i := 0; i < 3; i++ {
+		code, _ := login("wrong-password", "203.0.113.9, 10.0.0.1")
+		assert.Equal(t, 401, code)
+	}
+
+	// Locked out, even with the right password
+	code, w := login("password123", "203.0.113.9")
+	assert.Equal(t, 429, code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	code, response := doJSON(t, handler, "GET", "/api/v1/admin/login-failures?email=locked@example.com", admin, nil)
+	require.Equal(t, 200, code)
+	failures := response["data"].([]interface{})
+	require.Len(t, failures, 4)
+	assert.Equal(t, models.LoginFailureThrottled, failures[0].(map[string]interface{})["reason"])
+	assert.Equal(t, models.LoginFailureInvalidPassword, failures[3].(map[string]interface{})["reason"])
+	assert.Equal(t, "203.0.113.9", failures[3].(map[string]interface{})["ip_address"])
+
+	// Only admins can unlock
+	var user models.User
+	require.NoError(t, db.Where("email = ?", "locked@example.com").First(&user).Error)
+	path := "/api/v1/admin/users/" + strconv.Itoa(int(user.ID)) + "/unlock"
+	code, _ = doJSON(t, handler, "POST", path, registerAndGetToken(t, handler, "not-admin@example.com"), nil)
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "POST", path, admin, nil)
+	assert.Equal(t, 200, code)
+
+	code, _ = login("password123", "203.0.113.9")
+	assert.Equal(t, 200, code)
+
+	// Unknown accounts are throttled alike
+	for i := 0; i < 3; i++ {
+		code, _ = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": "nobody@example.com", "password": "password123"})
+		assert.Equal(t, 401, code)
+	}
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": "nobody@example.com", "password": "password123"})
+	assert.Equal(t, 429, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/admin/ips/not-an-ip/unlock", admin, nil)
+	assert.Equal(t, 400, code)
+}
+
+func TestTwoFactorAuthentication(t *testing.T) {
+	handler, _, db := setupTestApp()
+	admin := registerAs(t, handler, db, "mfa-admin@example.com", models.RoleAdmin)
+	credentials := map[string]interface{}{"email": "mfa-admin@example.com", "password": "password123"}
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/2fa/setup", admin, nil)
+	require.Equal(t, 200, code)
+	setup := response["data"].(map[string]interface{})
+	secret := setup["secret"].(string)
+	assert.Contains(t, setup["otpauth_uri"], "otpauth://totp/NFT%20Platform:mfa-admin@example.com?")
+
+	totpCode := func(offset int64) string {
+		c, err := totp.Code(secret, totp.Step(time.Now())+offset)
+		require.NoError(t, err)
+		return c
+	}
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/enable", admin, map[string]interface{}{"code": totpCode(10)})
+	assert.Equal(t, 401, code)
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/2fa/enable", admin, map[string]interface{}{"code": totpCode(0)})
+	require.Equal(t, 200, code)
+	recoveryCodes := response["data"].(map[string]interface{})["recovery_codes"].([]interface{})
+	require.Len(t, recoveryCodes, 4)
+
+	// A password alone only yields a token for the second step
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/login", "", credentials)
+	require.Equal(t, 200, code)
+	challenge := response["data"].(map[string]interface{})
+	assert.Equal(t, true, challenge["mfa_required"])
+	assert.NotContains(t, challenge, "token")
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": challenge["mfa_token"], "code": totpCode(10)})
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "INVALID_MFA_CODE", response["error"].(map[string]interface{})["code"])
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": challenge["mfa_token"], "code": totpCode(1)})
+	require.Equal(t, 200, code)
+	admin = response["data"].(map[string]interface{})["token"].(string)
+
+	// Tokens and codes are accepted once
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": challenge["mfa_token"], "code": totpCode(1)})
+	assert.Equal(t, 401, code)
+	_, response = doJSON(t, handler, "POST", "/api/v1/auth/login", "", credentials)
+	mfaToken := response["data"].(map[string]interface{})["mfa_token"]
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": mfaToken, "code": totpCode(1)})
+	assert.Equal(t, 401, code)
+
+	// Recovery codes work once each, in any case and without the dash
+	recovery := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0].(string), "-", ""))
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": mfaToken, "code": recovery})
+	assert.Equal(t, 200, code)
+	_, response = doJSON(t, handler, "POST", "/api/v1/auth/login", "", credentials)
+	mfaToken = response["data"].(map[string]interface{})["mfa_token"]
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/verify", "", map[string]interface{}{"mfa_token": mfaToken, "code": recovery})
+	assert.Equal(t, 401, code)
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/2fa", admin, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, true, response["data"].(map[string]interface{})["enabled"])
+	assert.Equal(t, float64(3), response["data"].(map[string]interface{})["recovery_codes_remaining"])
+
+	// Requiring 2FA limits users without it to setting it up
+	researcher := registerAndGetToken(t, handler, "mfa-researcher@example.com")
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/2fa", researcher, map[string]interface{}{"required": true})
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/2fa", admin, map[string]interface{}{})
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/2fa", admin, map[string]interface{}{"required": true})
+	assert.Equal(t, 200, code)
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/auth/login", "", map[string]interface{}{"email": "mfa-researcher@example.com", "password": "password123"})
+	require.Equal(t, 200, code)
+	researcher = response["data"].(map[string]interface{})["token"].(string)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/papers", researcher, nil)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/2fa", researcher, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, true, response["data"].(map[string]interface{})["required"])
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/setup", researcher, nil)
+	assert.Equal(t, 200, code)
+
+	// While required, 2FA cannot be turned off by the user, only reset by an admin
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/2fa/disable", admin, map[string]interface{}{"code": recoveryCodes[1]})
+	assert.Equal(t, 409, code)
+
+	var user models.User
+	require.NoError(t, db.Where("email = ?", "mfa-admin@example.com").First(&user).Error)
+	code, response = doJSON(t, handler, "DELETE", "/api/v1/admin/users/"+strconv.Itoa(int(user.ID))+"/2fa", admin, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, false, response["data"].(map[string]interface{})["two_factor_enabled"])
+}
+
+// doAPIKey sends a request authenticated with an API key instead of a JWT
+func doAPIKey(t *testing.T, handler http.Handler, method, path, key string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "ApiKey "+key)
+	handler.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if w.Body.Len() > 0 {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestAPIKeys(t *testing.T) {
+	handler, _, _ := setupTestApp()
+	token := registerAndGetToken(t, handler, "apikey@example.com")
+	createPaper(t, handler, token)
+
+	code, _ := doJSON(t, handler, "POST", "/api/v1/auth/api-keys", token, map[string]interface{}{"name": "ci", "scopes": []string{"papers:delete"}})
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/auth/api-keys", token, map[string]interface{}{"name": "ci", "scopes": []string{models.ScopePapersRead}, "expires_at": time.Now().Add(-time.Hour)})
+	assert.Equal(t, 400, code)
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/auth/api-keys", token, map[string]interface{}{"name": "ci", "scopes": []string{models.ScopePapersRead}})
+	require.Equal(t, 201, code)
+	created := response["data"].(map[string]interface{})
+	key := created["key"].(string)
+	assert.True(t, strings.HasPrefix(key, models.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, created["prefix"].(string)))
+
+	// Keys act as their user within their scopes only
+	code, response = doAPIKey(t, handler, "GET", "/api/v1/papers/my", key)
+	assert.Equal(t, 200, code)
+	assert.Len(t, response["data"], 1)
+	code, _ = doAPIKey(t, handler, "GET", "/api/v1/reviews/my", key)
+	assert.Equal(t, 403, code)
+	code, _ = doAPIKey(t, handler, "POST", "/api/v1/auth/api-keys", key)
+	assert.Equal(t, 403, code)
+	code, _ = doAPIKey(t, handler, "GET", "/api/v1/auth/profile", key)
+	assert.Equal(t, 403, code)
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/auth/api-keys", token, nil)
+	require.Equal(t, 200, code)
+	keys := response["data"].([]interface{})
+	require.Len(t, keys, 1)
+	listed := keys[0].(map[string]interface{})
+	assert.NotContains(t, listed, "key")
+	assert.NotNil(t, listed["last_used_at"])
+
+	// Revoked and unknown keys are rejected
+	path := "/api/v1/auth/api-keys/" + strconv.Itoa(int(created["id"].(float64)))
+	code, _ = doJSON(t, handler, "DELETE", path, token, nil)
+	assert.Equal(t, 204, code)
+	code, _ = doJSON(t, handler, "DELETE", path, token, nil)
+	assert.Equal(t, 404, code)
+	code, response = doAPIKey(t, handler, "GET", "/api/v1/papers/my", key)
+	assert.Equal(t, 401, code)
+	assert.Equal(t, "API key has been revoked", response["error"])
+	code, _ = doAPIKey(t, handler, "GET", "/api/v1/papers/my", models.APIKeyPrefix+"unknown")
+	assert.Equal(t, 401, code)
+}
+
+func TestPaperRoutes(t *testing.T) {
+	handler := setupTestRouter()
+	token := registerAndGetToken(t, handler, "author@example.com")
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/papers", token, map[string]interface{}{
+		"title":    "A Study of Things",
+		"abstract": "An abstract",
+		"authors":  []string{"Test User"},
+		"category": "cs",
+	})
+	assert.Equal(t, 201, code)
+	paperID := response["data"].(map[string]interface{})["id"].(float64)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	code, response = doJSON(t, handler, "GET", path, token, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "A Study of Things", response["data"].(map[string]interface{})["title"])
+
+	code, response = doJSON(t, handler, "PUT", path, token, map[string]interface{}{"title": "Renamed"})
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "Renamed", response["data"].(map[string]interface{})["title"])
+
+	code, _ = doJSON(t, handler, "GET", path+"/reviews", token, nil)
+	assert.Equal(t, 200, code)
+
+	code, _ = doJSON(t, handler, "DELETE", path, token, nil)
+	assert.Equal(t, 204, code)
+
+	code, response = doJSON(t, handler, "GET", path, token, nil)
+	assert.Equal(t, 404, code)
+	assert.Equal(t, "PAPER_NOT_FOUND", response["error"].(map[string]interface{})["code"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/papers/abc", token, nil)
+	assert.Equal(t, 400, code)
+	assert.Equal(t, "INVALID_FORMAT", response["error"].(map[string]interface{})["code"])
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	handler := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/v1/papers/1", nil)
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, 405, w.Code)
+	assert.Contains(t, w.Header().Get("Allow"), "GET")
+	assert.Contains(t, w.Header().Get("Allow"), "DELETE")
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "METHOD_NOT_ALLOWED", response["error"].(map[string]interface{})["code"])
+}
+
+func TestPaperLifecycle(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "author@example.com")
+	editor := registerAs(t, handler, db, "editor@example.com", models.RoleAdmin)
+
+	_, response := doJSON(t, handler, "POST", "/api/v1/papers", author, map[string]interface{}{
+		"title":    "A Study of Things",
+		"abstract": "An abstract",
+		"authors":  []string{"Test User"},
+		"category": "cs",
+	})
+	paperID := response["data"].(map[string]interface{})["id"].(float64)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	status := func(response map[string]interface{}) interface{} {
+		return response["data"].(map[string]interface{})["status"]
+	}
+
+	code, response := doJSON(t, handler, "POST", path+"/submit", author, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "submitted", status(response))
+
+	code, response = doJSON(t, handler, "POST", path+"/publish", editor, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "INVALID_STATUS_TRANSITION", response["error"].(map[string]interface{})["code"])
+
+	code, response = doJSON(t, handler, "POST", path+"/withdraw", author, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "draft", status(response))
+
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	assert.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, editor, editor)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews", editor, reviewRequest(paperID, "revision"))
+	assert.Equal(t, 201, code)
+
+	// Only accepted papers are published
+	code, response = doJSON(t, handler, "POST", path+"/publish", editor, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "INVALID_STATUS_TRANSITION", response["error"].(map[string]interface{})["code"])
+
+	// Authors cannot decide on papers, their own or otherwise
+	code, response = doJSON(t, handler, "POST", path+"/decision", author, nil)
+	assert.Equal(t, 403, code)
+	assert.Equal(t, "FORBIDDEN", response["error"].(map[string]interface{})["code"])
+	assert.Equal(t, "Insufficient permissions", response["error"].(map[string]interface{})["message"])
+
+	decidePaper(t, handler, paperID, editor, "minor_revision")
+	code, response = doJSON(t, handler, "GET", path, author, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "revision_requested", status(response))
+
+	code, response = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "submitted", status(response))
+}
+
+// uploadFile uploads content as the manuscript of a paper
+func uploadFile(t *testing.T, handler http.Handler, paperID float64, token, fileName string, content []byte) (int, map[string]interface{}) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("description", "camera ready")
+	part, _ := mw.CreateFormFile("file", fileName)
+	part.Write(content)
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/file", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestUploadPaperFile(t *testing.T) {
+	handler := setupTestRouter()
+	author := registerAndGetToken(t, handler, "author@example.com")
+	other := registerAndGetToken(t, handler, "other@example.com")
+	paperID := createPaper(t, handler, author)
+
+	manuscript := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+
+	code, _ := uploadFile(t, handler, paperID, other, "paper.pdf", manuscript)
+	assert.Equal(t, 403, code)
+
+	code, response := uploadFile(t, handler, paperID, author, "notes.txt", []byte("just some text"))
+	assert.Equal(t, 415, code)
+	assert.Equal(t, "UNSUPPORTED_MEDIA_TYPE", response["error"].(map[string]interface{})["code"])
+
+	code, response = uploadFile(t, handler, paperID, author, "huge.pdf", append([]byte("%PDF-1.4\n"), make([]byte, testMaxFileSize)...))
+	assert.Equal(t, 413, code)
+	assert.Equal(t, "FILE_TOO_LARGE", response["error"].(map[string]interface{})["code"])
+
+	code, response = uploadFile(t, handler, paperID, author, "../paper.pdf", manuscript)
+	assert.Equal(t, 200, code)
+	paper := response["data"].(map[string]interface{})
+	cid := ipfstest.CID(manuscript)
+	sum := sha256.Sum256(manuscript)
+	assert.Equal(t, cid, paper["ipfs_hash"])
+	assert.Equal(t, "paper.pdf", paper["file_name"])
+	assert.Equal(t, float64(len(manuscript)), paper["file_size"])
+	assert.Equal(t, hex.EncodeToString(sum[:]), paper["file_sha256"])
+	assert.Equal(t, "application/pdf", paper["file_content_type"])
+	assert.True(t, testIPFS.Pinned(cid))
+
+	// Replacing the manuscript unpins the previous version
+	revised := []byte(strings.Replace(string(manuscript), "Catalog", "Catalog /Version 2", 1))
+	code, response = uploadFile(t, handler, paperID, author, "paper-v2.pdf", revised)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, ipfstest.CID(revised), response["data"].(map[string]interface{})["ipfs_hash"])
+	assert.False(t, testIPFS.Pinned(cid))
+
+	// The manuscript is frozen once submitted
+	code, _ = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/submit", author, nil)
+	assert.Equal(t, 200, code)
+	code, _ = uploadFile(t, handler, paperID, author, "paper-v3.pdf", manuscript)
+	assert.Equal(t, 409, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/file", author, nil)
+	assert.Equal(t, 400, code)
+}
+
+func TestDownloadPaperFile(t *testing.T) {
+	handler := setupTestRouter()
+	author := registerAndGetToken(t, handler, "author@example.com")
+	reader := registerAndGetToken(t, handler, "reader@example.com")
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID)) + "/file"
+
+	code, response := doJSON(t, handler, "GET", path, reader, nil)
+	assert.Equal(t, 404, code)
+	assert.Equal(t, "NOT_FOUND", response["error"].(map[string]interface{})["code"])
+
+	manuscript := []byte("%PDF-1.4\n0123456789abcdef\n%%EOF\n")
+	code, _ = uploadFile(t, handler, paperID, author, "paper.pdf", manuscript)
+	assert.Equal(t, 200, code)
+
+	download := func(header map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+reader)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := download(nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, manuscript, w.Body.Bytes())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `filename=paper.pdf`)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"`+ipfstest.CID(manuscript)+`"`, etag)
+
+	w = download(map[string]string{"Range": "bytes=9-18"})
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "0123456789", w.Body.String())
+	assert.Equal(t, "bytes 9-18/"+strconv.Itoa(len(manuscript)), w.Header().Get("Content-Range"))
+
+	w = download(map[string]string{"Range": "bytes=-6"})
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "%%EOF\n", w.Body.String())
+
+	w = download(map[string]string{"Range": "bytes=1000-"})
+	assert.Equal(t, 416, w.Code)
+
+	w = download(map[string]string{"If-None-Match": etag})
+	assert.Equal(t, 304, w.Code)
+}
+
+// reviewRequest is a review of paperID on the default rubric scoring 8 on every criterion
+func reviewRequest(paperID float64, recommendation string) map[string]interface{} {
+	return map[string]interface{}{
+		"paper_id":       paperID,
+		"comment":        "Solid work",
+		"criteria":       map[string]int{"originality": 8, "methodology": 8, "clarity": 8, "significance": 8},
+		"sections":       map[string]string{"summary": "The paper studies things."},
+		"recommendation": recommendation,
+	}
+}
+
+// createPaper creates a draft paper and returns its ID
+func createPaper(t *testing.T, handler http.Handler, token string) float64 {
+	code, response := doJSON(t, handler, "POST", "/api/v1/papers", token, map[string]interface{}{
+		"title":    "A Study of Things",
+		"abstract": "An abstract",
+		"authors":  []string{"Test User"},
+		"category": "cs",
+		"keywords": []string{"things"},
+	})
+	assert.Equal(t, 201, code)
+	return response["data"].(map[string]interface{})["id"].(float64)
+}
+
+func TestReviewAssignments(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "assign-author@example.com")
+	reviewer := registerAs(t, handler, db, "assign-reviewer@example.com", models.RoleReviewer)
+	other := registerAs(t, handler, db, "assign-other@example.com", models.RoleReviewer)
+	editor := registerAs(t, handler, db, "assign-editor@example.com", models.RoleAdmin)
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	userID := func(email string) uint {
+		var user models.User
+		require.NoError(t, db.Where("email = ?", email).First(&user).Error)
+		return user.ID
+	}
+	review := reviewRequest(paperID, "accept")
+	due := time.Now().Add(7 * 24 * time.Hour)
+
+	// Drafts cannot be assigned
+	code, _ := doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-reviewer@example.com"), "due_at": due})
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+
+	// Only editors invite, only reviewers other than the author, and only once
+	code, _ = doJSON(t, handler, "POST", path+"/assignments", reviewer, map[string]interface{}{"reviewer_id": userID("assign-reviewer@example.com"), "due_at": due})
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-author@example.com"), "due_at": due})
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-reviewer@example.com"), "due_at": time.Now().Add(-time.Hour)})
+	assert.Equal(t, 400, code)
+	code, response := doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-reviewer@example.com"), "due_at": due})
+	require.Equal(t, 201, code)
+	assignment := response["data"].(map[string]interface{})
+	assert.Equal(t, models.AssignmentStatusInvited, assignment["status"])
+	assignmentPath := "/api/v1/assignments/" + strconv.Itoa(int(assignment["id"].(float64)))
+	code, response = doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-reviewer@example.com"), "due_at": due})
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "ALREADY_ASSIGNED", response["error"].(map[string]interface{})["code"])
+
+	// Unsolicited and unaccepted reviews are refused
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", other, review)
+	assert.Equal(t, 403, code)
+	assert.Equal(t, "NOT_ASSIGNED", response["error"].(map[string]interface{})["code"])
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, review)
+	assert.Equal(t, 403, code)
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/assignments/my?status=invited", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 1)
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 0)
+
+	// Only the invited reviewer responds
+	code, _ = doJSON(t, handler, "POST", assignmentPath+"/accept", other, nil)
+	assert.Equal(t, 404, code)
+	code, response = doJSON(t, handler, "POST", assignmentPath+"/accept", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, models.AssignmentStatusAccepted, response["data"].(map[string]interface{})["status"])
+	code, _ = doJSON(t, handler, "POST", assignmentPath+"/accept", reviewer, nil)
+	assert.Equal(t, 409, code)
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", reviewer, nil)
+	require.Equal(t, 200, code)
+	require.Len(t, response["data"], 1)
+	pending := response["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, paperID, pending["paper"].(map[string]interface{})["id"])
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, review)
+	require.Equal(t, 201, code)
+	reviewID := response["data"].(map[string]interface{})["id"].(float64)
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 0)
+	code, _ = doJSON(t, handler, "POST", assignmentPath+"/decline", reviewer, nil)
+	assert.Equal(t, 409, code)
+
+	// Deleting the review reopens the assignment
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/reviews/"+strconv.Itoa(int(reviewID)), reviewer, nil)
+	require.Equal(t, 204, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 1)
+
+	// A second reviewer declines with a reason; a third invitation is cancelled
+	code, response = doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-other@example.com"), "due_at": due})
+	require.Equal(t, 201, code)
+	otherPath := "/api/v1/assignments/" + strconv.Itoa(int(response["data"].(map[string]interface{})["id"].(float64)))
+	code, response = doJSON(t, handler, "POST", otherPath+"/decline", other, map[string]interface{}{"reason": "Conflict of interest"})
+	require.Equal(t, 200, code)
+	assert.Equal(t, "Conflict of interest", response["data"].(map[string]interface{})["decline_reason"])
+
+	code, response = doJSON(t, handler, "POST", path+"/assignments", editor, map[string]interface{}{"reviewer_id": userID("assign-other@example.com"), "due_at": due})
+	require.Equal(t, 201, code)
+	cancelPath := "/api/v1/assignments/" + strconv.Itoa(int(response["data"].(map[string]interface{})["id"].(float64)))
+	code, _ = doJSON(t, handler, "DELETE", cancelPath, other, nil)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "DELETE", cancelPath, editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, models.AssignmentStatusCancelled, response["data"].(map[string]interface{})["status"])
+	code, _ = doJSON(t, handler, "POST", cancelPath+"/accept", other, nil)
+	assert.Equal(t, 409, code)
+
+	code, response = doJSON(t, handler, "GET", path+"/assignments", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 3)
+}
+
+func TestReviewerSuggestions(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "match-author@example.com")
+	editor := registerAs(t, handler, db, "match-editor@example.com", models.RoleAdmin)
+	interested := registerAs(t, handler, db, "match-interested@example.com", models.RoleReviewer)
+	colleague := registerAs(t, handler, db, "match-colleague@example.com", models.RoleReviewer)
+	coauthor := registerAs(t, handler, db, "match-coauthor@example.com", models.RoleReviewer)
+	published := registerAs(t, handler, db, "match-published@example.com", models.RoleReviewer)
+	researcher := registerAndGetToken(t, handler, "match-researcher@example.com")
+	require.NoError(t, db.Model(&models.User{}).Where("email IN ?", []string{"match-author@example.com", "match-colleague@example.com"}).
+		Update("institution", "Example University").Error)
+
+	userID := func(email string) float64 {
+		var user models.User
+		require.NoError(t, db.Where("email = ?", email).First(&user).Error)
+		return float64(user.ID)
+	}
+	newPaper := func(token string, authors, keywords []string) float64 {
+		code, response := doJSON(t, handler, "POST", "/api/v1/papers", token, map[string]interface{}{
+			"title":    "A Study of Rollups",
+			"abstract": "An abstract",
+			"authors":  authors,
+			"category": "cs",
+			"keywords": keywords,
+		})
+		require.Equal(t, 201, code)
+		return response["data"].(map[string]interface{})["id"].(float64)
+	}
+
+	code, response := doJSON(t, handler, "PUT", "/api/v1/auth/profile/interests", interested, map[string]interface{}{"interests": []string{" Rollups ", "rollups", "Zero Knowledge"}})
+	require.Equal(t, 200, code)
+	assert.Equal(t, []interface{}{"Rollups", "Zero Knowledge"}, response["data"].(map[string]interface{})["interests"])
+	for _, token := range []string{colleague, researcher} {
+		code, _ = doJSON(t, handler, "PUT", "/api/v1/auth/profile/interests", token, map[string]interface{}{"interests": []string{"rollups", "zero knowledge"}})
+		require.Equal(t, 200, code)
+	}
+	newPaper(coauthor, []string{"Carol", "Alice Author"}, []string{"rollups"})
+	newPaper(published, []string{"Dave"}, []string{"consensus"})
+
+	paperID := newPaper(author, []string{"Alice Author"}, []string{"rollups", "zero knowledge", "data availability"})
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+	code, _ = doJSON(t, handler, "GET", path+"/reviewer-suggestions", editor, nil)
+	assert.Equal(t, 400, code)
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+
+	code, _ = doJSON(t, handler, "GET", path+"/reviewer-suggestions", researcher, nil)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "GET", path+"/reviewer-suggestions", editor, nil)
+	require.Equal(t, 200, code)
+	data := response["data"].(map[string]interface{})
+
+	// Interests weigh more than the category of one's papers; researchers are not candidates
+	suggestions := data["suggestions"].([]interface{})
+	require.Len(t, suggestions, 2)
+	first := suggestions[0].(map[string]interface{})
+	assert.Equal(t, userID("match-interested@example.com"), first["reviewer_id"])
+	assert.Equal(t, 0.5, first["score"])
+	assert.Equal(t, []interface{}{"interested in rollups, zero knowledge", "0 open assignments"}, first["reasons"])
+	second := suggestions[1].(map[string]interface{})
+	assert.Equal(t, userID("match-published@example.com"), second["reviewer_id"])
+	assert.Equal(t, []interface{}{"published on cs", "0 open assignments"}, second["reasons"])
+
+	conflicts := map[float64]string{}
+	for _, c := range data["conflicts"].([]interface{}) {
+		conflict := c.(map[string]interface{})
+		conflicts[conflict["reviewer_id"].(float64)] = conflict["reason"].(string)
+	}
+	assert.Equal(t, "same institution as the author: Example University", conflicts[userID("match-colleague@example.com")])
+	assert.Equal(t, "co-authored a paper with Alice Author", conflicts[userID("match-coauthor@example.com")])
+
+	// Invited reviewers drop out, and open assignments lower a reviewer's score elsewhere
+	assignReviewer(t, handler, paperID, editor, interested)
+	code, response = doJSON(t, handler, "GET", path+"/reviewer-suggestions?limit=1", editor, nil)
+	require.Equal(t, 200, code)
+	data = response["data"].(map[string]interface{})
+	require.Len(t, data["suggestions"], 1)
+	assert.Equal(t, userID("match-published@example.com"), data["suggestions"].([]interface{})[0].(map[string]interface{})["reviewer_id"])
+
+	otherID := newPaper(author, []string{"Alice Author"}, []string{"rollups"})
+	code, _ = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(otherID))+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/papers/"+strconv.Itoa(int(otherID))+"/reviewer-suggestions", editor, nil)
+	require.Equal(t, 200, code)
+	first = response["data"].(map[string]interface{})["suggestions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, userID("match-interested@example.com"), first["reviewer_id"])
+	assert.Equal(t, 0.4, first["score"])
+	assert.Equal(t, float64(1), first["open_assignments"])
+}
+
+func TestBlindReview(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "blind-author@example.com")
+	first := registerAs(t, handler, db, "blind-first@example.com", models.RoleReviewer)
+	second := registerAs(t, handler, db, "blind-second@example.com", models.RoleReviewer)
+	editor := registerAs(t, handler, db, "blind-editor@example.com", models.RoleAdmin)
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	// Papers are reviewed single-blind unless configured otherwise
+	code, response := doJSON(t, handler, "GET", "/api/v1/admin/settings/review-policy", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, models.ReviewPolicySingleBlind, response["data"].(map[string]interface{})["default"])
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-policy", author, map[string]interface{}{"default": models.ReviewPolicyOpen})
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-policy/categories/CS", editor, map[string]interface{}{"policy": "triple_blind"})
+	assert.Equal(t, 400, code)
+	code, response = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-policy/categories/CS", editor, map[string]interface{}{"policy": models.ReviewPolicyDoubleBlind})
+	require.Equal(t, 200, code)
+	assert.Equal(t, map[string]interface{}{"cs": models.ReviewPolicyDoubleBlind}, response["data"].(map[string]interface{})["categories"])
+
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, editor, first)
+	assignReviewer(t, handler, paperID, editor, second)
+
+	// Reviewers do not learn who wrote a double-blind paper; its author does
+	code, response = doJSON(t, handler, "GET", path, first, nil)
+	require.Equal(t, 200, code)
+	paper := response["data"].(map[string]interface{})
+	assert.Equal(t, models.ReviewPolicyDoubleBlind, paper["review_policy"])
+	assert.Equal(t, true, paper["authors_hidden"])
+	assert.NotContains(t, paper, "authors")
+	assert.NotContains(t, paper, "owner_id")
+	code, response = doJSON(t, handler, "GET", "/api/v1/assignments/my", first, nil)
+	require.Equal(t, 200, code)
+	assert.NotContains(t, response["data"].([]interface{})[0].(map[string]interface{})["paper"], "authors")
+	code, response = doJSON(t, handler, "GET", path, author, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, []interface{}{"Test User"}, response["data"].(map[string]interface{})["authors"])
+
+	for _, reviewer := range []string{first, second} {
+		code, _ = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, reviewRequest(paperID, "accept"))
+		require.Equal(t, 201, code)
+	}
+
+	// Reviewers are known by stable aliases to everyone but themselves and editors
+	reviews := func(token string) []interface{} {
+		code, response := doJSON(t, handler, "GET", path+"/reviews", token, nil)
+		require.Equal(t, 200, code)
+		return response["data"].(map[string]interface{})["reviews"].([]interface{})
+	}
+	identified := func(token string) map[string]bool {
+		seen := make(map[string]bool)
+		for _, review := range reviews(token) {
+			review := review.(map[string]interface{})
+			_, ok := review["reviewer_id"]
+			seen[review["reviewer_alias"].(string)] = ok
+		}
+		return seen
+	}
+	assert.Equal(t, map[string]bool{"Reviewer 1": false, "Reviewer 2": false}, identified(author))
+	assert.Equal(t, map[string]bool{"Reviewer 1": true, "Reviewer 2": false}, identified(first))
+	assert.Equal(t, map[string]bool{"Reviewer 1": true, "Reviewer 2": true}, identified(editor))
+
+	// Editors may open the review of a single paper, but authors may not
+	code, _ = doJSON(t, handler, "PUT", path+"/review-policy", author, map[string]interface{}{"policy": models.ReviewPolicyOpen})
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "PUT", path+"/review-policy", editor, map[string]interface{}{"policy": models.ReviewPolicyOpen})
+	require.Equal(t, 200, code)
+	assert.Equal(t, models.ReviewPolicyOpen, response["data"].(map[string]interface{})["review_policy"])
+	assert.Equal(t, map[string]bool{"Reviewer 1": true, "Reviewer 2": true}, identified(author))
+
+	// Clearing the override and the category policy falls back to the default
+	code, _ = doJSON(t, handler, "PUT", path+"/review-policy", editor, map[string]interface{}{"policy": ""})
+	require.Equal(t, 200, code)
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/admin/settings/review-policy/categories/cs", editor, nil)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", path, second, nil)
+	require.Equal(t, 200, code)
+	paper = response["data"].(map[string]interface{})
+	assert.Equal(t, models.ReviewPolicySingleBlind, paper["review_policy"])
+	assert.Equal(t, []interface{}{"Test User"}, paper["authors"])
+}
+
+func TestReviewRubrics(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "rubric-author@example.com")
+	reviewer := registerAs(t, handler, db, "rubric-reviewer@example.com", models.RoleReviewer)
+	editor := registerAs(t, handler, db, "rubric-editor@example.com", models.RoleAdmin)
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	// Papers are reviewed on the built-in rubric until one is configured
+	code, response := doJSON(t, handler, "GET", path+"/rubric", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["version"])
+	assert.Len(t, response["data"].(map[string]interface{})["criteria"], 4)
+
+	rubric := map[string]interface{}{
+		"scale": map[string]int{"min": 1, "max": 5},
+		"criteria": []map[string]interface{}{
+			{"key": "soundness", "label": "Soundness", "weight": 3},
+			{"key": "novelty", "label": "Novelty", "weight": 1},
+		},
+		"sections": []map[string]interface{}{
+			{"key": "summary", "label": "Summary", "required": true, "min_length": 10},
+		},
+	}
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-rubric/categories/cs", reviewer, rubric)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-rubric/categories/cs", editor, map[string]interface{}{
+		"scale":    map[string]int{"min": 5, "max": 1},
+		"criteria": []map[string]interface{}{{"key": "Soundness", "label": "Soundness", "weight": 0}},
+	})
+	assert.Equal(t, 400, code)
+	assert.Len(t, response["error"].(map[string]interface{})["details"], 3)
+	code, response = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-rubric/categories/cs", editor, rubric)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(2), response["data"].(map[string]interface{})["version"])
+
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, editor, reviewer)
+
+	// Reviews must follow the rubric of the paper's category
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, reviewRequest(paperID, "accept"))
+	require.Equal(t, 400, code)
+	fields := make([]string, 0)
+	for _, detail := range response["error"].(map[string]interface{})["details"].([]interface{}) {
+		fields = append(fields, detail.(map[string]interface{})["field"].(string))
+	}
+	assert.ElementsMatch(t, []string{"criteria.soundness", "criteria.novelty", "criteria.originality", "criteria.methodology", "criteria.clarity", "criteria.significance"}, fields)
+
+	submission := map[string]interface{}{
+		"paper_id":              paperID,
+		"comment":               "Sound but incremental",
+		"criteria":              map[string]int{"soundness": 5, "novelty": 2},
+		"sections":              map[string]string{"summary": "Proves a tighter bound."},
+		"confidential_comments": "Overlaps with prior work",
+		"recommendation":        "revision",
+	}
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, submission)
+	require.Equal(t, 400, code)
+	assert.Equal(t, "confidential_comments", response["error"].(map[string]interface{})["details"].([]interface{})[0].(map[string]interface{})["field"])
+
+	// Rubrics offering confidential comments keep them from authors
+	rubric["confidential_comments"] = true
+	code, response = doJSON(t, handler, "PUT", "/api/v1/admin/settings/review-rubric/categories/CS", editor, rubric)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(3), response["data"].(map[string]interface{})["version"])
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, submission)
+	require.Equal(t, 201, code)
+	review := response["data"].(map[string]interface{})
+	reviewPath := "/api/v1/reviews/" + strconv.Itoa(int(review["id"].(float64)))
+
+	// (3*5 + 1*2) / 4 = 4.25 on 1-5 is 8.31 on 1-10
+	metadata := review["metadata"].(map[string]interface{})
+	assert.Equal(t, 4.25, metadata["overall_score"])
+	assert.Equal(t, float64(3), metadata["rubric_version"])
+	assert.Equal(t, "cs", metadata["rubric_category"])
+	assert.Equal(t, float64(8), review["score"])
+	assert.Equal(t, "Overlaps with prior work", review["confidential_comments"])
+
+	code, response = doJSON(t, handler, "GET", reviewPath, editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, "Overlaps with prior work", response["data"].(map[string]interface{})["confidential_comments"])
+	code, response = doJSON(t, handler, "GET", reviewPath, author, nil)
+	require.Equal(t, 200, code)
+	assert.NotContains(t, response["data"], "confidential_comments")
+	assert.NotContains(t, response["data"].(map[string]interface{})["metadata"], "confidential_comments")
+
+	// Updates are scored again; removing the category rubric restores the default
+	submission["criteria"] = map[string]int{"soundness": 1, "novelty": 1}
+	delete(submission, "confidential_comments")
+	code, response = doJSON(t, handler, "PUT", reviewPath, reviewer, submission)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["score"])
+	assert.NotContains(t, response["data"], "confidential_comments")
+
+	code, _ = doJSON(t, handler, "DELETE", "/api/v1/admin/settings/review-rubric/categories/cs", editor, nil)
+	require.Equal(t, 204, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/admin/settings/review-rubric", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Empty(t, response["data"].(map[string]interface{})["categories"])
+	code, response = doJSON(t, handler, "GET", path+"/rubric", reviewer, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["version"])
+}
+
+func TestEditorialDecisions(t *testing.T) {
+	handler, _, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "decision-author@example.com")
+	first := registerAs(t, handler, db, "decision-first@example.com", models.RoleReviewer)
+	second := registerAs(t, handler, db, "decision-second@example.com", models.RoleReviewer)
+	editor := registerAs(t, handler, db, "decision-editor@example.com", models.RoleAdmin)
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	code, _ := doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, editor, first)
+	assignReviewer(t, handler, paperID, editor, second)
+
+	reviewID := func(response map[string]interface{}) string {
+		return strconv.Itoa(int(response["data"].(map[string]interface{})["id"].(float64)))
+	}
+	confident := reviewRequest(paperID, "accept")
+	confident["confidence"] = 5
+	code, response := doJSON(t, handler, "POST", "/api/v1/reviews", first, confident)
+	require.Equal(t, 201, code)
+	firstReview := "/api/v1/reviews/" + reviewID(response)
+
+	// Decisions wait for the required number of reviews
+	code, response = doJSON(t, handler, "GET", path+"/decision", editor, nil)
+	require.Equal(t, 200, code)
+	draft := response["data"].(map[string]interface{})
+	assert.Equal(t, false, draft["ready"])
+	assert.Equal(t, float64(1), draft["reviews"])
+	assert.NotContains(t, draft, "decision")
+	code, response = doJSON(t, handler, "POST", path+"/decision", editor, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "REVIEWS_INCOMPLETE", response["error"].(map[string]interface{})["code"])
+
+	unsure := reviewRequest(paperID, "revision")
+	unsure["criteria"] = map[string]int{"originality": 5, "methodology": 5, "clarity": 5, "significance": 5}
+	unsure["confidence"] = 1
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews", second, unsure)
+	require.Equal(t, 201, code)
+	secondReview := "/api/v1/reviews/" + reviewID(response)
+
+	// (8*5 + 5*1) / 6 = 7.5 weights the confident review over the mean of 6.5
+	code, _ = doJSON(t, handler, "GET", path+"/decision", author, nil)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "GET", path+"/decision", editor, nil)
+	require.Equal(t, 200, code)
+	draft = response["data"].(map[string]interface{})
+	assert.Equal(t, true, draft["ready"])
+	assert.Equal(t, 7.5, draft["score"])
+	assert.Equal(t, 6.5, draft["scores"].(map[string]interface{})["mean"])
+	assert.Equal(t, "accept", draft["decision"])
+	assert.Len(t, draft["rationale"], 2)
+
+	// Overriding the draft needs a reason
+	code, _ = doJSON(t, handler, "POST", path+"/decision", editor, map[string]interface{}{"decision": "maybe"})
+	assert.Equal(t, 400, code)
+	code, response = doJSON(t, handler, "POST", path+"/decision", editor, map[string]interface{}{"decision": "reject"})
+	assert.Equal(t, 400, code)
+	assert.Equal(t, "comment", response["error"].(map[string]interface{})["details"].([]interface{})[0].(map[string]interface{})["field"])
+
+	code, response = doJSON(t, handler, "POST", path+"/decision", editor, map[string]interface{}{
+		"decision": "minor_revision",
+		"comment":  "The second reviewer's concerns deserve an answer",
+	})
+	require.Equal(t, 201, code)
+	record := response["data"].(map[string]interface{})
+	assert.Equal(t, "minor_revision", record["decision"])
+	assert.Equal(t, "accept", record["proposed"])
+	assert.Equal(t, "revision_requested", record["paper_status"])
+	assert.Equal(t, float64(0), record["round"])
+	code, response = doJSON(t, handler, "POST", path+"/decision", editor, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "INVALID_STATUS_TRANSITION", response["error"].(map[string]interface{})["code"])
+
+	// The next round starts from fresh reviews
+	code, response = doJSON(t, handler, "GET", path+"/decision", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(0), response["data"].(map[string]interface{})["reviews"])
+
+	// Reviewers of the resubmitted paper owe it another look, given by updating
+	// their reviews, even if it was withdrawn for rework in between
+	code, response = doJSON(t, handler, "POST", path+"/withdraw", author, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, "draft", response["data"].(map[string]interface{})["status"])
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", second, nil)
+	require.Equal(t, 200, code)
+	assert.Empty(t, response["data"])
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", second, nil)
+	require.Equal(t, 200, code)
+	assert.Len(t, response["data"], 1)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews", second, unsure)
+	assert.Equal(t, 409, code)
+
+	code, _ = doJSON(t, handler, "PUT", firstReview, first, confident)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", path, author, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, "under_review", response["data"].(map[string]interface{})["status"])
+	code, response = doJSON(t, handler, "GET", path+"/decision", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["reviews"])
+
+	revised := reviewRequest(paperID, "accept")
+	revised["confidence"] = 3
+	code, _ = doJSON(t, handler, "PUT", secondReview, second, revised)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", "/api/v1/reviews/pending", second, nil)
+	require.Equal(t, 200, code)
+	assert.Empty(t, response["data"])
+
+	code, response = doJSON(t, handler, "POST", path+"/decision", editor, nil)
+	require.Equal(t, 201, code)
+	record = response["data"].(map[string]interface{})
+	assert.Equal(t, "accept", record["decision"])
+	assert.Equal(t, float64(8), record["score"])
+	assert.Equal(t, float64(1), record["round"])
+	assert.Equal(t, float64(2), record["reviews"])
+	assert.Equal(t, "accepted", record["paper_status"])
+	code, response = doJSON(t, handler, "GET", path, author, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, "accepted", response["data"].(map[string]interface{})["status"])
+
+	// Decided reviews are final
+	code, response = doJSON(t, handler, "PUT", firstReview, first, unsure)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "INVALID_STATUS_TRANSITION", response["error"].(map[string]interface{})["code"])
+	code, _ = doJSON(t, handler, "DELETE", secondReview, second, nil)
+	assert.Equal(t, 409, code)
+
+	// Authors see the decisions on their papers; reviewers do not
+	code, response = doJSON(t, handler, "GET", path+"/decisions", author, nil)
+	require.Equal(t, 200, code)
+	require.Len(t, response["data"], 2)
+	assert.Equal(t, "minor_revision", response["data"].([]interface{})[0].(map[string]interface{})["decision"])
+	code, _ = doJSON(t, handler, "GET", path+"/decisions", first, nil)
+	assert.Equal(t, 403, code)
+
+	// Policies
+	code, response = doJSON(t, handler, "GET", "/api/v1/admin/settings/decision-policy", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, "confidence_weighted", response["data"].(map[string]interface{})["score_method"])
+
+	policy := map[string]interface{}{
+		"required_reviews":  3,
+		"score_method":      "median",
+		"accept_at":         8,
+		"minor_revision_at": 6,
+		"major_revision_at": 4,
+		"unanimous_accept":  true,
+	}
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/decision-policy", first, policy)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "PUT", "/api/v1/admin/settings/decision-policy", editor, map[string]interface{}{
+		"required_reviews": 0,
+		"score_method":     "mode",
+		"accept_at":        3,
+	})
+	assert.Equal(t, 400, code)
+	assert.Len(t, response["error"].(map[string]interface{})["details"], 3)
+	code, _ = doJSON(t, handler, "PUT", "/api/v1/admin/settings/decision-policy", editor, policy)
+	require.Equal(t, 200, code)
+	code, response = doJSON(t, handler, "GET", path+"/decision", editor, nil)
+	require.Equal(t, 200, code)
+	assert.Equal(t, float64(3), response["data"].(map[string]interface{})["required_reviews"])
+	assert.Equal(t, "median", response["data"].(map[string]interface{})["score_method"])
+}
+
+// assignReviewer invites reviewer to a submitted paper as editor and accepts as
+// reviewer; returns the assignment ID
+func assignReviewer(t *testing.T, handler http.Handler, paperID float64, editor, reviewer string) float64 {
+	code, response := doJSON(t, handler, "GET", "/api/v1/auth/profile", reviewer, nil)
+	require.Equal(t, 200, code)
+	reviewerID := response["data"].(map[string]interface{})["id"]
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/assignments", editor, map[string]interface{}{
+		"reviewer_id": reviewerID,
+		"due_at":      time.Now().Add(14 * 24 * time.Hour),
+	})
+	require.Equal(t, 201, code)
+	assignmentID := response["data"].(map[string]interface{})["id"].(float64)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/assignments/"+strconv.Itoa(int(assignmentID))+"/accept", reviewer, nil)
+	require.Equal(t, 200, code)
+	return assignmentID
+}
+
+// decidePaper confirms decision on a paper under review as editor, deciding on a single review
+func decidePaper(t *testing.T, handler http.Handler, paperID float64, editor, decision string) {
+	code, _ := doJSON(t, handler, "PUT", "/api/v1/admin/settings/decision-policy", editor, map[string]interface{}{
+		"required_reviews":  1,
+		"score_method":      "confidence_weighted",
+		"accept_at":         7.5,
+		"minor_revision_at": 6,
+		"major_revision_at": 4,
+		"majority_reject":   true,
+	})
+	require.Equal(t, 200, code)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/decision", editor, map[string]interface{}{
+		"decision": decision,
+		"comment":  "Decided in test",
+	})
+	require.Equal(t, 201, code)
+}
+
+// reviewAndPublish submits a paper, reviews it as reviewer, accepts and publishes it as editor; returns the review ID
+func reviewAndPublish(t *testing.T, handler http.Handler, paperID float64, author, reviewer, editor string) float64 {
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	code, _ := doJSON(t, handler, "POST", path+"/submit", author, nil)
+	assert.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, editor, reviewer)
+
+	code, response := doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, reviewRequest(paperID, "accept"))
+	assert.Equal(t, 201, code)
+
+	decidePaper(t, handler, paperID, editor, "accept")
+	code, _ = doJSON(t, handler, "POST", path+"/publish", editor, nil)
+	assert.Equal(t, 200, code)
+
+	return response["data"].(map[string]interface{})["id"].(float64)
+}
+
+// drainMintJobs processes all due mint jobs
+func drainMintJobs(t *testing.T, mintWorker *worker.MintWorker) {
+	for {
+		processed, err := mintWorker.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		if !processed {
+			return
+		}
+	}
+}
+
+func TestMintPaperAndReview(t *testing.T) {
+	handler, mintWorker, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "author@example.com")
+	reviewer := registerAs(t, handler, db, "reviewer@example.com", models.RoleReviewer)
+	admin := registerAs(t, handler, db, "admin@example.com", models.RoleAdmin)
+
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	code, response := doJSON(t, handler, "POST", path+"/mint", admin, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "PAPER_NOT_PUBLISHED", response["error"].(map[string]interface{})["code"])
+
+	reviewID := reviewAndPublish(t, handler, paperID, author, reviewer, admin)
+
+	// Only admins mint, even the paper's own author
+	code, _ = doJSON(t, handler, "POST", path+"/mint", author, nil)
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "POST", path+"/mint", reviewer, nil)
+	assert.Equal(t, 403, code)
+
+	code, response = doJSON(t, handler, "POST", path+"/mint", admin, nil)
+	assert.Equal(t, 202, code)
+	job := response["data"].(map[string]interface{})
+	assert.Equal(t, "queued", job["status"])
+	assert.Equal(t, "paper", job["type"])
+	jobPath := "/api/v1/mint-jobs/" + strconv.Itoa(int(job["id"].(float64)))
+
+	// Requesting again before the job ran returns the same job
+	code, response = doJSON(t, handler, "POST", path+"/mint", admin, nil)
+	assert.Equal(t, 202, code)
+	assert.Equal(t, job["id"], response["data"].(map[string]interface{})["id"])
+
+	code, _ = doJSON(t, handler, "GET", jobPath, reviewer, nil)
+	assert.Equal(t, 404, code)
+
+	drainMintJobs(t, mintWorker)
+
+	code, response = doJSON(t, handler, "GET", jobPath, admin, nil)
+	assert.Equal(t, 200, code)
+	job = response["data"].(map[string]interface{})
+	assert.Equal(t, "confirmed", job["status"])
+	assert.Equal(t, float64(1), job["token_id"])
+	assert.Equal(t, float64(1), job["attempts"])
+	assert.NotEmpty(t, job["tx_hash"])
+
+	code, response = doJSON(t, handler, "POST", path+"/mint", admin, nil)
+	assert.Equal(t, 409, code)
+	assert.Equal(t, "ALREADY_MINTED", response["error"].(map[string]interface{})["code"])
+
+	_, response = doJSON(t, handler, "GET", path, author, nil)
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["nft_token_id"])
+	publishedAt, err := time.Parse(time.RFC3339, response["data"].(map[string]interface{})["published_at"].(string))
+	require.NoError(t, err)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews/"+strconv.Itoa(int(reviewID))+"/mint", reviewer, nil)
+	assert.Equal(t, 403, code)
+	code, response = doJSON(t, handler, "POST", "/api/v1/reviews/"+strconv.Itoa(int(reviewID))+"/mint", admin, nil)
+	assert.Equal(t, 202, code)
+	reviewJobPath := "/api/v1/mint-jobs/" + strconv.Itoa(int(response["data"].(map[string]interface{})["id"].(float64)))
+
+	drainMintJobs(t, mintWorker)
+
+	_, response = doJSON(t, handler, "GET", reviewJobPath, admin, nil)
+	assert.Equal(t, "confirmed", response["data"].(map[string]interface{})["status"])
+	assert.Equal(t, "review", response["data"].(map[string]interface{})["type"])
+	assert.Equal(t, float64(1), response["data"].(map[string]interface{})["token_id"])
+
+	// Token metadata is public so contracts can point tokenURI at it
+	code, metadata := doJSON(t, handler, "GET", "/api/v1/nfts/1/metadata", "", nil)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "A Study of Things", metadata["name"])
+	assert.Equal(t, "https://papers.example.com/api/v1/papers/"+strconv.Itoa(int(paperID)), metadata["external_url"])
+	assert.True(t, strings.HasPrefix(metadata["image"].(string), "ipfs://"))
+	traits := map[string]interface{}{}
+	for _, attribute := range metadata["attributes"].([]interface{}) {
+		attribute := attribute.(map[string]interface{})
+		traits[attribute["trait_type"].(string)] = attribute["value"]
+	}
+	assert.Equal(t, "cs", traits["Category"])
+	assert.Equal(t, "things", traits["Keyword"])
+	assert.Equal(t, float64(1), traits["Review Count"])
+	assert.Equal(t, float64(8), traits["Average Review Score"])
+	assert.Equal(t, "accept", traits["Recommendation"])
+	assert.Equal(t, float64(publishedAt.Unix()), traits["Published"])
+
+	code, metadata = doJSON(t, handler, "GET", "/api/v1/nfts/1/metadata?type=review", "", nil)
+	assert.Equal(t, 200, code)
+	assert.Contains(t, metadata["name"], "Review #")
+	assert.NotContains(t, metadata["description"], "Solid work")
+
+	// Review tokens reference the metadata pinned for them when minted
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=review", admin, nil)
+	require.Equal(t, 200, code)
+	detail := response["data"].(map[string]interface{})
+	assert.Equal(t, detail["metadata_uri"], "ipfs://"+detail["review"].(map[string]interface{})["ipfs_hash"].(string))
+
+	code, _ = doJSON(t, handler, "GET", "/api/v1/nfts/2/metadata", "", nil)
+	assert.Equal(t, 404, code)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/nfts/1/metadata?type=badge", "", nil)
+	assert.Equal(t, 400, code)
+}
+
+func TestNFTDetailReviewPolicy(t *testing.T) {
+	handler, mintWorker, db := setupTestApp()
+	author := registerAndGetToken(t, handler, "nft-blind-author@example.com")
+	reviewer := registerAs(t, handler, db, "nft-blind-reviewer@example.com", models.RoleReviewer)
+	admin := registerAs(t, handler, db, "nft-blind-admin@example.com", models.RoleAdmin)
+	stranger := registerAndGetToken(t, handler, "nft-blind-stranger@example.com")
+	paperID := createPaper(t, handler, author)
+	path := "/api/v1/papers/" + strconv.Itoa(int(paperID))
+
+	code, _ := doJSON(t, handler, "PUT", path+"/review-policy", admin, map[string]interface{}{"policy": models.ReviewPolicyDoubleBlind})
+	require.Equal(t, 200, code)
+	code, _ = doJSON(t, handler, "POST", path+"/submit", author, nil)
+	require.Equal(t, 200, code)
+	assignReviewer(t, handler, paperID, admin, reviewer)
+
+	review := reviewRequest(paperID, "accept")
+	review["confidential_comments"] = "I know the authors"
+	code, response := doJSON(t, handler, "POST", "/api/v1/reviews", reviewer, review)
+	require.Equal(t, 201, code)
+	reviewID := response["data"].(map[string]interface{})["id"].(float64)
+	decidePaper(t, handler, paperID, admin, "accept")
+	code, _ = doJSON(t, handler, "POST", path+"/publish", admin, nil)
+	require.Equal(t, 200, code)
+
+	code, _ = doJSON(t, handler, "POST", path+"/mint", admin, nil)
+	require.Equal(t, 202, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews/"+strconv.Itoa(int(reviewID))+"/mint", admin, nil)
+	require.Equal(t, 202, code)
+	drainMintJobs(t, mintWorker)
+
+	// Tokens show their review and paper under the paper's review policy
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=review", stranger, nil)
+	require.Equal(t, 200, code)
+	detail := response["data"].(map[string]interface{})["review"].(map[string]interface{})
+	assert.Equal(t, "Reviewer 1", detail["reviewer_alias"])
+	assert.NotContains(t, detail, "reviewer_id")
+	assert.NotContains(t, detail, "reviewer")
+	assert.NotContains(t, detail, "confidential_comments")
+	assert.Equal(t, models.ReviewPolicyDoubleBlind, detail["paper"].(map[string]interface{})["review_policy"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=review", admin, nil)
+	require.Equal(t, 200, code)
+	detail = response["data"].(map[string]interface{})["review"].(map[string]interface{})
+	assert.Contains(t, detail, "reviewer_id")
+	assert.Equal(t, "I know the authors", detail["confidential_comments"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=paper", stranger, nil)
+	require.Equal(t, 200, code)
+	paper := response["data"].(map[string]interface{})["paper"].(map[string]interface{})
+	require.Len(t, paper["reviews"], 1)
+	assert.NotContains(t, paper["reviews"].([]interface{})[0], "reviewer_id")
+	assert.NotContains(t, paper["reviews"].([]interface{})[0], "confidential_comments")
+
+	// Authors of a double-blind paper are hidden whenever it is not published
+	require.NoError(t, db.Model(&models.Paper{}).Where("id = ?", uint(paperID)).Update("status", models.PaperStatusAccepted).Error)
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=paper", stranger, nil)
+	require.Equal(t, 200, code)
+	paper = response["data"].(map[string]interface{})["paper"].(map[string]interface{})
+	assert.Equal(t, true, paper["authors_hidden"])
+	assert.NotContains(t, paper, "owner_id")
+	assert.NotContains(t, paper, "owner")
+	assert.NotContains(t, paper, "authors")
+}
+
+// registerWithWallet registers a user, links wallet to it directly in the
+// database and returns the issued JWT and user ID
+func registerWithWallet(t *testing.T, handler http.Handler, db *gorm.DB, email, wallet string) (string, float64) {
+	token := registerAndGetToken(t, handler, email)
+	_, response := doJSON(t, handler, "GET", "/api/v1/auth/profile", token, nil)
+	userID := response["data"].(map[string]interface{})["id"].(float64)
+
+	assert.NoError(t, db.Model(&models.User{}).Where("id = ?", uint(userID)).Update("wallet_addr", wallet).Error)
+	return token, userID
+}
+
+func TestNFTRegistry(t *testing.T) {
+	const (
+		authorWallet    = "0x1111111111111111111111111111111111111111"
+		collectorWallet = "0x2222222222222222222222222222222222222222"
+	)
+
+	handler, mintWorker, db := setupTestApp()
+	author, authorID := registerWithWallet(t, handler, db, "author@example.com", authorWallet)
+	collector, collectorID := registerWithWallet(t, handler, db, "collector@example.com", collectorWallet)
+	reviewer := registerAs(t, handler, db, "reviewer@example.com", models.RoleReviewer)
+	admin := registerAs(t, handler, db, "admin@example.com", models.RoleAdmin)
+
+	paperID := createPaper(t, handler, author)
+	reviewID := reviewAndPublish(t, handler, paperID, author, reviewer, admin)
+
+	code, _ := doJSON(t, handler, "POST", "/api/v1/papers/"+strconv.Itoa(int(paperID))+"/mint", admin, nil)
+	assert.Equal(t, 202, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/reviews/"+strconv.Itoa(int(reviewID))+"/mint", admin, nil)
+	assert.Equal(t, 202, code)
+	drainMintJobs(t, mintWorker)
+
+	code, response := doJSON(t, handler, "GET", "/api/v1/nfts", author, nil)
+	assert.Equal(t, 200, code)
+	assert.Len(t, response["data"], 2)
+
+	_, response = doJSON(t, handler, "GET", "/api/v1/nfts?type=paper&owner=0x"+strings.ToUpper(authorWallet[2:]), author, nil)
+	assert.Len(t, response["data"], 1)
+	_, response = doJSON(t, handler, "GET", "/api/v1/nfts?reference_id="+strconv.Itoa(int(reviewID))+"&type=review", author, nil)
+	assert.Len(t, response["data"], 1)
+	code, _ = doJSON(t, handler, "GET", "/api/v1/nfts?type=badge", author, nil)
+	assert.Equal(t, 400, code)
+
+	// Detail joins the paper or review the token was minted for
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1", author, nil)
+	assert.Equal(t, 200, code)
+	detail := response["data"].(map[string]interface{})
+	assert.Equal(t, authorWallet, detail["owner_address"])
+	assert.Equal(t, "A Study of Things", detail["paper"].(map[string]interface{})["title"])
+	assert.NotContains(t, detail, "review")
+
+	_, response = doJSON(t, handler, "GET", "/api/v1/nfts/1?type=review", author, nil)
+	assert.Equal(t, reviewID, response["data"].(map[string]interface{})["review"].(map[string]interface{})["id"])
+
+	// Only the holder can transfer
+	transfer := map[string]interface{}{"type": "paper", "token_id": 1, "to_address": collectorWallet}
+	code, _ = doJSON(t, handler, "POST", "/api/v1/nfts/transfer", collector, transfer)
+	assert.Equal(t, 403, code)
+	code, _ = doJSON(t, handler, "POST", "/api/v1/nfts/transfer", author, map[string]interface{}{"type": "paper", "token_id": 1, "to_address": "0x12"})
+	assert.Equal(t, 400, code)
+
+	code, response = doJSON(t, handler, "POST", "/api/v1/nfts/transfer", author, transfer)
+	assert.Equal(t, 200, code)
+	assert.NotEmpty(t, response["data"].(map[string]interface{})["tx_hash"])
+
+	code, response = doJSON(t, handler, "GET", "/api/v1/nfts/1/transfers", author, nil)
+	assert.Equal(t, 200, code)
+	history := response["data"].([]interface{})
+	assert.Len(t, history, 2)
+	assert.Equal(t, "0x0000000000000000000000000000000000000000", history[0].(map[string]interface{})["from_address"])
+	assert.Equal(t, authorWallet, history[1].(map[string]interface{})["from_address"])
+	assert.Equal(t, collectorWallet, history[1].(map[string]interface{})["to_address"])
+
+	// Ownership follows the transfer
+	_, response = doJSON(t, handler, "GET", "/api/v1/users/"+strconv.Itoa(int(collectorID))+"/nfts", author, nil)
+	assert.Len(t, response["data"], 1)
+	_, response = doJSON(t, handler, "GET", "/api/v1/users/"+strconv.Itoa(int(authorID))+"/nfts", author, nil)
+	assert.Len(t, response["data"], 0)
+
+	code, _ = doJSON(t, handler, "POST", "/api/v1/nfts/transfer", author, transfer)
+	assert.Equal(t, 403, code)
+}
@@ -31,7 +31,23 @@ func AutoMigrate() error {
 		&models.User{},
 		&models.Paper{},
 		&models.Review{},
+		&models.ReviewAssignment{},
+		&models.EditorialDecision{},
 		&models.NFTMetadata{},
+		&models.MintJob{},
+		&models.NFTTransfer{},
+		&models.ChainCheckpoint{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.RevokedToken{},
+		&models.WalletNonce{},
+		&models.EmailToken{},
+		&models.ThrottleState{},
+		&models.LoginFailure{},
+		&models.RecoveryCode{},
+		&models.MFAChallenge{},
+		&models.Setting{},
+		&models.APIKey{},
 	)
 
 	if err != nil {
@@ -0,0 +1,224 @@
+// Package decision drafts editorial decisions on papers from their reviews: it
+// aggregates review scores and recommendations and applies an editorial
+// policy of thresholds and vetoes, explaining each step of the outcome.
+package decision
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+)
+
+// Editorial decisions
+const (
+	Accept        = "accept"
+	MinorRevision = "minor_revision"
+	MajorRevision = "major_revision"
+	Reject        = "reject"
+)
+
+// Decisions lists every editorial decision, most favourable first
+var Decisions = []string{Accept, MinorRevision, MajorRevision, Reject}
+
+// Score aggregation methods
+const (
+	MethodMean     = "mean"
+	MethodMedian   = "median"
+	MethodWeighted = "confidence_weighted"
+)
+
+// Methods lists every score aggregation method
+var Methods = []string{MethodMean, MethodMedian, MethodWeighted}
+
+// Reviewer confidence, in 1-5. Reviews without one count as DefaultConfidence.
+const (
+	MinConfidence     = 1
+	MaxConfidence     = 5
+	DefaultConfidence = 3
+)
+
+// Review recommendations, as submitted by reviewers
+const (
+	RecommendAccept   = "accept"
+	RecommendRevision = "revision"
+	RecommendReject   = "reject"
+)
+
+// Review is the part of a review decisions are drafted from
+type Review struct {
+	Score          int // 1-10
+	Confidence     int // 1-5, or 0 if not given
+	Recommendation string
+}
+
+// Policy is how reviews are turned into a decision. The aggregate score on
+// 1-10 is compared against the thresholds, highest first.
+type Policy struct {
+	RequiredReviews int     `json:"required_reviews"`
+	ScoreMethod     string  `json:"score_method"`
+	AcceptAt        float64 `json:"accept_at"`
+	MinorRevisionAt float64 `json:"minor_revision_at"`
+	MajorRevisionAt float64 `json:"major_revision_at"` // Papers scoring below are rejected
+	UnanimousAccept bool    `json:"unanimous_accept"`  // Accept only if every reviewer recommends acceptance
+	MajorityReject  bool    `json:"majority_reject"`   // Reject if most reviewers recommend rejection, whatever the score
+}
+
+// DefaultPolicy applies until an admin configures one
+func DefaultPolicy() Policy {
+	return Policy{
+		RequiredReviews: 2,
+		ScoreMethod:     MethodWeighted,
+		AcceptAt:        7.5,
+		MinorRevisionAt: 6,
+		MajorRevisionAt: 4,
+		MajorityReject:  true,
+	}
+}
+
+// Check reports what makes p unusable as a policy
+func (p Policy) Check() validation.ValidationErrors {
+	v := validation.NewValidator()
+	v.Range("required_reviews", p.RequiredReviews, 1, 20)
+	v.OneOf("score_method", p.ScoreMethod, Methods)
+	if !(1 <= p.MajorRevisionAt && p.MajorRevisionAt <= p.MinorRevisionAt && p.MinorRevisionAt <= p.AcceptAt && p.AcceptAt <= 10) {
+		v.AddError("thresholds", "must satisfy 1 <= major_revision_at <= minor_revision_at <= accept_at <= 10")
+	}
+	return v.Errors()
+}
+
+// Scores are the aggregates of the review scores
+type Scores struct {
+	Mean     float64 `json:"mean"`
+	Median   float64 `json:"median"`
+	Weighted float64 `json:"confidence_weighted"`
+}
+
+// Draft is the decision proposed to the editor
+type Draft struct {
+	Ready           bool           `json:"ready"` // Enough reviews are complete to decide
+	Reviews         int            `json:"reviews"`
+	RequiredReviews int            `json:"required_reviews"`
+	Scores          Scores         `json:"scores"`
+	ScoreMethod     string         `json:"score_method"`
+	Score           float64        `json:"score"` // The aggregate decided on
+	Recommendations map[string]int `json:"recommendations"`
+	Decision        string         `json:"decision,omitempty"` // Empty until ready
+	Rationale       []string       `json:"rationale"`
+}
+
+// Propose drafts the decision policy makes on reviews
+func Propose(policy Policy, reviews []Review) *Draft {
+	draft := &Draft{
+		Reviews:         len(reviews),
+		RequiredReviews: policy.RequiredReviews,
+		ScoreMethod:     policy.ScoreMethod,
+		Recommendations: map[string]int{RecommendAccept: 0, RecommendRevision: 0, RecommendReject: 0},
+		Rationale:       []string{fmt.Sprintf("%d of %d required reviews complete", len(reviews), policy.RequiredReviews)},
+	}
+	for _, review := range reviews {
+		draft.Recommendations[review.Recommendation]++
+	}
+	if len(reviews) == 0 {
+		return draft
+	}
+
+	draft.Scores = aggregate(reviews)
+	switch policy.ScoreMethod {
+	case MethodMean:
+		draft.Score = draft.Scores.Mean
+	case MethodMedian:
+		draft.Score = draft.Scores.Median
+	default:
+		draft.Score = draft.Scores.Weighted
+	}
+	if len(reviews) < policy.RequiredReviews {
+		return draft
+	}
+
+	draft.Ready = true
+	draft.Decision = byScore(policy, draft.Score)
+	draft.Rationale = append(draft.Rationale, fmt.Sprintf("%s score %.2f %s", methodName(policy.ScoreMethod), draft.Score, thresholdReason(policy, draft.Decision)))
+
+	rejects, accepts := draft.Recommendations[RecommendReject], draft.Recommendations[RecommendAccept]
+	if policy.MajorityReject && rejects*2 > len(reviews) && draft.Decision != Reject {
+		draft.Decision = Reject
+		draft.Rationale = append(draft.Rationale, fmt.Sprintf("%d of %d reviewers recommend rejection, which overrides the score", rejects, len(reviews)))
+	}
+	if policy.UnanimousAccept && draft.Decision == Accept && accepts < len(reviews) {
+		draft.Decision = MinorRevision
+		draft.Rationale = append(draft.Rationale, fmt.Sprintf("only %d of %d reviewers recommend acceptance, so revisions are requested", accepts, len(reviews)))
+	}
+	return draft
+}
+
+// byScore returns the decision the thresholds of policy give score
+func byScore(policy Policy, score float64) string {
+	switch {
+	case score >= policy.AcceptAt:
+		return Accept
+	case score >= policy.MinorRevisionAt:
+		return MinorRevision
+	case score >= policy.MajorRevisionAt:
+		return MajorRevision
+	default:
+		return Reject
+	}
+}
+
+func thresholdReason(policy Policy, decision string) string {
+	switch decision {
+	case Accept:
+		return fmt.Sprintf("reaches the acceptance threshold of %.2f", policy.AcceptAt)
+	case MinorRevision:
+		return fmt.Sprintf("reaches the minor revision threshold of %.2f", policy.MinorRevisionAt)
+	case MajorRevision:
+		return fmt.Sprintf("reaches the major revision threshold of %.2f", policy.MajorRevisionAt)
+	default:
+		return fmt.Sprintf("is below the major revision threshold of %.2f", policy.MajorRevisionAt)
+	}
+}
+
+func methodName(method string) string {
+	switch method {
+	case MethodMean:
+		return "Mean"
+	case MethodMedian:
+		return "Median"
+	default:
+		return "Confidence-weighted"
+	}
+}
+
+func aggregate(reviews []Review) Scores {
+	scores := make([]float64, 0, len(reviews))
+	var total, weighted, weights float64
+	for _, review := range reviews {
+		score := float64(review.Score)
+		confidence := review.Confidence
+		if confidence < MinConfidence || confidence > MaxConfidence {
+			confidence = DefaultConfidence
+		}
+		scores = append(scores, score)
+		total += score
+		weighted += score * float64(confidence)
+		weights += float64(confidence)
+	}
+
+	slices.Sort(scores)
+	median := scores[len(scores)/2]
+	if len(scores)%2 == 0 {
+		median = (scores[len(scores)/2-1] + median) / 2
+	}
+
+	return Scores{
+		Mean:     round(total / float64(len(scores))),
+		Median:   round(median),
+		Weighted: round(weighted / weights),
+	}
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
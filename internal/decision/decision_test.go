@@ -0,0 +1,99 @@
+package decision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	assert.Empty(t, DefaultPolicy().Check())
+
+	errs := Policy{RequiredReviews: 0, ScoreMethod: "mode", AcceptAt: 5, MinorRevisionAt: 6, MajorRevisionAt: 4}.Check()
+	names := make([]string, 0, len(errs))
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+	assert.Equal(t, []string{"required_reviews", "score_method", "thresholds"}, names)
+}
+
+func TestProposeNotReady(t *testing.T) {
+	draft := Propose(DefaultPolicy(), []Review{{Score: 9, Confidence: 5, Recommendation: RecommendAccept}})
+	assert.False(t, draft.Ready)
+	assert.Empty(t, draft.Decision)
+	assert.Equal(t, 9.0, draft.Score)
+	assert.Equal(t, []string{"1 of 2 required reviews complete"}, draft.Rationale)
+
+	draft = Propose(DefaultPolicy(), nil)
+	assert.False(t, draft.Ready)
+	assert.Equal(t, Scores{}, draft.Scores)
+}
+
+func TestProposeAggregates(t *testing.T) {
+	reviews := []Review{
+		{Score: 9, Confidence: 5, Recommendation: RecommendAccept},
+		{Score: 5, Confidence: 1, Recommendation: RecommendRevision},
+		{Score: 8, Recommendation: RecommendAccept},
+	}
+
+	draft := Propose(DefaultPolicy(), reviews)
+	require.True(t, draft.Ready)
+	// (9*5 + 5*1 + 8*3) / 9 = 8.22
+	assert.Equal(t, Scores{Mean: 7.33, Median: 8, Weighted: 8.22}, draft.Scores)
+	assert.Equal(t, 8.22, draft.Score)
+	assert.Equal(t, Accept, draft.Decision)
+	assert.Equal(t, map[string]int{RecommendAccept: 2, RecommendRevision: 1, RecommendReject: 0}, draft.Recommendations)
+	assert.Equal(t, []string{
+		"3 of 2 required reviews complete",
+		"Confidence-weighted score 8.22 reaches the acceptance threshold of 7.50",
+	}, draft.Rationale)
+
+	policy := DefaultPolicy()
+	policy.ScoreMethod = MethodMean
+	draft = Propose(policy, reviews)
+	assert.Equal(t, 7.33, draft.Score)
+	assert.Equal(t, MinorRevision, draft.Decision)
+
+	policy.ScoreMethod = MethodMedian
+	draft = Propose(policy, reviews[1:])
+	assert.Equal(t, 6.5, draft.Score)
+	assert.Equal(t, MinorRevision, draft.Decision)
+}
+
+func TestProposeThresholds(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.RequiredReviews = 1
+	for score, want := range map[int]string{10: Accept, 7: MinorRevision, 5: MajorRevision, 3: Reject} {
+		draft := Propose(policy, []Review{{Score: score, Recommendation: RecommendRevision}})
+		assert.Equal(t, want, draft.Decision, "score %d", score)
+	}
+}
+
+func TestProposeOverrides(t *testing.T) {
+	// Most reviewers recommending rejection overrides a good score
+	draft := Propose(DefaultPolicy(), []Review{
+		{Score: 9, Recommendation: RecommendReject},
+		{Score: 9, Recommendation: RecommendReject},
+		{Score: 9, Recommendation: RecommendAccept},
+	})
+	assert.Equal(t, Reject, draft.Decision)
+	assert.Equal(t, "2 of 3 reviewers recommend rejection, which overrides the score", draft.Rationale[2])
+
+	// Half is not a majority
+	draft = Propose(DefaultPolicy(), []Review{
+		{Score: 9, Recommendation: RecommendReject},
+		{Score: 9, Recommendation: RecommendAccept},
+	})
+	assert.Equal(t, Accept, draft.Decision)
+
+	policy := DefaultPolicy()
+	policy.MajorityReject = false
+	policy.UnanimousAccept = true
+	draft = Propose(policy, []Review{
+		{Score: 9, Recommendation: RecommendAccept},
+		{Score: 9, Recommendation: RecommendRevision},
+	})
+	assert.Equal(t, MinorRevision, draft.Decision)
+	assert.Equal(t, "only 1 of 2 reviewers recommend acceptance, so revisions are requested", draft.Rationale[2])
+}
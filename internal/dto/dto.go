@@ -47,17 +47,181 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string    `json:"token"`
-	User  *UserInfo `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int64     `json:"expires_in"` // Lifetime of Token in seconds
+	User         *UserInfo `json:"user"`
+}
+
+// MFAChallengeResponse answers a login of a user with two-factor
+// authentication; the token is exchanged for an AuthResponse with a code
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"` // Lifetime of MFAToken in seconds
+}
+
+// VerifyMFARequest completes a login with a TOTP or recovery code
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code confirming a two-factor change
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TOTPSetupResponse carries the secret to add to an authenticator app
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse lists new recovery codes, which are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatusResponse describes the two-factor authentication of the current user
+type TwoFactorStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorPolicy is whether every user must enable two-factor authentication
+type TwoFactorPolicy struct {
+	Required *bool `json:"required" validate:"required"`
+}
+
+// ReviewPolicyRequest sets the review policy of papers whose category has none
+type ReviewPolicyRequest struct {
+	Default string `json:"default" validate:"required"`
+}
+
+// CategoryReviewPolicyRequest sets the review policy of a category
+type CategoryReviewPolicyRequest struct {
+	Policy string `json:"policy" validate:"required"`
+}
+
+// RefreshRequest carries the refresh token to rotate or revoke
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// VerifyEmailRequest carries the token of an email verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with the token of a password reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// SIWENonceResponse carries the values a client puts in a Sign-In with Ethereum message
+type SIWENonceResponse struct {
+	Nonce     string    `json:"nonce"`
+	Domain    string    `json:"domain"`
+	Origin    string    `json:"origin"`             // Scheme and host the message URI must belong to
+	ChainID   int       `json:"chain_id,omitempty"` // Required chain, omitted if any chain is accepted
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SIWEVerifyRequest is a signed Sign-In with Ethereum message
+type SIWEVerifyRequest struct {
+	Message   string `json:"message" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// SessionInfo describes an active login of the current user
+type SessionInfo struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Whether the request was made from this session
 }
 
 type UserInfo struct {
-	ID          uint      `json:"id"`
-	Email       string    `json:"email"`
-	Name        string    `json:"name"`
-	Role        string    `json:"role"`
-	Institution string    `json:"institution"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	Role          string    `json:"role"`
+	Institution   string    `json:"institution"`
+	Interests     []string  `json:"interests"`
+	WalletAddress *string   `json:"wallet_address"` // null until a wallet is linked
+	EmailVerified bool      `json:"email_verified"`
+	TwoFactor     bool      `json:"two_factor_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UpdateInterestsRequest replaces the research interests of the current user
+type UpdateInterestsRequest struct {
+	Interests []string `json:"interests" validate:"max=20,dive,max=100"`
+}
+
+// ChangeRoleRequest sets the role of a user
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// LoginFailureInfo is an entry of the failed login audit log
+type LoginFailureInfo struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	UserID    uint      `json:"user_id,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAPIKeyRequest describes a new API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyInfo describes an API key without the key itself
+type APIKeyInfo struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries a new API key, which is shown only once
+type CreateAPIKeyResponse struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// WalletChallengeResponse is the message to sign with the wallet being linked
+type WalletChallengeResponse struct {
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LinkWalletRequest carries the signed wallet challenge
+type LinkWalletRequest struct {
+	Address   string `json:"address" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+	Nonce     string `json:"nonce" validate:"required"`
 }
 
 // Paper DTOs
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"time"
 )
 
 // ErrorCode represents application error codes
@@ -15,24 +17,56 @@ const (
 	ErrNotFound   ErrorCode = "NOT_FOUND"
 	ErrConflict   ErrorCode = "CONFLICT"
 
+	// Routing errors
+	ErrMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+
 	// Authentication errors
 	ErrUnauthorized ErrorCode = "UNAUTHORIZED"
 	ErrForbidden    ErrorCode = "FORBIDDEN"
 	ErrInvalidToken ErrorCode = "INVALID_TOKEN"
 	ErrTokenExpired ErrorCode = "TOKEN_EXPIRED"
 
+	// Two-factor authentication errors
+	ErrInvalidMFACode   ErrorCode = "INVALID_MFA_CODE"
+	ErrMFASetupRequired ErrorCode = "MFA_SETUP_REQUIRED"
+
+	// Rate limiting errors
+	ErrTooManyRequests ErrorCode = "TOO_MANY_REQUESTS"
+
 	// Validation errors
 	ErrValidation    ErrorCode = "VALIDATION_ERROR"
 	ErrMissingField  ErrorCode = "MISSING_FIELD"
 	ErrInvalidFormat ErrorCode = "INVALID_FORMAT"
 
 	// Business logic errors
-	ErrUserExists      ErrorCode = "USER_EXISTS"
-	ErrUserNotFound    ErrorCode = "USER_NOT_FOUND"
-	ErrPaperNotFound   ErrorCode = "PAPER_NOT_FOUND"
-	ErrReviewNotFound  ErrorCode = "REVIEW_NOT_FOUND"
-	ErrInvalidOwner    ErrorCode = "INVALID_OWNER"
-	ErrAlreadyReviewed ErrorCode = "ALREADY_REVIEWED"
+	ErrUserExists         ErrorCode = "USER_EXISTS"
+	ErrUserNotFound       ErrorCode = "USER_NOT_FOUND"
+	ErrPaperNotFound      ErrorCode = "PAPER_NOT_FOUND"
+	ErrReviewNotFound     ErrorCode = "REVIEW_NOT_FOUND"
+	ErrInvalidOwner       ErrorCode = "INVALID_OWNER"
+	ErrAlreadyReviewed    ErrorCode = "ALREADY_REVIEWED"
+	ErrEmailNotVerified   ErrorCode = "EMAIL_NOT_VERIFIED"
+	ErrAssignmentNotFound ErrorCode = "ASSIGNMENT_NOT_FOUND"
+	ErrNotAssigned        ErrorCode = "NOT_ASSIGNED"
+	ErrAlreadyAssigned    ErrorCode = "ALREADY_ASSIGNED"
+	ErrReviewsIncomplete  ErrorCode = "REVIEWS_INCOMPLETE"
+
+	// Paper lifecycle errors
+	ErrInvalidTransition ErrorCode = "INVALID_STATUS_TRANSITION"
+
+	// NFT errors
+	ErrNotPublished  ErrorCode = "PAPER_NOT_PUBLISHED"
+	ErrAlreadyMinted ErrorCode = "ALREADY_MINTED"
+	ErrBlockchain    ErrorCode = "BLOCKCHAIN_ERROR"
+	ErrNoWallet      ErrorCode = "WALLET_REQUIRED"
+
+	// Wallet authentication errors
+	ErrInvalidSignature ErrorCode = "INVALID_SIGNATURE"
+
+	// File storage errors
+	ErrFileTooLarge         ErrorCode = "FILE_TOO_LARGE"
+	ErrUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	ErrStorage              ErrorCode = "STORAGE_ERROR"
 )
 
 // AppError represents an application error
@@ -42,6 +76,7 @@ type AppError struct {
 	Details    interface{} `json:"details,omitempty"`
 	Cause      error       `json:"-"`
 	StatusCode int         `json:"-"`
+	RetryAfter int         `json:"-"` // Seconds the client should wait before retrying, sent as Retry-After
 }
 
 // Error implements the error interface
@@ -93,14 +128,24 @@ func getStatusCode(code ErrorCode) int {
 	switch code {
 	case ErrBadRequest, ErrValidation, ErrMissingField, ErrInvalidFormat:
 		return http.StatusBadRequest
-	case ErrUnauthorized, ErrInvalidToken, ErrTokenExpired:
+	case ErrUnauthorized, ErrInvalidToken, ErrTokenExpired, ErrInvalidSignature, ErrInvalidMFACode:
 		return http.StatusUnauthorized
-	case ErrForbidden, ErrInvalidOwner:
+	case ErrForbidden, ErrInvalidOwner, ErrEmailNotVerified, ErrMFASetupRequired, ErrNotAssigned:
 		return http.StatusForbidden
-	case ErrNotFound, ErrUserNotFound, ErrPaperNotFound, ErrReviewNotFound:
+	case ErrNotFound, ErrUserNotFound, ErrPaperNotFound, ErrReviewNotFound, ErrAssignmentNotFound:
 		return http.StatusNotFound
-	case ErrConflict, ErrUserExists, ErrAlreadyReviewed:
+	case ErrConflict, ErrUserExists, ErrAlreadyReviewed, ErrAlreadyAssigned, ErrInvalidTransition, ErrNotPublished, ErrAlreadyMinted, ErrNoWallet, ErrReviewsIncomplete:
 		return http.StatusConflict
+	case ErrBlockchain, ErrStorage:
+		return http.StatusBadGateway
+	case ErrFileTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case ErrMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case ErrTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -134,6 +179,11 @@ func Forbidden(message string) *AppError {
 	return New(ErrForbidden, message)
 }
 
+// MethodNotAllowed creates a method not allowed error
+func MethodNotAllowed() *AppError {
+	return New(ErrMethodNotAllowed, "Method not allowed")
+}
+
 // Internal creates an internal server error
 func Internal(message string, cause error) *AppError {
 	return Wrap(cause, ErrInternal, message)
@@ -149,6 +199,13 @@ func Conflict(message string) *AppError {
 	return New(ErrConflict, message)
 }
 
+// TooManyRequests creates a rate limiting error telling the client to retry after wait
+func TooManyRequests(message string, wait time.Duration) *AppError {
+	err := New(ErrTooManyRequests, message)
+	err.RetryAfter = int(math.Ceil(wait.Seconds()))
+	return err
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) bool {
 	_, ok := err.(*AppError)
@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/decision"
+	"github.com/nshmdayo/nft-platform-sample/internal/dto"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/rubric"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// AdminHandler handles user administration requests. Access is restricted to
+// admins by the router.
+type AdminHandler struct {
+	BaseHandler
+	authService *service.AuthService
+	policies    *service.ReviewPolicies
+	rubrics     *service.ReviewRubrics
+	decisions   *service.DecisionService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(authService *service.AuthService, policies *service.ReviewPolicies, rubrics *service.ReviewRubrics, decisions *service.DecisionService) *AdminHandler {
+	return &AdminHandler{
+		authService: authService,
+		policies:    policies,
+		rubrics:     rubrics,
+		decisions:   decisions,
+	}
+}
+
+// ListUsers handles listing users
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, limit := h.ParsePagination(r)
+
+	users, err := h.authService.ListUsers(page, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	infos := make([]*dto.UserInfo, 0, len(users))
+	for i := range users {
+		infos = append(infos, toUserInfo(&users[i]))
+	}
+
+	h.SendResponse(w, http.StatusOK, infos)
+}
+
+// ChangeUserRole handles setting the role of a user
+func (h *AdminHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	userID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("role", req.Role)
+	validator.OneOf("role", req.Role, models.Roles)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	user, err := h.authService.ChangeUserRole(userID, req.Role)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Role changed by admin", "admin_id", adminID, "user_id", userID, "role", req.Role)
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// RevokeUserSessions handles an admin signing a user out of every session
+func (h *AdminHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	revoked, err := h.authService.RevokeAllSessions(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("User sessions revoked", "user_id", userID, "sessions", revoked)
+	h.SendResponse(w, http.StatusOK, map[string]int{"revoked": revoked})
+}
+
+// UnlockUser handles lifting the login lockout of a user's account
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	userID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	user, err := h.authService.UnlockUser(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Account unlocked by admin", "admin_id", adminID, "user_id", userID)
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// UnlockIP handles lifting the login lockout and registration limit of a client address
+func (h *AdminHandler) UnlockIP(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	addr, err := netip.ParseAddr(r.PathValue("ip"))
+	if err != nil {
+		h.SendError(w, errors.BadRequest("Invalid IP address"))
+		return
+	}
+
+	if err := h.authService.UnlockIP(addr.Unmap().String()); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Address unlocked by admin", "admin_id", adminID, "ip", addr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListLoginFailures handles listing the failed login audit log, optionally
+// filtered by the email and ip query parameters
+func (h *AdminHandler) ListLoginFailures(w http.ResponseWriter, r *http.Request) {
+	page, limit := h.ParsePagination(r)
+	query := r.URL.Query()
+
+	failures, err := h.authService.ListLoginFailures(query.Get("email"), query.Get("ip"), page, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	infos := make([]dto.LoginFailureInfo, 0, len(failures))
+	for _, failure := range failures {
+		infos = append(infos, dto.LoginFailureInfo{
+			ID:        failure.ID,
+			Email:     failure.Email,
+			UserID:    failure.UserID,
+			IPAddress: failure.IPAddress,
+			UserAgent: failure.UserAgent,
+			Reason:    failure.Reason,
+			CreatedAt: failure.CreatedAt,
+		})
+	}
+
+	h.SendResponse(w, http.StatusOK, infos)
+}
+
+// ResetUserTwoFactor handles turning off the two-factor authentication of a
+// user who lost their authenticator
+func (h *AdminHandler) ResetUserTwoFactor(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	userID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	user, err := h.authService.ResetTwoFactor(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Two-factor authentication reset by admin", "admin_id", adminID, "user_id", userID)
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// GetTwoFactorPolicy handles getting whether every user must enable two-factor authentication
+func (h *AdminHandler) GetTwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	required, err := h.authService.TwoFactorRequired()
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.TwoFactorPolicy{Required: &required})
+}
+
+// SetTwoFactorPolicy handles setting whether every user must enable two-factor authentication
+func (h *AdminHandler) SetTwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req dto.TwoFactorPolicy
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	if req.Required == nil {
+		validator.AddError("required", "is required")
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.authService.SetTwoFactorRequired(adminID, *req.Required); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &req)
+}
+
+// GetReviewPolicies handles getting the default and per-category review policies
+func (h *AdminHandler) GetReviewPolicies(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.policies.Settings()
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, settings)
+}
+
+// SetReviewPolicy handles setting the review policy of papers whose category has none
+func (h *AdminHandler) SetReviewPolicy(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReviewPolicyRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("default", req.Default)
+	validator.OneOf("default", req.Default, models.ReviewPolicies)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.policies.SetDefault(req.Default); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.GetReviewPolicies(w, r)
+}
+
+// SetCategoryReviewPolicy handles setting the review policy of a category
+func (h *AdminHandler) SetCategoryReviewPolicy(w http.ResponseWriter, r *http.Request) {
+	var req dto.CategoryReviewPolicyRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("category", r.PathValue("category"))
+	validator.Required("policy", req.Policy)
+	validator.OneOf("policy", req.Policy, models.ReviewPolicies)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.policies.SetCategoryPolicy(r.PathValue("category"), req.Policy); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.GetReviewPolicies(w, r)
+}
+
+// DeleteCategoryReviewPolicy handles making a category follow the default review policy again
+func (h *AdminHandler) DeleteCategoryReviewPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.policies.SetCategoryPolicy(r.PathValue("category"), ""); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.GetReviewPolicies(w, r)
+}
+
+// GetReviewRubrics handles getting the default and per-category review rubrics
+func (h *AdminHandler) GetReviewRubrics(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.rubrics.Settings()
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, settings)
+}
+
+// SetReviewRubric handles replacing the rubric of papers whose category has none
+func (h *AdminHandler) SetReviewRubric(w http.ResponseWriter, r *http.Request) {
+	next, ok := h.decodeRubric(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.rubrics.SetDefault(*next)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, saved)
+}
+
+// SetCategoryReviewRubric handles replacing the rubric of a category
+func (h *AdminHandler) SetCategoryReviewRubric(w http.ResponseWriter, r *http.Request) {
+	next, ok := h.decodeRubric(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.rubrics.SetCategoryRubric(r.PathValue("category"), *next)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, saved)
+}
+
+// DeleteCategoryReviewRubric handles making a category use the default rubric again
+func (h *AdminHandler) DeleteCategoryReviewRubric(w http.ResponseWriter, r *http.Request) {
+	if err := h.rubrics.DeleteCategoryRubric(r.PathValue("category")); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRubric reads a rubric from the request body and reports any error
+func (h *AdminHandler) decodeRubric(w http.ResponseWriter, r *http.Request) (*rubric.Rubric, bool) {
+	var next rubric.Rubric
+	if err := h.DecodeJSON(r, &next); err != nil {
+		h.SendError(w, err)
+		return nil, false
+	}
+
+	if errs := next.Check(); len(errs) > 0 {
+		h.SendError(w, errors.Validation("Validation failed", errs))
+		return nil, false
+	}
+	return &next, true
+}
+
+// GetDecisionPolicy handles getting the policy editorial decisions are drafted under
+func (h *AdminHandler) GetDecisionPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.decisions.Policy()
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, policy)
+}
+
+// SetDecisionPolicy handles replacing the policy editorial decisions are drafted under
+func (h *AdminHandler) SetDecisionPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy decision.Policy
+	if err := h.DecodeJSON(r, &policy); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if errs := policy.Check(); len(errs) > 0 {
+		h.SendError(w, errors.Validation("Validation failed", errs))
+		return
+	}
+
+	if err := h.decisions.SetPolicy(policy); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &policy)
+}
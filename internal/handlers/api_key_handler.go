@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/dto"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+)
+
+// CreateAPIKey handles issuing an API key for the current user
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("name", req.Name).MaxLength("name", req.Name, 100)
+	validator.ArrayNotEmpty("scopes", req.Scopes)
+	for _, scope := range req.Scopes {
+		validator.OneOf("scopes", scope, models.APIKeyScopes)
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	key, secret, err := h.authService.CreateAPIKey(userID, &service.CreateAPIKeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusCreated, &dto.CreateAPIKeyResponse{
+		APIKeyInfo: toAPIKeyInfo(key),
+		Key:        secret,
+	})
+}
+
+// ListAPIKeys handles listing the API keys of the current user
+func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	keys, err := h.authService.ListAPIKeys(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	infos := make([]dto.APIKeyInfo, 0, len(keys))
+	for i := range keys {
+		infos = append(infos, toAPIKeyInfo(&keys[i]))
+	}
+
+	h.SendResponse(w, http.StatusOK, infos)
+}
+
+// RevokeAPIKey handles revoking one of the current user's API keys
+func (h *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	keyID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if err := h.authService.RevokeAPIKey(userID, keyID); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// toAPIKeyInfo converts an API key to its DTO
+func toAPIKeyInfo(key *models.APIKey) dto.APIKeyInfo {
+	return dto.APIKeyInfo{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/nshmdayo/nft-platform-sample/internal/dto"
 	"github.com/nshmdayo/nft-platform-sample/internal/errors"
 	"github.com/nshmdayo/nft-platform-sample/internal/middleware"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/service"
 	"github.com/nshmdayo/nft-platform-sample/internal/validation"
 	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
@@ -26,11 +27,6 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 
 // Register handles user registration
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	if err := h.ValidateMethod(r, http.MethodPost); err != nil {
-		h.SendError(w, err)
-		return
-	}
-
 	var req dto.RegisterRequest
 	if err := h.DecodeJSON(r, &req); err != nil {
 		h.SendError(w, err)
@@ -54,28 +50,22 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Password:    req.Password,
 		Name:        req.Name,
 		Institution: req.Institution,
-		WalletAddr:  "", // Will be set later when blockchain integration is added
+		Client:      clientInfo(r),
 	}
 
 	response, err := h.authService.Register(serviceReq)
 	if err != nil {
 		logger.Error("Failed to register user", "error", err, "email", req.Email)
+		if errors.IsAppError(err) {
+			h.SendError(w, err)
+			return
+		}
 		h.SendError(w, errors.Conflict("User registration failed"))
 		return
 	}
 
 	// Convert to DTO response
-	authResponse := &dto.AuthResponse{
-		Token: response.Token,
-		User: &dto.UserInfo{
-			ID:          response.User.ID,
-			Email:       response.User.Email,
-			Name:        response.User.Name,
-			Role:        response.User.Role,
-			Institution: response.User.Institution,
-			CreatedAt:   response.User.CreatedAt,
-		},
-	}
+	authResponse := toAuthResponse(response)
 
 	logger.Info("User registered successfully", "user_id", response.User.ID, "email", req.Email)
 	h.SendResponse(w, http.StatusCreated, authResponse)
@@ -83,11 +73,6 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login handles user login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	if err := h.ValidateMethod(r, http.MethodPost); err != nil {
-		h.SendError(w, err)
-		return
-	}
-
 	var req dto.LoginRequest
 	if err := h.DecodeJSON(r, &req); err != nil {
 		h.SendError(w, err)
@@ -108,39 +93,233 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	serviceReq := &service.LoginRequest{
 		Email:    req.Email,
 		Password: req.Password,
+		Client:   clientInfo(r),
 	}
 
 	response, err := h.authService.Login(serviceReq)
 	if err != nil {
 		logger.Error("Failed to login user", "error", err, "email", req.Email)
+		// Throttled logins carry their own status and Retry-After
+		if errors.IsAppError(err) {
+			h.SendError(w, err)
+			return
+		}
 		h.SendError(w, errors.Unauthorized("Invalid credentials"))
 		return
 	}
 
-	// Convert to DTO response
-	authResponse := &dto.AuthResponse{
-		Token: response.Token,
-		User: &dto.UserInfo{
-			ID:          response.User.ID,
-			Email:       response.User.Email,
-			Name:        response.User.Name,
-			Role:        response.User.Role,
-			Institution: response.User.Institution,
-			CreatedAt:   response.User.CreatedAt,
-		},
+	if response.MFAToken != "" {
+		logger.Info("Password accepted, awaiting two-factor code", "email", req.Email)
+	} else {
+		logger.Info("User logged in successfully", "user_id", response.User.ID, "email", req.Email)
 	}
+	h.SendResponse(w, http.StatusOK, toLoginResponse(response))
+}
 
-	logger.Info("User logged in successfully", "user_id", response.User.ID, "email", req.Email)
-	h.SendResponse(w, http.StatusOK, authResponse)
+// Refresh handles exchanging a refresh token for a new token pair
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("refresh_token", req.RefreshToken)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	response, err := h.authService.Refresh(req.RefreshToken, clientInfo(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, toAuthResponse(response))
 }
 
-// GetProfile handles getting user profile
-func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	if err := h.ValidateMethod(r, http.MethodGet); err != nil {
+// Logout handles revoking the refresh tokens of the current login
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("refresh_token", req.RefreshToken)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		logger.Error("Failed to log out", "error", err)
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail handles confirming an email address with the token of a verification link
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("token", req.Token)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	user, err := h.authService.VerifyEmail(req.Token)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// ResendVerificationEmail handles sending the current user a new verification link
+func (h *AuthHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(userID); err != nil {
+		logger.Error("Failed to resend verification email", "error", err, "user_id", userID)
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword handles requesting a password reset link. The response is the
+// same whether or not the address belongs to an account.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("email", req.Email).Email("email", req.Email)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		logger.Error("Failed to send password reset email", "error", err)
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword handles setting a new password with the token of a reset link
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("token", req.Token)
+	validator.Required("password", req.Password).MinLength("password", req.Password, 8)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.Password); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SIWENonce handles issuing a nonce for a Sign-In with Ethereum message
+func (h *AuthHandler) SIWENonce(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.authService.IssueSIWENonce()
+	if err != nil {
+		logger.Error("Failed to issue SIWE nonce", "error", err)
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.SIWENonceResponse{
+		Nonce:     challenge.Nonce,
+		Domain:    challenge.Domain,
+		Origin:    challenge.Origin,
+		ChainID:   challenge.ChainID,
+		ExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// SIWEVerify handles logging in with a signed Sign-In with Ethereum message.
+// Signed-in users link the wallet to their account on first use.
+func (h *AuthHandler) SIWEVerify(w http.ResponseWriter, r *http.Request) {
+	var req dto.SIWEVerifyRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("message", req.Message)
+	validator.Required("signature", req.Signature)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	// Authentication is optional on this route
+	userID, _ := GetUserIDFromContext(r)
+
+	response, err := h.authService.SignInWithEthereum(&service.SIWERequest{
+		Message:   req.Message,
+		Signature: req.Signature,
+		UserID:    userID,
+		Client:    clientInfo(r),
+	})
+	if err != nil {
 		h.SendError(w, err)
 		return
 	}
 
+	h.SendResponse(w, http.StatusOK, toLoginResponse(response))
+}
+
+// GetProfile handles getting user profile
+func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
 		h.SendError(w, errors.Unauthorized("User not authenticated"))
@@ -154,16 +333,175 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userInfo := &dto.UserInfo{
-		ID:          user.ID,
-		Email:       user.Email,
-		Name:        user.Name,
-		Role:        user.Role,
-		Institution: user.Institution,
-		CreatedAt:   user.CreatedAt,
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// UpdateInterests handles replacing the research interests of the current user
+func (h *AuthHandler) UpdateInterests(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	h.SendResponse(w, http.StatusOK, userInfo)
+	var req dto.UpdateInterestsRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	if len(req.Interests) > 20 {
+		validator.AddError("interests", "must contain no more than 20 items")
+	}
+	for _, interest := range req.Interests {
+		validator.MaxLength("interests", interest, 100)
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	user, err := h.authService.SetInterests(userID, req.Interests)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// WalletChallenge handles issuing the message to sign for linking a wallet
+func (h *AuthHandler) WalletChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	challenge, err := h.authService.IssueWalletChallenge(userID)
+	if err != nil {
+		logger.Error("Failed to issue wallet challenge", "error", err, "user_id", userID)
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.WalletChallengeResponse{
+		Nonce:     challenge.Nonce,
+		Message:   challenge.Message,
+		ExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// LinkWallet handles linking a wallet that signed the challenge to the current user
+func (h *AuthHandler) LinkWallet(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req dto.LinkWalletRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("address", req.Address)
+	validator.Required("signature", req.Signature)
+	validator.Required("nonce", req.Nonce)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	user, err := h.authService.LinkWallet(userID, &service.LinkWalletRequest{
+		Address:   req.Address,
+		Signature: req.Signature,
+		Nonce:     req.Nonce,
+	})
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, toUserInfo(user))
+}
+
+// UnlinkWallet handles removing the wallet of the current user
+func (h *AuthHandler) UnlinkWallet(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if err := h.authService.UnlinkWallet(userID); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions handles listing the active sessions of the current user
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID)
+	if err != nil {
+		logger.Error("Failed to list sessions", "error", err, "user_id", userID)
+		h.SendError(w, err)
+		return
+	}
+
+	currentID, _ := r.Context().Value(middleware.SessionIDKey).(uint)
+	infos := make([]dto.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, dto.SessionInfo{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	h.SendResponse(w, http.StatusOK, infos)
+}
+
+// RevokeSession handles signing out one of the current user's sessions
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	sessionID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Session revoked", "user_id", userID, "session_id", sessionID)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // GetUserIDFromContext extracts user ID from request context
@@ -180,3 +518,66 @@ func GetUserIDFromContext(r *http.Request) (uint, error) {
 
 	return userIDUint, nil
 }
+
+// GetRoleFromContext returns the role of the authenticated user, or "" if unknown
+func GetRoleFromContext(r *http.Request) string {
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	return role
+}
+
+// viewerOf describes the authenticated user to hide identities from under
+// blind review; editors see every identity
+func viewerOf(r *http.Request) service.Viewer {
+	userID, _ := GetUserIDFromContext(r)
+	return service.Viewer{
+		UserID: userID,
+		Editor: middleware.HasPermission(GetRoleFromContext(r), middleware.PermPaperDecide),
+	}
+}
+
+// clientInfo describes the device making the request for session tracking
+func clientInfo(r *http.Request) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: middleware.ClientIP(r),
+	}
+}
+
+// toLoginResponse converts the outcome of a login to its DTO: the issued token
+// pair, or the challenge of a user with two-factor authentication
+func toLoginResponse(response *service.AuthResponse) interface{} {
+	if response.MFAToken != "" {
+		return &dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    response.MFAToken,
+			ExpiresIn:   response.ExpiresIn,
+		}
+	}
+	return toAuthResponse(response)
+}
+
+// toAuthResponse converts an issued token pair to its DTO
+func toAuthResponse(response *service.AuthResponse) *dto.AuthResponse {
+	return &dto.AuthResponse{
+		Token:        response.Token,
+		RefreshToken: response.RefreshToken,
+		ExpiresIn:    response.ExpiresIn,
+		User:         toUserInfo(response.User),
+	}
+}
+
+// toUserInfo converts a user to its DTO
+func toUserInfo(user *models.User) *dto.UserInfo {
+	return &dto.UserInfo{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		Institution:   user.Institution,
+		Interests:     user.InterestList(),
+		WalletAddress: user.WalletAddr,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		TwoFactor:     user.TOTPEnabledAt != nil,
+		CreatedAt:     user.CreatedAt,
+	}
+}
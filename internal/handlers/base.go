@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/nshmdayo/nft-platform-sample/internal/dto"
@@ -53,6 +55,9 @@ func (h *BaseHandler) SendError(w http.ResponseWriter, err error) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if appErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(appErr.RetryAfter))
+	}
 	w.WriteHeader(appErr.StatusCode)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -76,12 +81,19 @@ func (h *BaseHandler) DecodeJSON(r *http.Request, dest interface{}) error {
 	return nil
 }
 
-// ExtractIDFromPath extracts ID from URL path
-func (h *BaseHandler) ExtractIDFromPath(path string, resourceName string) (uint, error) {
-	// This is a simplified implementation
-	// In a real application, you might want to use a proper router
-	// that handles path parameters more elegantly
-	return 0, errors.BadRequest("Path parameter extraction not implemented")
+// PathUint parses the named path wildcard (e.g. "id" in /papers/{id}) as an unsigned ID
+func PathUint(r *http.Request, name string) (uint, error) {
+	value := r.PathValue(name)
+	if value == "" {
+		return 0, errors.BadRequest(fmt.Sprintf("Missing path parameter: %s", name))
+	}
+
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || id == 0 {
+		return 0, errors.New(errors.ErrInvalidFormat, fmt.Sprintf("Invalid path parameter: %s", name))
+	}
+
+	return uint(id), nil
 }
 
 // ValidateMethod checks if the request method is allowed
@@ -91,7 +103,7 @@ func (h *BaseHandler) ValidateMethod(r *http.Request, allowedMethods ...string)
 			return nil
 		}
 	}
-	return errors.New(errors.ErrBadRequest, "Method not allowed")
+	return errors.MethodNotAllowed()
 }
 
 // ParseQueryParams parses common query parameters
@@ -124,12 +136,31 @@ func (h *BaseHandler) ParseQueryParams(r *http.Request) *dto.QueryParams {
 	return params
 }
 
+// ParsePagination parses the page and limit query parameters used by list endpoints
+func (h *BaseHandler) ParsePagination(r *http.Request) (page, limit int) {
+	query := r.URL.Query()
+
+	page = parseInt(query.Get("page"), 1)
+	if page <= 0 {
+		page = 1
+	}
+
+	limit = parseInt(query.Get("limit"), 10)
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	return page, limit
+}
+
 // Helper function to parse integer with default value
 func parseInt(s string, defaultVal int) int {
-	// Simplified implementation - in production use strconv.Atoi
 	if s == "" {
 		return defaultVal
 	}
-	// TODO: Implement proper integer parsing
-	return defaultVal
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return defaultVal
+	}
+	return i
 }
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/decision"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+)
+
+// GetDecisionDraft handles drafting the editorial decision on a paper from its current round of reviews
+func (h *ReviewHandler) GetDecisionDraft(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	draft, err := h.decisionService.DraftDecision(paperID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, draft)
+}
+
+// ConfirmDecision handles an editor confirming or overriding the drafted decision
+// on a paper; the body is optional when confirming
+func (h *ReviewHandler) ConfirmDecision(w http.ResponseWriter, r *http.Request) {
+	editorID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req service.ConfirmDecisionRequest
+	if r.ContentLength != 0 {
+		if err := h.DecodeJSON(r, &req); err != nil {
+			h.SendError(w, err)
+			return
+		}
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	if req.Decision != "" {
+		validator.OneOf("decision", req.Decision, decision.Decisions)
+	}
+	validator.MaxLength("comment", req.Comment, 5000)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	record, err := h.decisionService.ConfirmDecision(paperID, &req, editorID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusCreated, record)
+}
+
+// ListDecisions handles listing the decisions on a paper to its author or an editor
+func (h *ReviewHandler) ListDecisions(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	decisions, err := h.decisionService.ListDecisions(paperID, viewerOf(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, decisions)
+}
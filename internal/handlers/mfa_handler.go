@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/dto"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// VerifyMFA handles completing a login with a TOTP or recovery code
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyMFARequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("mfa_token", req.MFAToken)
+	validator.Required("code", req.Code)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	response, err := h.authService.VerifyMFA(req.MFAToken, req.Code, clientInfo(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("User logged in with two-factor authentication", "user_id", response.User.ID)
+	h.SendResponse(w, http.StatusOK, toAuthResponse(response))
+}
+
+// TwoFactorStatus handles getting the two-factor authentication status of the current user
+func (h *AuthHandler) TwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.TwoFactorStatusResponse{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodes,
+	})
+}
+
+// SetupTOTP handles generating a TOTP secret for the current user
+func (h *AuthHandler) SetupTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	setup, err := h.authService.SetupTOTP(userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.TOTPSetupResponse{
+		Secret:     setup.Secret,
+		OTPAuthURI: setup.URI,
+	})
+}
+
+// EnableTOTP handles turning on two-factor authentication with a code from the authenticator app
+func (h *AuthHandler) EnableTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	code, ok := h.decodeMFACode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.EnableTOTP(userID, code)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP handles turning off two-factor authentication of the current user
+func (h *AuthHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	code, ok := h.decodeMFACode(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DisableTOTP(userID, code); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes of the current user
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	code, ok := h.decodeMFACode(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, code)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, &dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// decodeMFACode reads the code confirming a two-factor change, writing the
+// error response if the request is invalid
+func (h *AuthHandler) decodeMFACode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req dto.MFACodeRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return "", false
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("code", req.Code)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return "", false
+	}
+	return req.Code, true
+}
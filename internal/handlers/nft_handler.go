@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// NFTHandler handles NFT related requests
+type NFTHandler struct {
+	BaseHandler
+	nftService *service.NFTService
+	policies   *service.ReviewPolicies
+}
+
+// NewNFTHandler creates a new NFT handler
+func NewNFTHandler(nftService *service.NFTService, policies *service.ReviewPolicies) *NFTHandler {
+	return &NFTHandler{
+		nftService: nftService,
+		policies:   policies,
+	}
+}
+
+// MintPaper handles queueing a published paper to be minted as an NFT
+func (h *NFTHandler) MintPaper(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	job, err := h.nftService.RequestPaperMint(paperID, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Paper mint queued", "paper_id", paperID, "job_id", job.ID, "status", job.Status)
+	h.SendResponse(w, http.StatusAccepted, job)
+}
+
+// MintReview handles queueing a review of a published paper to be minted as an NFT
+func (h *NFTHandler) MintReview(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	reviewID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	job, err := h.nftService.RequestReviewMint(reviewID, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Review mint queued", "review_id", reviewID, "job_id", job.ID, "status", job.Status)
+	h.SendResponse(w, http.StatusAccepted, job)
+}
+
+// GetMintJob handles polling the status of a mint job
+func (h *NFTHandler) GetMintJob(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	jobID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	job, err := h.nftService.GetMintJob(jobID, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, job)
+}
+
+// GetTokenMetadata handles serving the ERC-721 metadata document of a token so a
+// contract's tokenURI can point at the API. The ?type query selects the paper
+// (default) or review collection. The document is sent as is, without the API envelope.
+func (h *NFTHandler) GetTokenMetadata(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := PathUint(r, "tokenId")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	doc, err := h.nftService.GetTokenMetadata(r.Context(), nftTypeQuery(r), tokenID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc)
+}
+
+// ListNFTs handles listing minted tokens, optionally filtered by type, owner
+// address and the ID of the paper or review they were minted for
+func (h *NFTHandler) ListNFTs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.NFTFilter{
+		Type:      query.Get("type"),
+		OwnerAddr: query.Get("owner"),
+	}
+	if ref := query.Get("reference_id"); ref != "" {
+		referenceID := parseInt(ref, 0)
+		if referenceID <= 0 {
+			h.SendError(w, errors.New(errors.ErrInvalidFormat, "reference_id must be a positive integer"))
+			return
+		}
+		filter.ReferenceID = uint(referenceID)
+	}
+
+	page, limit := h.ParsePagination(r)
+
+	nfts, err := h.nftService.ListNFTs(filter, page, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, nfts)
+}
+
+// GetNFT handles getting a token together with its paper or review
+func (h *NFTHandler) GetNFT(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := PathUint(r, "tokenId")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	detail, err := h.nftService.GetNFT(nftTypeQuery(r), tokenID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	view, err := h.policies.NFTView(detail, viewerOf(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, view)
+}
+
+// GetNFTTransfers handles listing the ownership history of a token
+func (h *NFTHandler) GetNFTTransfers(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := PathUint(r, "tokenId")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	transfers, err := h.nftService.GetTransferHistory(nftTypeQuery(r), tokenID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, transfers)
+}
+
+// TransferNFT handles transferring a token held by the user's wallet
+func (h *NFTHandler) TransferNFT(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req service.TransferNFTRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.OneOf("type", req.Type, []string{models.NFTTypePaper, models.NFTTypeReview})
+	validator.Required("to_address", req.ToAddress)
+	if req.TokenID == 0 {
+		validator.AddError("token_id", "is required")
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	transfer, err := h.nftService.TransferNFT(r.Context(), &req, userID)
+	if err != nil {
+		logger.Error("Failed to transfer NFT", "error", err, "type", req.Type, "token_id", req.TokenID, "user_id", userID)
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("NFT transferred", "type", req.Type, "token_id", req.TokenID, "to", transfer.ToAddr, "tx_hash", transfer.TxHash)
+	h.SendResponse(w, http.StatusOK, transfer)
+}
+
+// GetUserNFTs handles listing the tokens held by a user's wallet
+func (h *NFTHandler) GetUserNFTs(w http.ResponseWriter, r *http.Request) {
+	userID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	page, limit := h.ParsePagination(r)
+
+	nfts, err := h.nftService.GetUserNFTs(userID, page, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, nfts)
+}
+
+// nftTypeQuery returns the ?type query selecting the paper (default) or review collection
+func nftTypeQuery(r *http.Request) string {
+	if nftType := r.URL.Query().Get("type"); nftType != "" {
+		return nftType
+	}
+	return models.NFTTypePaper
+}
@@ -1,203 +1,344 @@
 package handlers
 
 import (
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
-	"strconv"
 
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
 )
 
+// PaperHandler handles paper related requests
 type PaperHandler struct {
+	BaseHandler
 	paperService *service.PaperService
+	policies     *service.ReviewPolicies
 }
 
-func NewPaperHandler(paperService *service.PaperService) *PaperHandler {
+// NewPaperHandler creates a new paper handler
+func NewPaperHandler(paperService *service.PaperService, policies *service.ReviewPolicies) *PaperHandler {
 	return &PaperHandler{
 		paperService: paperService,
+		policies:     policies,
 	}
 }
 
+// CreatePaper handles paper creation
 func (h *PaperHandler) CreatePaper(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		SendMethodNotAllowedResponse(w)
-		return
-	}
-
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
 	var req service.CreatePaperRequest
-	if err := DecodeJSONRequest(r, &req); err != nil {
-		SendValidationErrorResponse(w, err.Error())
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	// Validate required fields
-	if err := ValidateRequiredFields(map[string]interface{}{
-		"title":    req.Title,
-		"abstract": req.Abstract,
-		"category": req.Category,
-	}); err != nil {
-		SendValidationErrorResponse(w, err.Error())
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("title", req.Title)
+	validator.Required("abstract", req.Abstract)
+	validator.Required("category", req.Category)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
 		return
 	}
 
 	paper, err := h.paperService.CreatePaper(&req, userID)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		logger.Error("Failed to create paper", "error", err, "user_id", userID)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusCreated, paper)
+	h.sendPaper(w, r, http.StatusCreated, paper)
 }
 
+// GetPaper handles getting a single paper
 func (h *PaperHandler) GetPaper(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	paperID, err := ExtractIDFromPath(r.URL.Path, "papers")
+	paper, err := h.paperService.GetPaper(paperID)
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid paper ID")
+		h.SendError(w, err)
 		return
 	}
 
-	paper, err := h.paperService.GetPaper(paperID)
+	h.sendPaper(w, r, http.StatusOK, paper)
+}
+
+// ListPapers handles listing papers
+func (h *PaperHandler) ListPapers(w http.ResponseWriter, r *http.Request) {
+	page, limit := h.ParsePagination(r)
+
+	papers, err := h.paperService.ListPapers(page, limit)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		logger.Error("Failed to list papers", "error", err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, paper)
+	h.sendPapers(w, r, papers)
 }
 
-func (h *PaperHandler) ListPapers(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+// UpdatePaper handles paper updates
+func (h *PaperHandler) UpdatePaper(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page := 1
-	limit := 10
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	var req service.UpdatePaperRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	paper, err := h.paperService.UpdatePaper(paperID, &req, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	papers, err := h.paperService.ListPapers(page, limit)
+	h.sendPaper(w, r, http.StatusOK, paper)
+}
+
+// DeletePaper handles paper deletion
+func (h *PaperHandler) DeletePaper(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
+		return
+	}
+
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	if err := h.paperService.DeletePaper(paperID, userID); err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, papers)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *PaperHandler) UpdatePaper(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		SendMethodNotAllowedResponse(w)
+// GetMyPapers handles listing the authenticated user's papers
+func (h *PaperHandler) GetMyPapers(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	page, limit := h.ParsePagination(r)
+
+	papers, err := h.paperService.GetUserPapers(userID, page, limit)
+	if err != nil {
+		logger.Error("Failed to list user papers", "error", err, "user_id", userID)
+		h.SendError(w, err)
 		return
 	}
 
+	h.sendPapers(w, r, papers)
+}
+
+// UploadFile handles uploading a paper's manuscript as multipart/form-data in the "file" field.
+// The file is streamed to storage without being buffered in memory.
+func (h *PaperHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
-	paperID, err := ExtractIDFromPath(r.URL.Path, "papers")
+	paperID, err := PathUint(r, "id")
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid paper ID")
+		h.SendError(w, err)
 		return
 	}
 
-	var req service.UpdatePaperRequest
-	if err := DecodeJSONRequest(r, &req); err != nil {
-		SendValidationErrorResponse(w, err.Error())
+	mr, err := r.MultipartReader()
+	if err != nil {
+		h.SendError(w, errors.BadRequest("Request must be multipart/form-data"))
 		return
 	}
 
-	paper, err := h.paperService.UpdatePaper(paperID, &req, userID)
+	var part *multipart.Part
+	for {
+		part, err = mr.NextPart()
+		if err == io.EOF {
+			h.SendError(w, errors.New(errors.ErrMissingField, "Missing file field: file"))
+			return
+		}
+		if err != nil {
+			h.SendError(w, errors.BadRequest("Invalid multipart body"))
+			return
+		}
+		if part.FormName() == "file" {
+			break
+		}
+		part.Close()
+	}
+	defer part.Close()
+
+	paper, err := h.paperService.UploadFile(r.Context(), paperID, userID, &service.UploadFileRequest{
+		FileName: part.FileName(),
+		Content:  part,
+	})
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		logger.Error("Failed to upload paper file", "error", err, "paper_id", paperID, "user_id", userID)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, paper)
+	logger.Info("Paper file uploaded", "paper_id", paper.ID, "cid", paper.IPFSHash, "size", paper.FileSize)
+	h.sendPaper(w, r, http.StatusOK, paper)
 }
 
-func (h *PaperHandler) DeletePaper(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		SendMethodNotAllowedResponse(w)
+// DownloadFile handles streaming a paper's manuscript. Range and conditional
+// requests are supported; the CID doubles as a strong ETag.
+func (h *PaperHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	userID, err := GetUserIDFromContext(r)
+	paper, content, err := h.paperService.OpenFile(r.Context(), paperID)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
+	defer content.Close()
 
-	paperID, err := ExtractIDFromPath(r.URL.Path, "papers")
+	w.Header().Set("Content-Type", paper.FileType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": paper.FileName}))
+	w.Header().Set("ETag", `"`+paper.IPFSHash+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
+	http.ServeContent(w, r, paper.FileName, paper.UpdatedAt, content)
+}
+
+// SetReviewPolicy handles an editor overriding the review policy of a paper
+func (h *PaperHandler) SetReviewPolicy(w http.ResponseWriter, r *http.Request) {
+	editorID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid paper ID")
+		h.SendError(w, err)
 		return
 	}
 
-	if err := h.paperService.DeletePaper(paperID, userID); err != nil {
-		SendInternalServerErrorResponse(w, err)
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusNoContent, nil)
-}
+	var req service.SetReviewPolicyRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
 
-func (h *PaperHandler) GetMyPapers(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+	// Validate request
+	validator := validation.NewValidator()
+	if req.Policy != "" {
+		validator.OneOf("policy", req.Policy, models.ReviewPolicies)
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
 		return
 	}
 
+	paper, err := h.paperService.SetReviewPolicy(paperID, req.Policy, editorID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	logger.Info("Paper review policy changed", "paper_id", paper.ID, "policy", req.Policy, "editor_id", editorID)
+	h.sendPaper(w, r, http.StatusOK, paper)
+}
+
+// SubmitPaper handles submitting a draft or revised paper for review
+func (h *PaperHandler) SubmitPaper(w http.ResponseWriter, r *http.Request) {
+	h.handleTransition(w, r, "submitted", h.paperService.SubmitForReview)
+}
+
+// WithdrawPaper handles withdrawing a paper from review back to draft
+func (h *PaperHandler) WithdrawPaper(w http.ResponseWriter, r *http.Request) {
+	h.handleTransition(w, r, "withdrawn", h.paperService.WithdrawPaper)
+}
+
+// PublishPaper handles publishing an accepted paper
+func (h *PaperHandler) PublishPaper(w http.ResponseWriter, r *http.Request) {
+	h.handleTransition(w, r, "published", h.paperService.PublishPaper)
+}
+
+// handleTransition runs a paper lifecycle transition for the paper in the path on behalf of the caller
+func (h *PaperHandler) handleTransition(w http.ResponseWriter, r *http.Request, verb string, transition func(paperID, userID uint) (*models.Paper, error)) {
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page := 1
-	limit := 10
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	paper, err := transition(paperID, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	logger.Info("Paper "+verb, "paper_id", paper.ID, "status", paper.Status, "user_id", userID)
+	h.sendPaper(w, r, http.StatusOK, paper)
+}
+
+// sendPaper responds with paper as the caller may see it under its review policy
+func (h *PaperHandler) sendPaper(w http.ResponseWriter, r *http.Request, status int, paper *models.Paper) {
+	view, err := h.policies.PaperView(paper, viewerOf(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	papers, err := h.paperService.GetUserPapers(userID, page, limit)
+	h.SendResponse(w, status, view)
+}
+
+// sendPapers responds with papers as the caller may see them under their review policies
+func (h *PaperHandler) sendPapers(w http.ResponseWriter, r *http.Request, papers []models.Paper) {
+	views, err := h.policies.PaperViews(papers, viewerOf(r))
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, papers)
+	h.SendResponse(w, http.StatusOK, views)
 }
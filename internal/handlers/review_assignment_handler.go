@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+)
+
+// AssignReviewer handles an editor inviting a reviewer to a paper
+func (h *ReviewHandler) AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	editorID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req service.AssignReviewerRequest
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	if req.ReviewerID == 0 {
+		validator.AddError("reviewer_id", "is required")
+	}
+	if req.DueAt.IsZero() {
+		validator.AddError("due_at", "is required")
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	assignment, err := h.reviewService.AssignReviewer(paperID, &req, editorID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignment(w, r, http.StatusCreated, assignment)
+}
+
+// GetPaperAssignments handles listing the reviewers invited to a paper
+func (h *ReviewHandler) GetPaperAssignments(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignments, err := h.reviewService.GetPaperAssignments(paperID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignments(w, r, assignments)
+}
+
+// GetReviewerSuggestions handles ranking reviewers to invite to a paper,
+// optionally limited by ?limit= (default 10)
+func (h *ReviewHandler) GetReviewerSuggestions(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	_, limit := h.ParsePagination(r)
+
+	suggestions, err := h.reviewService.SuggestReviewers(paperID, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.SendResponse(w, http.StatusOK, suggestions)
+}
+
+// GetMyAssignments handles listing the authenticated reviewer's assignments,
+// optionally filtered by ?status=
+func (h *ReviewHandler) GetMyAssignments(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		validator := validation.NewValidator()
+		validator.OneOf("status", status, models.AssignmentStatuses)
+		if err := validator.Validate(); err != nil {
+			h.SendError(w, errors.Validation("Validation failed", err))
+			return
+		}
+	}
+
+	page, limit := h.ParsePagination(r)
+
+	assignments, err := h.reviewService.GetReviewerAssignments(userID, status, page, limit)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignments(w, r, assignments)
+}
+
+// AcceptAssignment handles a reviewer accepting an invitation
+func (h *ReviewHandler) AcceptAssignment(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignmentID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignment, err := h.reviewService.AcceptAssignment(assignmentID, userID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignment(w, r, http.StatusOK, assignment)
+}
+
+// DeclineAssignment handles a reviewer declining an invitation or withdrawing
+// from an accepted assignment; the body with a reason is optional
+func (h *ReviewHandler) DeclineAssignment(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignmentID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	var req service.DeclineAssignmentRequest
+	if r.ContentLength != 0 {
+		if err := h.DecodeJSON(r, &req); err != nil {
+			h.SendError(w, err)
+			return
+		}
+	}
+
+	// Validate request
+	validator := validation.NewValidator()
+	validator.MaxLength("reason", req.Reason, 1000)
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
+		return
+	}
+
+	assignment, err := h.reviewService.DeclineAssignment(assignmentID, userID, req.Reason)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignment(w, r, http.StatusOK, assignment)
+}
+
+// CancelAssignment handles an editor withdrawing an invitation
+func (h *ReviewHandler) CancelAssignment(w http.ResponseWriter, r *http.Request) {
+	editorID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignmentID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	assignment, err := h.reviewService.CancelAssignment(assignmentID, editorID)
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	h.sendAssignment(w, r, http.StatusOK, assignment)
+}
@@ -2,257 +2,286 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
+	"github.com/nshmdayo/nft-platform-sample/internal/decision"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
 )
 
+// ReviewHandler handles review related requests
 type ReviewHandler struct {
-	reviewService *service.ReviewService
+	BaseHandler
+	reviewService   *service.ReviewService
+	decisionService *service.DecisionService
+	policies        *service.ReviewPolicies
 }
 
-func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(reviewService *service.ReviewService, decisionService *service.DecisionService, policies *service.ReviewPolicies) *ReviewHandler {
 	return &ReviewHandler{
-		reviewService: reviewService,
+		reviewService:   reviewService,
+		decisionService: decisionService,
+		policies:        policies,
 	}
 }
 
+// CreateReview handles review creation
 func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		SendMethodNotAllowedResponse(w)
-		return
-	}
-
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
 	var req service.CreateReviewRequest
-	if err := DecodeJSONRequest(r, &req); err != nil {
-		SendValidationErrorResponse(w, err.Error())
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	// Validate required fields
-	if err := ValidateRequiredFields(map[string]interface{}{
-		"comment":        req.Comment,
-		"recommendation": req.Recommendation,
-	}); err != nil {
-		SendValidationErrorResponse(w, err.Error())
-		return
+	// Validate request
+	validator := validation.NewValidator()
+	validator.Required("comment", req.Comment)
+	validator.Required("recommendation", req.Recommendation)
+	if len(req.Criteria) == 0 {
+		validator.AddError("criteria", "is required")
+	}
+	if req.Confidence != 0 {
+		validator.Range("confidence", req.Confidence, decision.MinConfidence, decision.MaxConfidence)
 	}
 
-	// Validate score range
-	if req.Score < 1 || req.Score > 10 {
-		SendValidationErrorResponse(w, "Score must be between 1 and 10")
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
 		return
 	}
 
 	// Check eligibility
 	if err := h.reviewService.CheckReviewEligibility(req.PaperID, userID); err != nil {
-		SendErrorResponse(w, http.StatusForbidden, err.Error())
+		h.SendError(w, err)
 		return
 	}
 
 	review, err := h.reviewService.CreateReview(&req, userID)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		logger.Error("Failed to create review", "error", err, "user_id", userID, "paper_id", req.PaperID)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusCreated, review)
+	h.sendReview(w, r, http.StatusCreated, review)
 }
 
+// GetReview handles getting a single review
 func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+	reviewID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	reviewID, err := ExtractIDFromPath(r.URL.Path, "reviews")
+	review, err := h.reviewService.GetReview(reviewID)
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid review ID")
+		h.SendError(w, err)
 		return
 	}
 
-	review, err := h.reviewService.GetReview(reviewID)
+	h.sendReview(w, r, http.StatusOK, review)
+}
+
+// GetPaperRubric handles getting the rubric reviews of a paper are filled in on
+func (h *ReviewHandler) GetPaperRubric(w http.ResponseWriter, r *http.Request) {
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
+		return
+	}
+
+	paperRubric, err := h.reviewService.GetPaperRubric(paperID)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, review)
+	h.SendResponse(w, http.StatusOK, paperRubric)
 }
 
+// GetPaperReviews handles listing the reviews of a paper together with its score
 func (h *ReviewHandler) GetPaperReviews(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+	paperID, err := PathUint(r, "id")
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	paperID, err := ExtractIDFromPath(r.URL.Path, "papers")
+	reviews, err := h.reviewService.GetPaperReviews(paperID)
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid paper ID")
+		h.SendError(w, err)
 		return
 	}
 
-	reviews, err := h.reviewService.GetPaperReviews(paperID)
+	views, err := h.policies.ReviewViews(reviews, viewerOf(r))
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
 	score, err := h.reviewService.CalculatePaperScore(paperID)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
 	response := map[string]interface{}{
-		"reviews":     reviews,
+		"reviews":     views,
 		"paper_score": score,
 	}
 
-	SendJSONResponse(w, http.StatusOK, response)
+	h.SendResponse(w, http.StatusOK, response)
 }
 
+// UpdateReview handles review updates
 func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		SendMethodNotAllowedResponse(w)
-		return
-	}
-
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
-	reviewID, err := ExtractIDFromPath(r.URL.Path, "reviews")
+	reviewID, err := PathUint(r, "id")
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid review ID")
+		h.SendError(w, err)
 		return
 	}
 
 	var req service.CreateReviewRequest
-	if err := DecodeJSONRequest(r, &req); err != nil {
-		SendValidationErrorResponse(w, err.Error())
+	if err := h.DecodeJSON(r, &req); err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	// Validate score range if provided
-	if req.Score < 1 || req.Score > 10 {
-		SendValidationErrorResponse(w, "Score must be between 1 and 10")
+	// Validate request
+	validator := validation.NewValidator()
+	if len(req.Criteria) == 0 {
+		validator.AddError("criteria", "is required")
+	}
+	if req.Confidence != 0 {
+		validator.Range("confidence", req.Confidence, decision.MinConfidence, decision.MaxConfidence)
+	}
+
+	if err := validator.Validate(); err != nil {
+		h.SendError(w, errors.Validation("Validation failed", err))
 		return
 	}
 
 	review, err := h.reviewService.UpdateReview(reviewID, &req, userID)
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, review)
+	h.sendReview(w, r, http.StatusOK, review)
 }
 
+// DeleteReview handles review deletion
 func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		SendMethodNotAllowedResponse(w)
-		return
-	}
-
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
-	reviewID, err := ExtractIDFromPath(r.URL.Path, "reviews")
+	reviewID, err := PathUint(r, "id")
 	if err != nil {
-		SendValidationErrorResponse(w, "Invalid review ID")
+		h.SendError(w, err)
 		return
 	}
 
 	if err := h.reviewService.DeleteReview(reviewID, userID); err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusNoContent, nil)
+	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetMyReviews handles listing the authenticated user's reviews
 func (h *ReviewHandler) GetMyReviews(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
-		return
-	}
-
 	userID, err := GetUserIDFromContext(r)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		h.SendError(w, err)
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page := 1
-	limit := 10
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
+	page, limit := h.ParsePagination(r)
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	reviews, err := h.reviewService.GetReviewerReviews(userID, page, limit)
+	if err != nil {
+		logger.Error("Failed to list user reviews", "error", err, "user_id", userID)
+		h.SendError(w, err)
+		return
 	}
 
-	reviews, err := h.reviewService.GetReviewerReviews(userID, page, limit)
+	views, err := h.policies.ReviewViews(reviews, viewerOf(r))
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, reviews)
+	h.SendResponse(w, http.StatusOK, views)
 }
 
+// GetPendingReviews handles listing the accepted assignments awaiting the authenticated user's review
 func (h *ReviewHandler) GetPendingReviews(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		SendMethodNotAllowedResponse(w)
+	userID, err := GetUserIDFromContext(r)
+	if err != nil {
+		h.SendError(w, err)
 		return
 	}
 
-	userID, err := GetUserIDFromContext(r)
+	page, limit := h.ParsePagination(r)
+
+	assignments, err := h.reviewService.GetPendingReviews(userID, page, limit)
 	if err != nil {
-		SendUnauthorizedResponse(w)
+		logger.Error("Failed to list pending reviews", "error", err, "user_id", userID)
+		h.SendError(w, err)
 		return
 	}
 
-	// Parse pagination parameters with defaults
-	page := 1
-	limit := 10
+	h.sendAssignments(w, r, assignments)
+}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+// sendReview responds with review as the caller may see it under its paper's review policy
+func (h *ReviewHandler) sendReview(w http.ResponseWriter, r *http.Request, status int, review *models.Review) {
+	view, err := h.policies.ReviewView(review, viewerOf(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	h.SendResponse(w, status, view)
+}
+
+// sendAssignment responds with assignment, hiding the authors of its paper under double-blind review
+func (h *ReviewHandler) sendAssignment(w http.ResponseWriter, r *http.Request, status int, assignment *models.ReviewAssignment) {
+	view, err := h.policies.AssignmentView(assignment, viewerOf(r))
+	if err != nil {
+		h.SendError(w, err)
+		return
 	}
 
-	papers, err := h.reviewService.GetPendingReviews(userID, page, limit)
+	h.SendResponse(w, status, view)
+}
+
+// sendAssignments responds with assignments, hiding the authors of their papers under double-blind review
+func (h *ReviewHandler) sendAssignments(w http.ResponseWriter, r *http.Request, assignments []models.ReviewAssignment) {
+	views, err := h.policies.AssignmentViews(assignments, viewerOf(r))
 	if err != nil {
-		SendInternalServerErrorResponse(w, err)
+		h.SendError(w, err)
 		return
 	}
 
-	SendJSONResponse(w, http.StatusOK, papers)
+	h.SendResponse(w, http.StatusOK, views)
 }
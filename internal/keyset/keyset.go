@@ -0,0 +1,306 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported JWS algorithms
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const (
+	keyExt        = ".pem"
+	createdHeader = "Created"
+	rsaKeyBits    = 2048
+)
+
+// Key is a signing key identified by its kid
+type Key struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	private   crypto.Signer
+}
+
+// PrivateKey returns the key used to sign tokens
+func (k *Key) PrivateKey() crypto.Signer {
+	return k.private
+}
+
+// PublicKey returns the key used to verify tokens
+func (k *Key) PublicKey() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// KeySet holds the signing keys kept in a directory, one PEM-encoded PKCS#8
+// private key per file named <kid>.pem. The newest key signs new tokens; older
+// keys remain available for verification until they are pruned.
+type KeySet struct {
+	dir       string
+	algorithm string // Algorithm of generated keys
+
+	mu   sync.RWMutex
+	keys []*Key // Oldest first
+}
+
+// New loads the keys in dir, generating a first key for algorithm if there are none
+func New(dir, algorithm string) (*KeySet, error) {
+	if algorithm != AlgRS256 && algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{dir: dir, algorithm: algorithm}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	if s.Signing() == nil {
+		if _, err := s.Rotate(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Reload rereads the directory, picking up keys added or removed by other instances
+func (s *KeySet) Reload() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyExt {
+			continue
+		}
+		key, err := loadKey(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("load key %s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// Signing returns the key new tokens are signed with, or nil if the set is empty
+func (s *KeySet) Signing() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.keys) == 0 {
+		return nil
+	}
+	return s.keys[len(s.keys)-1]
+}
+
+// Lookup returns the key with the given kid
+func (s *KeySet) Lookup(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns every key in the set, oldest first
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*Key(nil), s.keys...)
+}
+
+// Rotate generates a new key, which becomes the signing key
+func (s *KeySet) Rotate(now time.Time) (*Key, error) {
+	private, err := generateKey(s.algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	key := &Key{
+		ID:        now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		Algorithm: s.algorithm,
+		CreatedAt: now.UTC(),
+		private:   private,
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdHeader: key.CreatedAt.Format(time.RFC3339Nano)},
+		Bytes:   der,
+	})
+
+	// Write under a temporary name so other instances never load a partial file
+	path := filepath.Join(s.dir, key.ID+keyExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	sortKeys(s.keys)
+	s.mu.Unlock()
+	return key, nil
+}
+
+// Prune deletes keys that were replaced more than retention ago. Retention must
+// cover the lifetime of the tokens signed with a key, or they stop verifying.
+func (s *KeySet) Prune(now time.Time, retention time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]*Key, 0, len(s.keys))
+	pruned := 0
+	for i, key := range s.keys {
+		// A key is retired when its successor is created
+		if i < len(s.keys)-1 && now.Sub(s.keys[i+1].CreatedAt) > retention {
+			err := os.Remove(filepath.Join(s.dir, key.ID+keyExt))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				s.keys = append(kept, s.keys[i:]...)
+				return pruned, err
+			}
+			pruned++
+			continue
+		}
+		kept = append(kept, key)
+	}
+	s.keys = kept
+	return pruned, nil
+}
+
+// RotateIfDue reloads the set, rotates if the signing key is older than
+// interval and prunes retired keys. A zero interval disables rotation.
+func (s *KeySet) RotateIfDue(now time.Time, interval, retention time.Duration) (bool, error) {
+	if err := s.Reload(); err != nil {
+		return false, err
+	}
+
+	rotated := false
+	if signing := s.Signing(); signing == nil || (interval > 0 && now.Sub(signing.CreatedAt) >= interval) {
+		if _, err := s.Rotate(now); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	if _, err := s.Prune(now, retention); err != nil {
+		return rotated, err
+	}
+	return rotated, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: strings.TrimSuffix(filepath.Base(path), keyExt)}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < rsaKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", rsaKeyBits)
+		}
+		key.Algorithm, key.private = AlgRS256, private
+	case ed25519.PrivateKey:
+		key.Algorithm, key.private = AlgEdDSA, private
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	// Keys created elsewhere carry no creation header; fall back to the file time
+	if created, ok := block.Headers[createdHeader]; ok {
+		key.CreatedAt, err = time.Parse(time.RFC3339Nano, created)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header: %w", createdHeader, err)
+		}
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		key.CreatedAt = info.ModTime().UTC()
+	}
+	return key, nil
+}
+
+func generateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// sortKeys orders keys by creation time, breaking ties by kid
+func sortKeys(keys []*Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+}
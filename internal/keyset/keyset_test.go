@@ -0,0 +1,103 @@
+package keyset
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGeneratesFirstKey(t *testing.T) {
+	dir := t.TempDir()
+
+	keys, err := New(dir, AlgEdDSA)
+	require.NoError(t, err)
+	signing := keys.Signing()
+	require.NotNil(t, signing)
+	assert.Equal(t, AlgEdDSA, signing.Algorithm)
+
+	// The key is persisted and loaded again on restart
+	reloaded, err := New(dir, AlgEdDSA)
+	require.NoError(t, err)
+	require.Len(t, reloaded.Keys(), 1)
+	assert.Equal(t, signing.ID, reloaded.Signing().ID)
+	assert.True(t, signing.CreatedAt.Equal(reloaded.Signing().CreatedAt))
+
+	_, err = New(t.TempDir(), "HS256")
+	assert.Error(t, err)
+}
+
+func TestRotateAndPrune(t *testing.T) {
+	keys, err := New(t.TempDir(), AlgEdDSA)
+	require.NoError(t, err)
+	first := keys.Signing()
+	start := first.CreatedAt
+
+	// Not due yet
+	rotated, err := keys.RotateIfDue(start.Add(time.Hour), 24*time.Hour, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+
+	rotated, err = keys.RotateIfDue(start.Add(24*time.Hour), 24*time.Hour, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	second := keys.Signing()
+	assert.NotEqual(t, first.ID, second.ID)
+
+	// The replaced key keeps verifying until the retention has passed
+	_, ok := keys.Lookup(first.ID)
+	assert.True(t, ok)
+	assert.Len(t, keys.JWKS().Keys, 2)
+
+	pruned, err := keys.Prune(second.CreatedAt.Add(2*time.Hour), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	_, ok = keys.Lookup(first.ID)
+	assert.False(t, ok)
+	assert.Equal(t, second.ID, keys.Signing().ID)
+
+	require.NoError(t, keys.Reload())
+	assert.Len(t, keys.Keys(), 1)
+
+	// A zero interval disables rotation
+	rotated, err = keys.RotateIfDue(second.CreatedAt.Add(1000*time.Hour), 0, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+}
+
+func TestLoadExternalRSAKey(t *testing.T) {
+	dir := t.TempDir()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy.pem"), data, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0o600))
+
+	// Existing keys are used even if new keys would use another algorithm
+	keys, err := New(dir, AlgEdDSA)
+	require.NoError(t, err)
+	require.Len(t, keys.Keys(), 1)
+	assert.Equal(t, "legacy", keys.Signing().ID)
+	assert.Equal(t, AlgRS256, keys.Signing().Algorithm)
+
+	jwk := keys.JWKS().Keys[0]
+	assert.Equal(t, "RSA", jwk.KeyType)
+	assert.Equal(t, "legacy", jwk.KeyID)
+	assert.Equal(t, "AQAB", jwk.E)
+	assert.NotEmpty(t, jwk.N)
+
+	// Weak keys are refused
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	data = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weak)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "weak.pem"), data, 0o600))
+	assert.Error(t, keys.Reload())
+}
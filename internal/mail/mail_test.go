@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRoundTrip(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir(), "Papers <no-reply@papers.example.com>")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, outbox.Send(ctx, &Message{To: "alice@example.com", Subject: "First", Body: "Line one\nLine two"}))
+	require.NoError(t, outbox.Send(ctx, &Message{To: "Bob <bob@example.com>", Subject: "Überprüfung", Body: "Hallo\n"}))
+
+	messages, err := outbox.Messages()
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, &Message{To: "alice@example.com", Subject: "First", Body: "Line one\nLine two\n"}, messages[0])
+	assert.Equal(t, "bob@example.com", messages[1].To)
+	assert.Equal(t, "Überprüfung", messages[1].Subject)
+}
+
+func TestHeaderInjectionRejected(t *testing.T) {
+	outbox, err := NewOutbox(t.TempDir(), "no-reply@papers.example.com")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.Error(t, outbox.Send(ctx, &Message{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "Hi"}))
+	assert.Error(t, outbox.Send(ctx, &Message{To: "alice@example.com", Subject: "Hi\r\nBcc: eve@example.com"}))
+
+	messages, err := outbox.Messages()
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(config.MailConfig{Backend: "outbox", From: "not an address"})
+	assert.Error(t, err)
+	_, err = New(config.MailConfig{Backend: "pigeon", From: "no-reply@example.com"})
+	assert.Error(t, err)
+
+	mailer, err := New(config.MailConfig{Backend: "smtp", From: "no-reply@example.com", SMTPHost: "localhost", SMTPPort: 25})
+	require.NoError(t, err)
+	assert.IsType(t, &SMTPMailer{}, mailer)
+}
+
+// fakeSMTP accepts one plain SMTP session and returns the envelope and data it received
+func fakeSMTP(t *testing.T) (string, <-chan []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+				lines = append(lines, line)
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 Go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 Queued")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+
+	mailer := NewSMTPMailer(config.MailConfig{From: "Papers <no-reply@papers.example.com>", SMTPHost: host, SMTPPort: portNum})
+	err := mailer.Send(context.Background(), &Message{To: "alice@example.com", Subject: "Verify", Body: "Click the link"})
+	require.NoError(t, err)
+
+	lines := <-received
+	assert.Equal(t, "MAIL FROM:<no-reply@papers.example.com> BODY=8BITMIME", lines[0])
+	assert.Equal(t, "RCPT TO:<alice@example.com>", lines[1])
+	assert.Contains(t, lines, "Subject: Verify")
+	assert.Contains(t, lines, "To: <alice@example.com>")
+	assert.Equal(t, "Click the link", lines[len(lines)-1])
+}
@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const messageExt = ".eml"
+
+// Outbox is a Mailer for local development and tests. It writes each message
+// to its own .eml file in a directory, or to stdout if no directory is set.
+type Outbox struct {
+	dir  string
+	from string
+
+	mu  sync.Mutex
+	out io.Writer // Used when dir is empty
+}
+
+// NewOutbox creates an outbox writing to dir, or to stdout if dir is empty
+func NewOutbox(dir, from string) (*Outbox, error) {
+	if dir == "" {
+		return &Outbox{from: from, out: os.Stdout}, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Outbox{dir: dir, from: from}, nil
+}
+
+// Send writes msg to the outbox
+func (o *Outbox) Send(ctx context.Context, msg *Message) error {
+	now := time.Now().UTC()
+	data, err := format(o.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if o.dir == "" {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		_, err := fmt.Fprintf(o.out, "----- mail -----\n%s----- end mail -----\n", strings.ReplaceAll(string(data), "\r\n", "\n"))
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	// Names sort in the order the messages were sent
+	name := now.Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(suffix) + messageExt
+
+	// Write under a temporary name so readers never see a partial message
+	path := filepath.Join(o.dir, name)
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Messages returns the messages in the outbox directory, oldest first
+func (o *Outbox) Messages() ([]*Message, error) {
+	if o.dir == "" {
+		return nil, errors.New("outbox writes to stdout")
+	}
+
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var messages []*Message
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != messageExt {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		msg, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
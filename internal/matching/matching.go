@@ -0,0 +1,210 @@
+// Package matching ranks candidate reviewers for a paper by how well their
+// expertise covers the paper's topics, excluding conflicts of interest and
+// favouring reviewers with fewer open assignments.
+package matching
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// interestWeight is how much a topic counts when the reviewer declared it
+	// as an interest
+	interestWeight = 1.0
+	// publicationWeight is how much a topic counts when it only appears among
+	// the keywords or categories of the reviewer's own papers
+	publicationWeight = 0.75
+	// loadPenalty scales a reviewer's score down per open assignment
+	loadPenalty = 0.25
+)
+
+// Paper is the paper reviewers are sought for
+type Paper struct {
+	OwnerID          uint
+	OwnerInstitution string
+	Authors          []string
+	Keywords         []string
+	Category         string
+}
+
+// Publication is a paper of a candidate reviewer
+type Publication struct {
+	Authors  []string
+	Keywords []string
+	Category string
+}
+
+// Candidate is a user who could review the paper
+type Candidate struct {
+	ID              uint
+	Name            string
+	Institution     string
+	Interests       []string
+	Publications    []Publication
+	OpenAssignments int  // Invited or accepted assignments on any paper
+	Assigned        bool // Already invited to, reviewing or done reviewing this paper
+}
+
+// Suggestion is a ranked candidate
+type Suggestion struct {
+	ReviewerID      uint     `json:"reviewer_id"`
+	Name            string   `json:"name"`
+	Institution     string   `json:"institution"`
+	Score           float64  `json:"score"`     // Relevance in [0, 1], reduced by load
+	Relevance       float64  `json:"relevance"` // Share of the paper's topics the reviewer covers, in [0, 1]
+	OpenAssignments int      `json:"open_assignments"`
+	Reasons         []string `json:"reasons"`
+}
+
+// Conflict is a candidate excluded from the suggestions
+type Conflict struct {
+	ReviewerID uint   `json:"reviewer_id"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`
+}
+
+// Rank scores every candidate against paper and returns up to limit
+// suggestions, best first, along with the candidates excluded for conflicts of
+// interest. Candidates sharing none of the paper's topics are left out.
+func Rank(paper Paper, candidates []Candidate, limit int) ([]Suggestion, []Conflict) {
+	topics := paperTopics(paper)
+	authors := normalizeSet(paper.Authors)
+
+	suggestions := make([]Suggestion, 0, len(candidates))
+	conflicts := make([]Conflict, 0)
+	for _, candidate := range candidates {
+		if reason := conflictOf(paper, authors, candidate); reason != "" {
+			conflicts = append(conflicts, Conflict{ReviewerID: candidate.ID, Name: candidate.Name, Reason: reason})
+			continue
+		}
+
+		suggestion, ok := score(topics, candidate)
+		if ok {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.OpenAssignments != b.OpenAssignments {
+			return a.OpenAssignments < b.OpenAssignments
+		}
+		return a.ReviewerID < b.ReviewerID
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, conflicts
+}
+
+// conflictOf returns why candidate must not review paper, or "" if they may
+func conflictOf(paper Paper, authors map[string]bool, candidate Candidate) string {
+	if candidate.ID == paper.OwnerID {
+		return "owner of the paper"
+	}
+	if candidate.Assigned {
+		return "already assigned to the paper"
+	}
+	if authors[normalize(candidate.Name)] {
+		return "author of the paper"
+	}
+	if institution := normalize(candidate.Institution); institution != "" && institution == normalize(paper.OwnerInstitution) {
+		return "same institution as the author: " + candidate.Institution
+	}
+	for _, publication := range candidate.Publications {
+		for _, coauthor := range publication.Authors {
+			if authors[normalize(coauthor)] && normalize(coauthor) != normalize(candidate.Name) {
+				return "co-authored a paper with " + strings.TrimSpace(coauthor)
+			}
+		}
+	}
+	return ""
+}
+
+// score rates how well candidate covers topics, and reports false if not at all
+func score(topics []string, candidate Candidate) (Suggestion, bool) {
+	interests := normalizeSet(candidate.Interests)
+	published := make(map[string]bool)
+	for _, publication := range candidate.Publications {
+		for _, keyword := range publication.Keywords {
+			published[normalize(keyword)] = true
+		}
+		published[normalize(publication.Category)] = true
+	}
+
+	var total float64
+	var interestMatches, publicationMatches []string
+	for _, topic := range topics {
+		switch {
+		case interests[topic]:
+			total += interestWeight
+			interestMatches = append(interestMatches, topic)
+		case published[topic]:
+			total += publicationWeight
+			publicationMatches = append(publicationMatches, topic)
+		}
+	}
+	if total == 0 {
+		return Suggestion{}, false
+	}
+
+	relevance := total / float64(len(topics))
+	reasons := make([]string, 0, 3)
+	if len(interestMatches) > 0 {
+		reasons = append(reasons, "interested in "+strings.Join(interestMatches, ", "))
+	}
+	if len(publicationMatches) > 0 {
+		reasons = append(reasons, "published on "+strings.Join(publicationMatches, ", "))
+	}
+	reasons = append(reasons, fmt.Sprintf("%d open assignments", candidate.OpenAssignments))
+
+	return Suggestion{
+		ReviewerID:      candidate.ID,
+		Name:            candidate.Name,
+		Institution:     candidate.Institution,
+		Score:           round(relevance / (1 + loadPenalty*float64(candidate.OpenAssignments))),
+		Relevance:       round(relevance),
+		OpenAssignments: candidate.OpenAssignments,
+		Reasons:         reasons,
+	}, true
+}
+
+// paperTopics returns the distinct, normalized keywords and category of paper
+func paperTopics(paper Paper) []string {
+	seen := make(map[string]bool)
+	topics := make([]string, 0, len(paper.Keywords)+1)
+	for _, topic := range slices.Concat(paper.Keywords, []string{paper.Category}) {
+		topic = normalize(topic)
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func normalizeSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value = normalize(value); value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// normalize makes names and topics compare regardless of case and spacing
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,60 @@
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRank(t *testing.T) {
+	paper := Paper{
+		OwnerID:          1,
+		OwnerInstitution: "Example University",
+		Authors:          []string{"Alice Author", "Bob Coauthor"},
+		Keywords:         []string{"Zero Knowledge", "rollups", "consensus"},
+		Category:         "cs",
+	}
+	candidates := []Candidate{
+		{ID: 1, Name: "Alice Author", Interests: []string{"rollups"}},
+		{ID: 2, Name: "Carol", Institution: "example  university", Interests: []string{"rollups"}},
+		{ID: 3, Name: "Dave", Publications: []Publication{{Authors: []string{"Dave", "bob coauthor"}, Keywords: []string{"rollups"}}}},
+		{ID: 4, Name: "Erin", Interests: []string{"zero knowledge", "rollups", "consensus"}, Publications: []Publication{{Category: "cs"}}},
+		{ID: 5, Name: "Frank", Interests: []string{"zero knowledge", "rollups", "consensus"}, Publications: []Publication{{Category: "cs"}}, OpenAssignments: 4},
+		{ID: 6, Name: "Grace", Publications: []Publication{{Authors: []string{"Grace"}, Keywords: []string{"Consensus"}}}},
+		{ID: 7, Name: "Heidi", Interests: []string{"biology"}},
+		{ID: 8, Name: "Ivan", Interests: []string{"rollups"}, Assigned: true},
+	}
+
+	suggestions, conflicts := Rank(paper, candidates, 0)
+
+	require.Len(t, conflicts, 4)
+	assert.Equal(t, Conflict{ReviewerID: 1, Name: "Alice Author", Reason: "owner of the paper"}, conflicts[0])
+	assert.Equal(t, "same institution as the author: example  university", conflicts[1].Reason)
+	assert.Equal(t, "co-authored a paper with bob coauthor", conflicts[2].Reason)
+	assert.Equal(t, "already assigned to the paper", conflicts[3].Reason)
+
+	// Heidi shares no topic; Frank covers as much as Erin but is busier
+	require.Len(t, suggestions, 3)
+	assert.Equal(t, uint(4), suggestions[0].ReviewerID)
+	assert.Equal(t, 0.938, suggestions[0].Score)
+	assert.Equal(t, []string{"interested in zero knowledge, rollups, consensus", "published on cs", "0 open assignments"}, suggestions[0].Reasons)
+	assert.Equal(t, uint(5), suggestions[1].ReviewerID)
+	assert.Equal(t, 0.938, suggestions[1].Relevance)
+	assert.Equal(t, 0.469, suggestions[1].Score)
+	assert.Equal(t, uint(6), suggestions[2].ReviewerID)
+	assert.Equal(t, 0.188, suggestions[2].Score)
+
+	suggestions, _ = Rank(paper, candidates, 1)
+	assert.Len(t, suggestions, 1)
+}
+
+func TestRankAuthorByName(t *testing.T) {
+	paper := Paper{OwnerID: 1, Authors: []string{"Carol Reviewer"}, Keywords: []string{"rollups"}}
+	candidates := []Candidate{{ID: 2, Name: "carol  reviewer", Interests: []string{"rollups"}}}
+
+	suggestions, conflicts := Rank(paper, candidates, 10)
+	assert.Empty(t, suggestions)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "author of the paper", conflicts[0].Reason)
+}
@@ -3,10 +3,13 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
-	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/internal/apikey"
+	"github.com/nshmdayo/nft-platform-sample/internal/revocation"
 	"github.com/nshmdayo/nft-platform-sample/internal/utils"
 	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
 )
@@ -14,12 +17,20 @@ import (
 type contextKey string
 
 const (
-	UserIDKey contextKey = "userID"
-	EmailKey  contextKey = "email"
-	RoleKey   contextKey = "role"
+	UserIDKey    contextKey = "userID"
+	EmailKey     contextKey = "email"
+	RoleKey      contextKey = "role"
+	SessionIDKey contextKey = "sessionID"
+	MFASetupKey  contextKey = "mfaSetup"
+	APIKeyIDKey  contextKey = "apiKeyID"
+	ScopesKey    contextKey = "scopes"
+	ClientIPKey  contextKey = "clientIP"
 )
 
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+// AuthMiddleware authenticates requests by their bearer token, or by an
+// "ApiKey" authorization checked by apiKeys. Tokens found in revocations are
+// rejected; a nil store skips the check, and a nil verifier rejects API keys.
+func AuthMiddleware(tokens utils.JWTOptions, revocations *revocation.Store, apiKeys *apikey.Verifier) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -30,8 +41,12 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Extract token from "Bearer <token>"
+			// Extract token from "Bearer <token>" or "ApiKey <key>"
 			tokenParts := strings.Split(authHeader, " ")
+			if len(tokenParts) == 2 && tokenParts[0] == "ApiKey" && apiKeys != nil {
+				authenticateAPIKey(w, r, next, apiKeys, tokenParts[1])
+				return
+			}
 			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -40,7 +55,7 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 			}
 
 			token := tokenParts[1]
-			claims, err := utils.ValidateJWT(token, cfg.JWT.Secret)
+			claims, err := utils.ValidateJWT(token, tokens)
 			if err != nil {
 				logger.Error("Invalid JWT token", "error", err)
 				w.Header().Set("Content-Type", "application/json")
@@ -49,12 +64,123 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
+			if revocations != nil {
+				revoked, err := revocations.IsRevoked(claims.ID)
+				if err != nil {
+					logger.Error("Failed to check token revocation", "error", err)
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+					return
+				}
+				if revoked {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					json.NewEncoder(w).Encode(map[string]string{"error": "Token has been revoked"})
+					return
+				}
+			}
+
 			// Set user information in context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, EmailKey, claims.Email)
 			ctx = context.WithValue(ctx, RoleKey, claims.Role)
+			ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+			ctx = context.WithValue(ctx, MFASetupKey, claims.MFASetup)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// authenticateAPIKey serves a request authorized by an API key as its user
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKeys *apikey.Verifier, key string) {
+	principal, err := apiKeys.Verify(key)
+	if err != nil {
+		status, message := http.StatusUnauthorized, "Invalid API key"
+		switch {
+		case errors.Is(err, apikey.ErrExpired):
+			message = "API key has expired"
+		case errors.Is(err, apikey.ErrRevoked):
+			message = "API key has been revoked"
+		case errors.Is(err, apikey.ErrInvalid):
+		default:
+			logger.Error("Failed to verify API key", "error", err)
+			status, message = http.StatusInternalServerError, "Internal server error"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, EmailKey, principal.Email)
+	ctx = context.WithValue(ctx, RoleKey, principal.Role)
+	ctx = context.WithValue(ctx, APIKeyIDKey, principal.KeyID)
+	ctx = context.WithValue(ctx, ScopesKey, principal.Scopes)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// OptionalAuthMiddleware authenticates requests that carry a bearer token like
+// AuthMiddleware, and lets requests without an Authorization header through
+// anonymously.
+func OptionalAuthMiddleware(tokens utils.JWTOptions, revocations *revocation.Store, apiKeys *apikey.Verifier) func(http.Handler) http.Handler {
+	auth := AuthMiddleware(tokens, revocations, apiKeys)
+	return func(next http.Handler) http.Handler {
+		authenticated := auth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireMFASetupComplete rejects tokens issued to users who still have to set
+// up two-factor authentication required by the platform. It must run after
+// AuthMiddleware.
+func RequireMFASetupComplete() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pending, _ := r.Context().Value(MFASetupKey).(bool); pending {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Two-factor authentication must be set up first"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope limits requests authenticated by an API key to keys granted
+// scope; with an empty scope, the route cannot be used with API keys at all.
+// Requests authenticated otherwise pass. It must run after AuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value(APIKeyIDKey).(uint); !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scopes, _ := r.Context().Value(ScopesKey).([]string)
+			if scope != "" && slices.Contains(scopes, scope) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			message := "API keys cannot be used for this route"
+			if scope != "" {
+				message = "API key lacks the " + scope + " scope"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": message})
+		})
+	}
+}
@@ -57,17 +57,9 @@ func LoggerMiddleware() func(http.Handler) http.Handler {
 				"path", path,
 				"status", rw.statusCode,
 				"latency", latency,
-				"client_ip", getClientIP(r),
+				"client_ip", ClientIP(r),
 				"user_agent", r.UserAgent(),
 			)
 		})
 	}
 }
-
-func getClientIP(r *http.Request) string {
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
-	}
-	return r.RemoteAddr
-}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/dto"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// Permission is an action guarded by role
+type Permission string
+
+// Permissions checked by the router
+const (
+	PermReviewWrite    Permission = "reviews:write"   // Create, update and delete reviews
+	PermPaperDecide    Permission = "papers:decide"   // Publish papers, request revisions and assign reviewers
+	PermNFTMint        Permission = "nfts:mint"       // Mint papers and reviews as NFTs
+	PermUsersManage    Permission = "users:manage"    // List users, change roles, revoke sessions
+	PermSettingsManage Permission = "settings:manage" // Change platform-wide settings such as the 2FA requirement and review policies
+)
+
+// rolePermissions is the permission matrix. Permissions not listed for a role
+// are denied; every authenticated user may use routes without a permission.
+var rolePermissions = map[string][]Permission{
+	models.RoleResearcher: {},
+	models.RoleReviewer:   {PermReviewWrite},
+	models.RoleAdmin:      {PermReviewWrite, PermPaperDecide, PermNFTMint, PermUsersManage, PermSettingsManage},
+}
+
+// HasPermission reports whether role grants permission
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole allows requests by users with one of roles. It must run after AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(RoleKey).(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			forbidden(w, r, role)
+		})
+	}
+}
+
+// RequirePermission allows requests by users whose role grants permission. It must run after AuthMiddleware.
+func RequirePermission(permission Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(RoleKey).(string)
+			if !HasPermission(role, permission) {
+				forbidden(w, r, role)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forbidden responds with a FORBIDDEN error in the envelope handlers use
+func forbidden(w http.ResponseWriter, r *http.Request, role string) {
+	logger.Warn("Access denied", "path", r.URL.Path, "role", role, "user_id", r.Context().Value(UserIDKey))
+	appErr := errors.Forbidden("Insufficient permissions")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(appErr.StatusCode)
+	json.NewEncoder(w).Encode(dto.APIResponse{
+		Success: false,
+		Error: &dto.ErrorInfo{
+			Code:    string(appErr.Code),
+			Message: appErr.Message,
+		},
+		Meta: &dto.MetaInfo{
+			Timestamp: time.Now(),
+		},
+	})
+}
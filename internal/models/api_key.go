@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognize
+const APIKeyPrefix = "nftp_"
+
+// API key scopes
+const (
+	ScopePapersRead   = "papers:read"
+	ScopePapersWrite  = "papers:write"
+	ScopeReviewsRead  = "reviews:read"
+	ScopeReviewsWrite = "reviews:write"
+	ScopeNFTsRead     = "nfts:read"
+)
+
+// APIKeyScopes lists every API key scope
+var APIKeyScopes = []string{ScopePapersRead, ScopePapersWrite, ScopeReviewsRead, ScopeReviewsWrite, ScopeNFTsRead}
+
+// APIKey lets a machine client act for a user on the routes its scopes allow.
+// Only the SHA-256 of the key is stored.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"` // Start of the key, to tell keys apart
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     string     `json:"scopes" gorm:"not null"` // Space-separated
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`   // nil if the key never expires
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the scopes granted to the key
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
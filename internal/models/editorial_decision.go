@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// EditorialDecision is an editor's confirmed decision on a paper after a round
+// of reviews, along with the draft it was based on
+type EditorialDecision struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	PaperID     uint           `json:"paper_id" gorm:"index;not null"`
+	EditorID    uint           `json:"editor_id" gorm:"not null"`
+	Decision    string         `json:"decision" gorm:"not null"` // accept, minor_revision, major_revision or reject
+	Proposed    string         `json:"proposed"`                 // The drafted decision; differs if the editor overrode it
+	Score       float64        `json:"score"`
+	ScoreMethod string         `json:"score_method"`
+	Round       int            `json:"round"`     // Review round decided, counting from 0
+	Reviews     int            `json:"reviews"`   // Reviews of the round the decision is based on
+	Rationale   datatypes.JSON `json:"rationale"` // []string
+	Comment     string         `json:"comment"`
+	PaperStatus string         `json:"paper_status"` // Status the decision moved the paper to
+	CreatedAt   time.Time      `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Email token purposes
+const (
+	EmailTokenVerify        = "verify_email"
+	EmailTokenPasswordReset = "reset_password"
+)
+
+// EmailToken is a single-use token sent by email to prove control of the
+// address. Only the SHA-256 of the token is stored.
+type EmailToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when the token is used or superseded
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Login failure reasons
+const (
+	LoginFailureUnknownAccount  = "unknown_account"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInvalidMFACode  = "invalid_mfa_code"
+	LoginFailureThrottled       = "throttled" // Attempted while locked out or before the required wait
+)
+
+// ThrottleState counts the recent failures of a throttled subject, such as
+// logins to one account or from one address
+type ThrottleState struct {
+	Subject       string     `json:"subject" gorm:"primaryKey"` // e.g. login:account:<email>, login:ip:<address>
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LoginFailure is an audit log entry of a failed login
+type LoginFailure struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Email     string    `json:"email" gorm:"index"`   // As entered, lowercased
+	UserID    uint      `json:"user_id" gorm:"index"` // 0 if no account uses Email
+	IPAddress string    `json:"ip_address" gorm:"index"`
+	UserAgent string    `json:"user_agent"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -5,11 +5,73 @@ import (
 )
 
 type NFTMetadata struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	TokenID      uint      `json:"token_id" gorm:"uniqueIndex:idx_nft_type_token"`
+	Type         string    `json:"type" gorm:"uniqueIndex:idx_nft_type_token;uniqueIndex:idx_nft_type_reference"` // paper, review
+	ReferenceID  uint      `json:"reference_id" gorm:"uniqueIndex:idx_nft_type_reference"`                        // Paper ID or Review ID
+	MetadataURI  string    `json:"metadata_uri"`                                                                  // IPFS URI
+	ContractAddr string    `json:"contract_address"`                                                              // Contract the token was minted on
+	TxHash       string    `json:"tx_hash"`                                                                       // Transaction hash
+	BlockNumber  uint64    `json:"block_number"`                                                                  // Block the mint was included in
+	OwnerAddr    string    `json:"owner_address" gorm:"index"`                                                    // Wallet currently holding the token
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NFT types
+const (
+	NFTTypePaper  = "paper"
+	NFTTypeReview = "review"
+)
+
+// ZeroAddress is the sender of mint transfers and the owner of burned tokens
+const ZeroAddress = "0x0000000000000000000000000000000000000000"
+
+// NFTTransfer is a change of ownership of a token. Mints are recorded as
+// transfers from ZeroAddress so the history of a token is complete.
+type NFTTransfer struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
-	TokenID     uint      `json:"token_id" gorm:"unique"`
-	Type        string    `json:"type"`         // paper, review
-	ReferenceID uint      `json:"reference_id"` // Paper ID or Review ID
-	MetadataURI string    `json:"metadata_uri"` // IPFS URI
-	TxHash      string    `json:"tx_hash"`      // Transaction hash
+	NFTID       uint      `json:"nft_id" gorm:"index"`
+	FromAddr    string    `json:"from_address"`
+	ToAddr      string    `json:"to_address"`
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	InitiatedBy *uint     `json:"initiated_by,omitempty"` // User who requested the transfer through the API
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+// ChainCheckpoint is the last block a chain follower has fully processed. The
+// block hash is kept to detect reorganisations below the checkpoint.
+type ChainCheckpoint struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex"`
+	BlockNumber uint64    `json:"block_number"`
+	BlockHash   string    `json:"block_hash"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MintJob is a queued request to mint a paper or review NFT. At most one job
+// exists per paper or review, which keeps minting idempotent.
+type MintJob struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Type        string     `json:"type" gorm:"uniqueIndex:idx_mint_job_reference"`         // paper, review
+	ReferenceID uint       `json:"reference_id" gorm:"uniqueIndex:idx_mint_job_reference"` // Paper ID or Review ID
+	RequestedBy uint       `json:"requested_by"`
+	Status      string     `json:"status" gorm:"index;default:'queued'"` // see MintJobStatus* constants
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	NextRunAt   time.Time  `json:"next_run_at" gorm:"index"`
+	LockedUntil *time.Time `json:"-"`
+	LastError   string     `json:"last_error,omitempty"`
+	TxHash      string     `json:"tx_hash,omitempty"`
+	TokenID     *uint      `json:"token_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Mint job statuses
+const (
+	MintJobStatusQueued    = "queued"    // waiting for a worker, possibly after a failed attempt
+	MintJobStatusSubmitted = "submitted" // transaction broadcast, waiting for confirmation
+	MintJobStatusConfirmed = "confirmed" // token minted and recorded
+	MintJobStatusFailed    = "failed"    // dead-lettered after a permanent error or too many attempts
+)
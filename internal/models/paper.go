@@ -7,20 +7,48 @@ import (
 )
 
 type Paper struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	Title      string         `json:"title" gorm:"not null"`
-	Abstract   string         `json:"abstract"`
-	Authors    datatypes.JSON `json:"authors" gorm:"type:json"`
-	Keywords   datatypes.JSON `json:"keywords" gorm:"type:json"`
-	Category   string         `json:"category"`
-	IPFSHash   string         `json:"ipfs_hash"`
-	NFTTokenID *uint          `json:"nft_token_id"`
-	OwnerID    uint           `json:"owner_id"`
-	Status     string         `json:"status" gorm:"default:'draft'"` // draft, submitted, under_review, published
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Title        string         `json:"title" gorm:"not null"`
+	Abstract     string         `json:"abstract"`
+	Authors      datatypes.JSON `json:"authors" gorm:"type:json"`
+	Keywords     datatypes.JSON `json:"keywords" gorm:"type:json"`
+	Category     string         `json:"category"`
+	IPFSHash     string         `json:"ipfs_hash"`         // CID of the manuscript
+	FileName     string         `json:"file_name"`         // Original name of the uploaded manuscript
+	FileSize     int64          `json:"file_size"`         // Manuscript size in bytes
+	FileSHA256   string         `json:"file_sha256"`       // Hex SHA-256 of the manuscript
+	FileType     string         `json:"file_content_type"` // Detected content type of the manuscript
+	NFTTokenID   *uint          `json:"nft_token_id"`
+	OwnerID      uint           `json:"owner_id"`
+	Status       string         `json:"status" gorm:"default:'draft'"` // see PaperStatus* constants
+	ReviewPolicy string         `json:"review_policy"`                 // see ReviewPolicy* constants; "" inherits the category's
+	ReviewRound  int            `json:"review_round"`                  // Decisions made on the paper so far, numbering its current review round
+	PublishedAt  *time.Time     `json:"published_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
 
 	// Relationships
 	Owner   User     `json:"owner" gorm:"foreignKey:OwnerID"`
 	Reviews []Review `json:"reviews,omitempty" gorm:"foreignKey:PaperID"`
 }
+
+// Review policies decide whose identities are hidden during review
+const (
+	ReviewPolicyOpen        = "open"         // Authors and reviewers see each other
+	ReviewPolicySingleBlind = "single_blind" // Reviewers are hidden from authors
+	ReviewPolicyDoubleBlind = "double_blind" // Reviewers and, until publication, authors are hidden from each other
+)
+
+// ReviewPolicies lists every review policy
+var ReviewPolicies = []string{ReviewPolicyOpen, ReviewPolicySingleBlind, ReviewPolicyDoubleBlind}
+
+// Paper lifecycle statuses
+const (
+	PaperStatusDraft             = "draft"
+	PaperStatusSubmitted         = "submitted"
+	PaperStatusUnderReview       = "under_review"
+	PaperStatusRevisionRequested = "revision_requested"
+	PaperStatusAccepted          = "accepted"
+	PaperStatusRejected          = "rejected"
+	PaperStatusPublished         = "published"
+)
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is an opaque, single-use refresh token. Only the SHA-256 of the
+// token is stored. Tokens rotated from the same login share a session, so a
+// replayed token can revoke every descendant of the login at once.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	SessionID uint       `json:"session_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // Set when the token is rotated
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Set on logout or reuse detection
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -13,19 +13,49 @@ type Review struct {
 	Score          int            `json:"score" gorm:"check:score >= 1 AND score <= 10"`
 	Comment        string         `json:"comment"`
 	Recommendation string         `json:"recommendation"`                  // accept, reject, revision
+	Confidence     int            `json:"confidence,omitempty"`            // Reviewer's confidence in 1-5, 0 if not given
+	Round          int            `json:"round"`                           // Review round of the paper the review was last written in
 	Status         string         `json:"status" gorm:"default:'pending'"` // pending, completed, rejected
-	Metadata       datatypes.JSON `json:"metadata"`
+	Metadata       datatypes.JSON `json:"metadata"`                        // ReviewMetadata
 	NFTTokenID     *uint          `json:"nft_token_id"`
+	IPFSHash       string         `json:"ipfs_hash,omitempty"` // CID of the token metadata pinned when the review is minted
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 
+	// Comments to editors only, never serialized with the review
+	ConfidentialComments string `json:"-"`
+
 	// Relationships
 	Paper    Paper `json:"paper" gorm:"foreignKey:PaperID"`
 	Reviewer User  `json:"reviewer" gorm:"foreignKey:ReviewerID"`
 }
 
+// ReviewMetadataVersion is the format of ReviewMetadata written by this
+// version. Version 1 held a review_criteria map that was never filled in.
+const ReviewMetadataVersion = 2
+
+// ReviewMetadata is a review as filled in on its rubric
 type ReviewMetadata struct {
-	ReviewCriteria map[string]interface{} `json:"review_criteria"`
-	ReviewType     string                 `json:"review_type"`
-	Version        int                    `json:"version"`
+	Version        int               `json:"version"`
+	ReviewType     string            `json:"review_type"`
+	RubricVersion  int               `json:"rubric_version"`
+	RubricCategory string            `json:"rubric_category,omitempty"` // Category whose rubric was used, "" for the default
+	Scale          ReviewScale       `json:"scale"`
+	Criteria       []CriterionScore  `json:"criteria"`
+	Sections       map[string]string `json:"sections,omitempty"`
+	OverallScore   float64           `json:"overall_score"` // Weighted mean of the criteria scores
+}
+
+// ReviewScale is the inclusive range criteria were scored in
+type ReviewScale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// CriterionScore is the score given to a rubric criterion
+type CriterionScore struct {
+	Key    string  `json:"key"`
+	Label  string  `json:"label"`
+	Weight float64 `json:"weight"`
+	Score  int     `json:"score"`
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Review assignment statuses
+const (
+	AssignmentStatusInvited   = "invited"   // Waiting for the reviewer to accept or decline
+	AssignmentStatusAccepted  = "accepted"  // The reviewer owes a review
+	AssignmentStatusDeclined  = "declined"  // The reviewer turned the invitation down or withdrew
+	AssignmentStatusCompleted = "completed" // The review was submitted
+	AssignmentStatusCancelled = "cancelled" // The editor withdrew the invitation
+)
+
+// AssignmentStatuses lists every review assignment status
+var AssignmentStatuses = []string{
+	AssignmentStatusInvited,
+	AssignmentStatusAccepted,
+	AssignmentStatusDeclined,
+	AssignmentStatusCompleted,
+	AssignmentStatusCancelled,
+}
+
+// ReviewAssignment is an editor's invitation of a reviewer to review a paper.
+// Reviews can only be submitted for accepted assignments.
+type ReviewAssignment struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	PaperID       uint       `json:"paper_id" gorm:"index;not null"`
+	ReviewerID    uint       `json:"reviewer_id" gorm:"index;not null"`
+	EditorID      uint       `json:"editor_id" gorm:"not null"` // Who sent the invitation
+	Status        string     `json:"status" gorm:"not null;default:'invited'"`
+	DueAt         time.Time  `json:"due_at"`
+	DeclineReason string     `json:"decline_reason,omitempty"`
+	RespondedAt   *time.Time `json:"responded_at"` // When the reviewer accepted or declined
+	ReviewID      *uint      `json:"review_id"`    // Set once completed
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	Paper    Paper `json:"paper" gorm:"foreignKey:PaperID"`
+	Reviewer User  `json:"reviewer" gorm:"foreignKey:ReviewerID"`
+}
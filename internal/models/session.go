@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a login on one device. The refresh tokens rotated from the login
+// belong to the session, and at most one access token of the session is valid
+// at a time: issuing a new one revokes CurrentJTI.
+type Session struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;not null"`
+	UserAgent       string     `json:"user_agent"`
+	IPAddress       string     `json:"ip_address"`
+	CurrentJTI      string     `json:"-"`            // ID of the latest access token
+	AccessExpiresAt time.Time  `json:"-"`            // Expiry of the latest access token
+	LastSeenAt      time.Time  `json:"last_seen_at"` // Last login or refresh
+	ExpiresAt       time.Time  `json:"expires_at"`   // Expiry of the latest refresh token
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// RevokedToken is a denylisted access token, kept until the token expires
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Platform setting keys
+const (
+	SettingRequire2FA           = "require_2fa"           // "true" if every user must enable two-factor authentication
+	SettingReviewPolicy         = "review_policy"         // Review policy of papers whose category has none
+	SettingCategoryReviewPolicy = "review_policy:"        // Prefix of the review policy of a category, e.g. "review_policy:cs"
+	SettingReviewRubric         = "review_rubric"         // JSON rubric of papers whose category has none
+	SettingCategoryReviewRubric = "review_rubric:"        // Prefix of the JSON rubric of a category
+	SettingReviewRubricVersion  = "review_rubric_version" // Version last assigned to a saved rubric
+	SettingDecisionPolicy       = "decision_policy"       // JSON policy drafting editorial decisions from reviews
+)
+
+// Setting is a platform-wide option changed at runtime by admins
+type Setting struct {
+	Key       string    `json:"key" gorm:"primaryKey"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,21 +1,48 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"gorm.io/datatypes"
+)
+
+// User roles
+const (
+	RoleResearcher = "researcher"
+	RoleReviewer   = "reviewer"
+	RoleAdmin      = "admin"
 )
 
+// Roles lists every user role
+var Roles = []string{RoleResearcher, RoleReviewer, RoleAdmin}
+
 type User struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Email       string    `json:"email" gorm:"unique;not null"`
-	Password    string    `json:"-" gorm:"not null"`
-	Name        string    `json:"name" gorm:"not null"`
-	WalletAddr  string    `json:"wallet_address" gorm:"unique"`
-	Role        string    `json:"role" gorm:"default:'researcher'"` // researcher, reviewer, admin
-	Institution string    `json:"institution"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Email           string         `json:"email" gorm:"unique;not null"`
+	Password        string         `json:"-" gorm:"not null"`
+	Name            string         `json:"name" gorm:"not null"`
+	WalletAddr      *string        `json:"wallet_address" gorm:"unique"`     // nil until a wallet is linked
+	Role            string         `json:"role" gorm:"default:'researcher'"` // researcher, reviewer, admin
+	Institution     string         `json:"institution"`
+	Interests       datatypes.JSON `json:"interests" gorm:"type:json"` // Declared research interests, used to match reviewers
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`          // nil until the user proves control of Email
+	TOTPSecret      string         `json:"-"`                          // Base32 TOTP secret, set during setup before TOTPEnabledAt
+	TOTPEnabledAt   *time.Time     `json:"totp_enabled_at"`            // nil unless logins require a TOTP code
+	TOTPLastStep    int64          `json:"-"`                          // Time step of the last accepted code, which cannot be reused
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
 
 	// Relationships
 	Papers  []Paper  `json:"papers,omitempty" gorm:"foreignKey:OwnerID"`
 	Reviews []Review `json:"reviews,omitempty" gorm:"foreignKey:ReviewerID"`
 }
+
+// InterestList returns the declared research interests of the user
+func (u *User) InterestList() []string {
+	var interests []string
+	if len(u.Interests) == 0 || json.Unmarshal(u.Interests, &interests) != nil {
+		return []string{}
+	}
+	return interests
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Wallet nonce purposes
+const (
+	NoncePurposeSIWE = "siwe" // Sign-In with Ethereum
+	NoncePurposeLink = "link" // Linking a wallet to a signed-in account
+)
+
+// WalletNonce is a single-use challenge a wallet signs to prove control of its address
+type WalletNonce struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Nonce     string     `json:"nonce" gorm:"uniqueIndex;not null"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	UserID    uint       `json:"user_id" gorm:"index"` // User the nonce was issued to, 0 if signed out
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
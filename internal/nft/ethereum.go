@@ -0,0 +1,397 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// Contract method and event signatures of the PaperNFT and ReviewNFT contracts
+const (
+	mintPaperSignature    = "mintPaper(address,string,string)"
+	mintReviewSignature   = "mintReview(address,uint256,uint8,string)"
+	safeTransferSignature = "safeTransferFrom(address,address,uint256)"
+	transferSignature     = "Transfer(address,address,uint256)"
+)
+
+// TransferTopic is the topic hash of the ERC-721 Transfer event
+var TransferTopic = ethereum.EventTopic(transferSignature)
+
+// ErrNoWallet is returned when the NFT recipient has not linked a wallet
+var ErrNoWallet = errors.New("recipient has no wallet address linked")
+
+// gasLimitMargin pads estimated gas by 20% to absorb state changes between estimation and inclusion
+const gasLimitMargin = 120
+
+// EthereumMinter mints NFTs by sending signed transactions to the PaperNFT and
+// ReviewNFT contracts over JSON-RPC.
+//
+// Nonces are tracked locally so concurrent mints from the same account do not
+// collide; the tracker is resynchronised from the node whenever a send fails.
+type EthereumMinter struct {
+	client         *ethereum.Client
+	key            *secp256k1.PrivateKey
+	from           ethereum.Address
+	paperContract  ethereum.Address
+	reviewContract ethereum.Address
+
+	// PollInterval is how often the receipt of a sent transaction is polled
+	PollInterval time.Duration
+	// ReceiptTimeout bounds how long a mint waits for its transaction to be mined
+	ReceiptTimeout time.Duration
+
+	mu      sync.Mutex
+	chainID *big.Int
+	nonce   *uint64
+}
+
+// NewEthereumMinter creates a minter from the Ethereum configuration
+func NewEthereumMinter(cfg config.EthereumConfig) (*EthereumMinter, error) {
+	key, err := ethereum.PrivateKeyFromHex(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRIVATE_KEY: %w", err)
+	}
+
+	paperContract, err := ethereum.HexToAddress(cfg.PaperContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONTRACT_ADDRESS_PAPER: %w", err)
+	}
+
+	reviewContract, err := ethereum.HexToAddress(cfg.ReviewContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONTRACT_ADDRESS_REVIEW: %w", err)
+	}
+
+	return &EthereumMinter{
+		client:         ethereum.NewClient(cfg.RPCURL),
+		key:            key,
+		from:           ethereum.PubkeyToAddress(key.PubKey()),
+		paperContract:  paperContract,
+		reviewContract: reviewContract,
+		PollInterval:   2 * time.Second,
+		ReceiptTimeout: 2 * time.Minute,
+	}, nil
+}
+
+// From returns the address transactions are sent from
+func (m *EthereumMinter) From() ethereum.Address {
+	return m.from
+}
+
+func (m *EthereumMinter) MintPaper(ctx context.Context, paper *models.Paper) (*MintResult, error) {
+	to, err := recipient(&paper.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ethereum.EncodeCall(mintPaperSignature, to, paper.Title, paper.IPFSHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.mint(ctx, m.paperContract, data)
+}
+
+// MintReview mints a review NFT. The on-chain paperId is the paper's token ID
+// when the paper has been minted, otherwise the platform paper ID; ipfsHash is
+// the CID of the review's pinned metadata.
+func (m *EthereumMinter) MintReview(ctx context.Context, review *models.Review) (*MintResult, error) {
+	to, err := recipient(&review.Reviewer)
+	if err != nil {
+		return nil, err
+	}
+	if review.IPFSHash == "" {
+		return nil, fmt.Errorf("review %d has no pinned content to reference", review.ID)
+	}
+
+	paperID := uint64(review.PaperID)
+	if review.Paper.NFTTokenID != nil {
+		paperID = uint64(*review.Paper.NFTTokenID)
+	}
+
+	if review.Score < 0 || review.Score > 255 {
+		return nil, fmt.Errorf("review score %d does not fit in uint8", review.Score)
+	}
+
+	data, err := ethereum.EncodeCall(mintReviewSignature, to, paperID, uint8(review.Score), review.IPFSHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.mint(ctx, m.reviewContract, data)
+}
+
+// mint sends a mint transaction, waits for its receipt and recovers the token ID
+// from the Transfer event emitted by contract.
+func (m *EthereumMinter) mint(ctx context.Context, contract ethereum.Address, data []byte) (*MintResult, error) {
+	txHash, err := m.send(ctx, contract, data)
+	if err != nil {
+		return nil, err
+	}
+	notifySubmitted(ctx, txHash)
+
+	return m.confirm(ctx, contract, txHash)
+}
+
+// Transfer sends safeTransferFrom(from, to, tokenId) from the platform account.
+// The contract only accepts it when the platform account is the owner or an
+// approved operator of from (setApprovalForAll), i.e. for custodial wallets.
+func (m *EthereumMinter) Transfer(ctx context.Context, nftType string, tokenID uint, from, to string) (*TransferResult, error) {
+	var contract ethereum.Address
+	switch nftType {
+	case models.NFTTypePaper:
+		contract = m.paperContract
+	case models.NFTTypeReview:
+		contract = m.reviewContract
+	default:
+		return nil, fmt.Errorf("unknown NFT type: %s", nftType)
+	}
+
+	fromAddr, err := ethereum.HexToAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddr, err := ethereum.HexToAddress(to)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ethereum.EncodeCall(safeTransferSignature, fromAddr, toAddr, uint64(tokenID))
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := m.send(ctx, contract, data)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := m.waitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Succeeded() {
+		return nil, fmt.Errorf("transfer transaction %s reverted", txHash)
+	}
+
+	blockNumber, err := ethereum.DecodeQuantity(receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt block number: %w", err)
+	}
+
+	return &TransferResult{TxHash: txHash, BlockNumber: blockNumber}, nil
+}
+
+// ResumeMint waits for an already broadcast mint transaction and recovers its result
+func (m *EthereumMinter) ResumeMint(ctx context.Context, nftType, txHash string) (*MintResult, error) {
+	switch nftType {
+	case models.NFTTypePaper:
+		return m.confirm(ctx, m.paperContract, txHash)
+	case models.NFTTypeReview:
+		return m.confirm(ctx, m.reviewContract, txHash)
+	default:
+		return nil, fmt.Errorf("unknown NFT type: %s", nftType)
+	}
+}
+
+// confirm waits for txHash to be mined and parses the minted token from its receipt
+func (m *EthereumMinter) confirm(ctx context.Context, contract ethereum.Address, txHash string) (*MintResult, error) {
+	receipt, err := m.waitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Succeeded() {
+		return nil, fmt.Errorf("mint transaction %s reverted", txHash)
+	}
+
+	tokenID, owner, err := mintedToken(receipt, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	blockNumber, err := ethereum.DecodeQuantity(receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt block number: %w", err)
+	}
+
+	return &MintResult{
+		TokenID:      tokenID,
+		ContractAddr: contract.Hex(),
+		TxHash:       txHash,
+		BlockNumber:  blockNumber,
+		Owner:        owner.Hex(),
+	}, nil
+}
+
+// send signs and broadcasts a call to contract, returning the transaction hash
+func (m *EthereumMinter) send(ctx context.Context, contract ethereum.Address, data []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.chainID == nil {
+		chainID, err := m.client.ChainID(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get chain ID: %w", err)
+		}
+		m.chainID = chainID
+	}
+
+	if m.nonce == nil {
+		nonce, err := m.client.PendingNonceAt(ctx, m.from)
+		if err != nil {
+			return "", fmt.Errorf("failed to get nonce: %w", err)
+		}
+		m.nonce = &nonce
+	}
+
+	gasPrice, err := m.client.GasPrice(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	gas, err := m.client.EstimateGas(ctx, ethereum.CallMsg{From: m.from, To: contract, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	raw, txHash, err := ethereum.SignLegacyTx(&ethereum.LegacyTx{
+		Nonce:    *m.nonce,
+		GasPrice: gasPrice,
+		Gas:      gas * gasLimitMargin / 100,
+		To:       &contract,
+		Data:     data,
+	}, m.chainID, m.key)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := m.client.SendRawTransaction(ctx, raw); err != nil {
+		// The node may know about transactions we do not; resync on the next send
+		m.nonce = nil
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	*m.nonce++
+	logger.Info("Mint transaction sent", "tx_hash", txHash, "contract", contract.Hex())
+	return txHash, nil
+}
+
+func (m *EthereumMinter) waitForReceipt(ctx context.Context, txHash string) (*ethereum.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.ReceiptTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(m.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := m.client.TransactionReceipt(ctx, txHash)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to fetch transaction receipt", "tx_hash", txHash, "error", err)
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for receipt of %s: %w", txHash, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// mintedToken finds the Transfer(0x0, to, tokenId) log emitted by contract and
+// returns the token ID and recipient
+func mintedToken(receipt *ethereum.Receipt, contract ethereum.Address) (uint, ethereum.Address, error) {
+	for _, log := range receipt.Logs {
+		if !strings.EqualFold(log.Address, contract.Hex()) || !IsTransferLog(log) {
+			continue
+		}
+
+		event, err := DecodeTransferLog(log)
+		if err != nil {
+			return 0, ethereum.Address{}, err
+		}
+		if event.From != (ethereum.Address{}) {
+			continue
+		}
+
+		return event.TokenID, event.To, nil
+	}
+
+	return 0, ethereum.Address{}, fmt.Errorf("no Transfer event from %s in transaction %s", contract.Hex(), receipt.TxHash)
+}
+
+// TransferEvent is a decoded ERC-721 Transfer event
+type TransferEvent struct {
+	Contract    string
+	From        ethereum.Address
+	To          ethereum.Address
+	TokenID     uint
+	BlockNumber uint64 // Zero for logs taken from a receipt without block fields
+	TxHash      string
+}
+
+// IsTransferLog reports whether log is an ERC-721 Transfer event. ERC-20
+// Transfer events share the topic but do not index the third argument.
+func IsTransferLog(log ethereum.Log) bool {
+	return len(log.Topics) == 4 && strings.EqualFold(log.Topics[0], TransferTopic)
+}
+
+// DecodeTransferLog decodes an ERC-721 Transfer event log
+func DecodeTransferLog(log ethereum.Log) (*TransferEvent, error) {
+	if !IsTransferLog(log) {
+		return nil, fmt.Errorf("log %s is not an ERC-721 Transfer event", log.TxHash)
+	}
+
+	tokenID, err := ethereum.DecodeBigQuantity(log.Topics[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token ID in Transfer event: %w", err)
+	}
+	if !tokenID.IsUint64() || tokenID.Uint64() > uint64(^uint(0)) {
+		return nil, fmt.Errorf("token ID %s out of range", tokenID)
+	}
+
+	event := &TransferEvent{
+		Contract: log.Address,
+		From:     TopicToAddress(log.Topics[1]),
+		To:       TopicToAddress(log.Topics[2]),
+		TokenID:  uint(tokenID.Uint64()),
+		TxHash:   log.TxHash,
+	}
+	if log.BlockNumber != "" {
+		if event.BlockNumber, err = ethereum.DecodeQuantity(log.BlockNumber); err != nil {
+			return nil, fmt.Errorf("invalid block number in Transfer event: %w", err)
+		}
+	}
+
+	return event, nil
+}
+
+// TopicToAddress extracts an address from a 32-byte indexed event topic
+func TopicToAddress(topic string) ethereum.Address {
+	var addr ethereum.Address
+	b, err := ethereum.DecodeHex(topic)
+	if err == nil && len(b) == 32 {
+		copy(addr[:], b[12:])
+	}
+	return addr
+}
+
+func recipient(user *models.User) (ethereum.Address, error) {
+	wallet := walletOf(user)
+	if wallet == "" {
+		return ethereum.Address{}, ErrNoWallet
+	}
+	return ethereum.HexToAddress(wallet)
+}
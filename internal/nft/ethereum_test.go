@@ -0,0 +1,215 @@
+package nft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPaperContract  = "0x00000000000000000000000000000000000000a1"
+	testReviewContract = "0x00000000000000000000000000000000000000a2"
+)
+
+// fakeNode is a JSON-RPC stand-in for a local dev chain
+type fakeNode struct {
+	mu           sync.Mutex
+	calls        map[string]int
+	sent         []string
+	lastData     string
+	lastTo       string
+	nextToken    uint64
+	failNextSend bool
+	receipts     map[string]map[string]interface{}
+	pendingPolls map[string]int
+}
+
+func newFakeNode() *fakeNode {
+	return &fakeNode{
+		calls:        make(map[string]int),
+		nextToken:    100,
+		receipts:     make(map[string]map[string]interface{}),
+		pendingPolls: make(map[string]int),
+	}
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls[req.Method]++
+
+	var result interface{}
+	var rpcErr map[string]interface{}
+
+	switch req.Method {
+	case "eth_chainId":
+		result = "0x539"
+	case "eth_getTransactionCount":
+		result = "0x5"
+	case "eth_gasPrice":
+		result = "0x3b9aca00"
+	case "eth_estimateGas":
+		var msg map[string]string
+		json.Unmarshal(req.Params[0], &msg)
+		n.lastData = msg["data"]
+		n.lastTo = msg["to"]
+		result = "0x30d40"
+	case "eth_sendRawTransaction":
+		if n.failNextSend {
+			n.failNextSend = false
+			rpcErr = map[string]interface{}{"code": -32000, "message": "nonce too low"}
+			break
+		}
+		var raw string
+		json.Unmarshal(req.Params[0], &raw)
+		b, _ := ethereum.DecodeHex(raw)
+		hash := ethereum.EncodeHex(ethereum.Keccak256(b))
+		n.sent = append(n.sent, hash)
+
+		tokenTopic := fmt.Sprintf("0x%064x", n.nextToken)
+		n.nextToken++
+		n.receipts[hash] = map[string]interface{}{
+			"transactionHash": hash,
+			"blockNumber":     fmt.Sprintf("0x%x", 10+len(n.sent)),
+			"status":          "0x1",
+			"logs": []map[string]interface{}{{
+				"address": n.lastTo,
+				"topics": []string{
+					TransferTopic,
+					ethereum.EncodeHex(make([]byte, 32)),
+					"0x000000000000000000000000" + strings.Repeat("11", 20),
+					tokenTopic,
+				},
+				"data": "0x",
+			}},
+		}
+		// Each transaction stays pending for one poll
+		n.pendingPolls[hash] = 1
+		result = hash
+	case "eth_getTransactionReceipt":
+		var hash string
+		json.Unmarshal(req.Params[0], &hash)
+		if n.pendingPolls[hash] > 0 {
+			n.pendingPolls[hash]--
+			result = nil
+		} else {
+			result = n.receipts[hash]
+		}
+	default:
+		rpcErr = map[string]interface{}{"code": -32601, "message": "method not found"}
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+func newTestMinter(t *testing.T, node *fakeNode) *EthereumMinter {
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	minter, err := NewEthereumMinter(config.EthereumConfig{
+		RPCURL:             server.URL,
+		PrivateKey:         "0x4646464646464646464646464646464646464646464646464646464646464646",
+		PaperContractAddr:  testPaperContract,
+		ReviewContractAddr: testReviewContract,
+	})
+	require.NoError(t, err)
+	minter.PollInterval = time.Millisecond
+	minter.ReceiptTimeout = time.Second
+	return minter
+}
+
+func TestEthereumMinterMintsPaperAndReview(t *testing.T) {
+	node := newFakeNode()
+	minter := newTestMinter(t, node)
+
+	wallet := "0x1111111111111111111111111111111111111111"
+	paper := &models.Paper{Title: "On Things", IPFSHash: "bafy", Owner: models.User{WalletAddr: &wallet}}
+
+	result, err := minter.MintPaper(context.Background(), paper)
+	require.NoError(t, err)
+	assert.Equal(t, uint(100), result.TokenID)
+	assert.Equal(t, uint64(11), result.BlockNumber)
+	assert.Equal(t, node.sent[0], result.TxHash)
+	assert.True(t, strings.EqualFold(testPaperContract, result.ContractAddr))
+	assert.True(t, strings.HasPrefix(node.lastData, ethereum.EncodeHex(ethereum.FunctionSelector(mintPaperSignature))))
+
+	tokenID := result.TokenID
+	review := &models.Review{Score: 8, PaperID: 1, Paper: models.Paper{NFTTokenID: &tokenID}, Reviewer: models.User{WalletAddr: &wallet}}
+
+	// Reviews reference their pinned metadata on chain
+	_, err = minter.MintReview(context.Background(), review)
+	assert.Error(t, err)
+
+	review.IPFSHash = "bafyreview"
+	result, err = minter.MintReview(context.Background(), review)
+	require.NoError(t, err)
+	assert.Equal(t, uint(101), result.TokenID)
+	to, err := ethereum.HexToAddress(wallet)
+	require.NoError(t, err)
+	data, err := ethereum.EncodeCall(mintReviewSignature, to, uint64(tokenID), uint8(8), "bafyreview")
+	require.NoError(t, err)
+	assert.Equal(t, ethereum.EncodeHex(data), node.lastData)
+
+	// The nonce is fetched once and then tracked locally
+	assert.Equal(t, 1, node.calls["eth_getTransactionCount"])
+	assert.Equal(t, 1, node.calls["eth_chainId"])
+	assert.NotEqual(t, node.sent[0], node.sent[1])
+}
+
+func TestEthereumMinterResyncsNonceAfterFailedSend(t *testing.T) {
+	node := newFakeNode()
+	node.failNextSend = true
+	minter := newTestMinter(t, node)
+
+	wallet := "0x1111111111111111111111111111111111111111"
+	paper := &models.Paper{Title: "On Things", Owner: models.User{WalletAddr: &wallet}}
+
+	_, err := minter.MintPaper(context.Background(), paper)
+	assert.Error(t, err)
+
+	_, err = minter.MintPaper(context.Background(), paper)
+	require.NoError(t, err)
+	assert.Equal(t, 2, node.calls["eth_getTransactionCount"])
+}
+
+func TestEthereumMinterRequiresWallet(t *testing.T) {
+	minter := newTestMinter(t, newFakeNode())
+
+	_, err := minter.MintPaper(context.Background(), &models.Paper{Title: "On Things"})
+	assert.ErrorIs(t, err, ErrNoWallet)
+}
@@ -0,0 +1,150 @@
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+)
+
+// TokenMetadata is an ERC-721 metadata document with the OpenSea extensions
+type TokenMetadata struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Image       string      `json:"image,omitempty"`
+	ExternalURL string      `json:"external_url,omitempty"`
+	Attributes  []Attribute `json:"attributes"`
+}
+
+// Attribute is an OpenSea metadata trait
+type Attribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"` // number, boost_number, boost_percentage or date
+	MaxValue    interface{} `json:"max_value,omitempty"`
+}
+
+// MetadataOptions supplies the links that are not derived from the paper or review
+type MetadataOptions struct {
+	Image       string // URI of the token image
+	ExternalURL string // Page describing the paper or review
+}
+
+// maxReviewScore is the upper bound of models.Review.Score
+const maxReviewScore = 10
+
+// PaperMetadata builds the metadata of a paper token from the paper and its reviews
+func PaperMetadata(paper *models.Paper, reviews []models.Review, opts MetadataOptions) *TokenMetadata {
+	attributes := []Attribute{
+		{TraitType: "Type", Value: "Paper"},
+	}
+	if paper.Category != "" {
+		attributes = append(attributes, Attribute{TraitType: "Category", Value: paper.Category})
+	}
+	for _, keyword := range jsonStrings(paper.Keywords) {
+		attributes = append(attributes, Attribute{TraitType: "Keyword", Value: keyword})
+	}
+	for _, author := range jsonStrings(paper.Authors) {
+		attributes = append(attributes, Attribute{TraitType: "Author", Value: author})
+	}
+
+	attributes = append(attributes, Attribute{TraitType: "Review Count", Value: len(reviews), DisplayType: "number"})
+	if len(reviews) > 0 {
+		attributes = append(attributes,
+			Attribute{TraitType: "Average Review Score", Value: averageScore(reviews), DisplayType: "number", MaxValue: maxReviewScore},
+		)
+		if recommendation := consensus(reviews); recommendation != "" {
+			attributes = append(attributes, Attribute{TraitType: "Recommendation", Value: recommendation})
+		}
+	}
+	if paper.IPFSHash != "" {
+		attributes = append(attributes, Attribute{TraitType: "Manuscript CID", Value: paper.IPFSHash})
+	}
+	if paper.PublishedAt != nil {
+		attributes = append(attributes, Attribute{TraitType: "Published", Value: paper.PublishedAt.Unix(), DisplayType: "date"})
+	}
+
+	return &TokenMetadata{
+		Name:        paper.Title,
+		Description: paper.Abstract,
+		Image:       opts.Image,
+		ExternalURL: opts.ExternalURL,
+		Attributes:  attributes,
+	}
+}
+
+// ReviewMetadata builds the metadata of a review token. The review must have Paper
+// loaded. The comment and reviewer are deliberately left out of the public document.
+func ReviewMetadata(review *models.Review, opts MetadataOptions) *TokenMetadata {
+	attributes := []Attribute{
+		{TraitType: "Type", Value: "Review"},
+		{TraitType: "Score", Value: review.Score, DisplayType: "number", MaxValue: maxReviewScore},
+	}
+	if review.Recommendation != "" {
+		attributes = append(attributes, Attribute{TraitType: "Recommendation", Value: review.Recommendation})
+	}
+	if review.Paper.Category != "" {
+		attributes = append(attributes, Attribute{TraitType: "Category", Value: review.Paper.Category})
+	}
+	for _, keyword := range jsonStrings(review.Paper.Keywords) {
+		attributes = append(attributes, Attribute{TraitType: "Keyword", Value: keyword})
+	}
+	if review.Paper.NFTTokenID != nil {
+		attributes = append(attributes, Attribute{TraitType: "Paper Token ID", Value: *review.Paper.NFTTokenID})
+	}
+	attributes = append(attributes, Attribute{TraitType: "Reviewed", Value: review.CreatedAt.Unix(), DisplayType: "date"})
+
+	return &TokenMetadata{
+		Name:        fmt.Sprintf("Review #%d: %s", review.ID, review.Paper.Title),
+		Description: fmt.Sprintf("Peer review of %q with a score of %d/%d.", review.Paper.Title, review.Score, maxReviewScore),
+		Image:       opts.Image,
+		ExternalURL: opts.ExternalURL,
+		Attributes:  attributes,
+	}
+}
+
+// averageScore returns the mean review score rounded to two decimals
+func averageScore(reviews []models.Review) float64 {
+	total := 0
+	for _, review := range reviews {
+		total += review.Score
+	}
+	return math.Round(float64(total)/float64(len(reviews))*100) / 100
+}
+
+// consensus returns the most common recommendation; ties go to the alphabetically first
+func consensus(reviews []models.Review) string {
+	counts := make(map[string]int)
+	for _, review := range reviews {
+		if review.Recommendation != "" {
+			counts[review.Recommendation]++
+		}
+	}
+
+	recommendations := make([]string, 0, len(counts))
+	for recommendation := range counts {
+		recommendations = append(recommendations, recommendation)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if counts[recommendations[i]] != counts[recommendations[j]] {
+			return counts[recommendations[i]] > counts[recommendations[j]]
+		}
+		return recommendations[i] < recommendations[j]
+	})
+
+	if len(recommendations) == 0 {
+		return ""
+	}
+	return recommendations[0]
+}
+
+// jsonStrings decodes a JSON string array column, ignoring malformed values
+func jsonStrings(raw []byte) []string {
+	var values []string
+	if len(raw) == 0 || json.Unmarshal(raw, &values) != nil {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,80 @@
+package nft
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaperMetadata(t *testing.T) {
+	publishedAt := time.Unix(1700000000, 0)
+	paper := &models.Paper{
+		Title:       "A Study of Things",
+		Abstract:    "An abstract",
+		Authors:     []byte(`["Ada","Grace"]`),
+		Keywords:    []byte(`["things"]`),
+		Category:    "cs",
+		IPFSHash:    "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		PublishedAt: &publishedAt,
+		UpdatedAt:   time.Unix(1800000000, 0),
+	}
+	reviews := []models.Review{
+		{Score: 8, Recommendation: "accept"},
+		{Score: 7, Recommendation: "revision"},
+		{Score: 6, Recommendation: "accept"},
+	}
+
+	metadata := PaperMetadata(paper, reviews, MetadataOptions{Image: "ipfs://image", ExternalURL: "https://example.com/papers/1"})
+
+	doc, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "A Study of Things",
+		"description": "An abstract",
+		"image": "ipfs://image",
+		"external_url": "https://example.com/papers/1",
+		"attributes": [
+			{"trait_type": "Type", "value": "Paper"},
+			{"trait_type": "Category", "value": "cs"},
+			{"trait_type": "Keyword", "value": "things"},
+			{"trait_type": "Author", "value": "Ada"},
+			{"trait_type": "Author", "value": "Grace"},
+			{"trait_type": "Review Count", "value": 3, "display_type": "number"},
+			{"trait_type": "Average Review Score", "value": 7, "display_type": "number", "max_value": 10},
+			{"trait_type": "Recommendation", "value": "accept"},
+			{"trait_type": "Manuscript CID", "value": "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+			{"trait_type": "Published", "value": 1700000000, "display_type": "date"}
+		]
+	}`, string(doc))
+}
+
+func TestReviewMetadataOmitsReviewerAndComment(t *testing.T) {
+	tokenID := uint(5)
+	review := &models.Review{
+		ID:             3,
+		Score:          9,
+		Comment:        "confidential remarks",
+		Recommendation: "accept",
+		Paper:          models.Paper{Title: "A Study of Things", NFTTokenID: &tokenID},
+		Reviewer:       models.User{Name: "Anonymous Referee"},
+	}
+
+	doc, err := json.Marshal(ReviewMetadata(review, MetadataOptions{}))
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), `"name":"Review #3: A Study of Things"`)
+	assert.Contains(t, string(doc), `{"trait_type":"Paper Token ID","value":5}`)
+	assert.NotContains(t, string(doc), "confidential")
+	assert.NotContains(t, string(doc), "Anonymous Referee")
+	assert.NotContains(t, string(doc), `"image"`)
+}
+
+func TestCardSVGEscapesAndWraps(t *testing.T) {
+	svg := string(CardSVG("Paper", "<script>alert(1)</script> and a very long title that goes on and on and on and on and on and on and on and on and on and on and never ends", "cs"))
+	assert.NotContains(t, svg, "<script>")
+	assert.Contains(t, svg, "&lt;script&gt;")
+	assert.Contains(t, svg, "…")
+}
@@ -0,0 +1,72 @@
+package nft
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+)
+
+// MintResult describes a confirmed mint transaction
+type MintResult struct {
+	TokenID      uint
+	ContractAddr string
+	TxHash       string
+	BlockNumber  uint64
+	Owner        string // Wallet address the token was minted to
+}
+
+// Minter mints paper and review NFTs on a chain.
+//
+// Implementations only talk to the chain; persisting the result is the
+// caller's job. The paper passed to MintPaper must have Owner loaded and the
+// review passed to MintReview must have Reviewer and Paper loaded, and its
+// metadata pinned as IPFSHash.
+type Minter interface {
+	MintPaper(ctx context.Context, paper *models.Paper) (*MintResult, error)
+	MintReview(ctx context.Context, review *models.Review) (*MintResult, error)
+}
+
+// Resumer is implemented by minters that can finish a mint whose transaction
+// was already broadcast, e.g. by a worker that crashed while waiting for it.
+type Resumer interface {
+	ResumeMint(ctx context.Context, nftType, txHash string) (*MintResult, error)
+}
+
+// TransferResult describes a confirmed transfer transaction
+type TransferResult struct {
+	TxHash      string
+	BlockNumber uint64
+}
+
+// Transferer is implemented by chain clients that can move a token between
+// wallets on behalf of its owner
+type Transferer interface {
+	Transfer(ctx context.Context, nftType string, tokenID uint, from, to string) (*TransferResult, error)
+}
+
+// ErrNotTokenOwner is returned when the sender of a transfer does not hold the token
+var ErrNotTokenOwner = errors.New("sender does not own the token")
+
+type submittedHookKey struct{}
+
+// WithSubmittedHook returns a context that makes minters call fn with the
+// transaction hash as soon as a mint transaction has been broadcast, before
+// waiting for it to be mined.
+func WithSubmittedHook(ctx context.Context, fn func(txHash string)) context.Context {
+	return context.WithValue(ctx, submittedHookKey{}, fn)
+}
+
+func notifySubmitted(ctx context.Context, txHash string) {
+	if fn, ok := ctx.Value(submittedHookKey{}).(func(string)); ok {
+		fn(txHash)
+	}
+}
+
+// walletOf returns the user's linked wallet address or "" when none is linked
+func walletOf(user *models.User) string {
+	if user == nil || user.WalletAddr == nil {
+		return ""
+	}
+	return *user.WalletAddr
+}
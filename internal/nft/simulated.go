@@ -0,0 +1,151 @@
+package nft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+)
+
+// Default contract addresses used by the simulated chain when none are configured
+const (
+	SimulatedPaperContract  = "0x00000000000000000000000000000000000000a1"
+	SimulatedReviewContract = "0x00000000000000000000000000000000000000a2"
+)
+
+// SimulatedChain is a deterministic in-memory Minter for tests and offline
+// development. Every mint is mined in its own block, token IDs are sequential
+// per contract starting at 1, and transaction hashes are derived from the
+// contract, token ID and block number so the same sequence of mints always
+// yields the same hashes.
+type SimulatedChain struct {
+	mu             sync.Mutex
+	paperContract  string
+	reviewContract string
+	blockNumber    uint64
+	lastTokenID    map[string]uint
+	owners         map[string]map[uint]string
+}
+
+// NewSimulatedChain creates a simulated chain. Empty contract addresses fall
+// back to SimulatedPaperContract and SimulatedReviewContract.
+func NewSimulatedChain(paperContract, reviewContract string) *SimulatedChain {
+	if paperContract == "" {
+		paperContract = SimulatedPaperContract
+	}
+	if reviewContract == "" {
+		reviewContract = SimulatedReviewContract
+	}
+
+	return &SimulatedChain{
+		paperContract:  paperContract,
+		reviewContract: reviewContract,
+		lastTokenID:    make(map[string]uint),
+		owners:         make(map[string]map[uint]string),
+	}
+}
+
+func (c *SimulatedChain) MintPaper(ctx context.Context, paper *models.Paper) (*MintResult, error) {
+	return c.mint(ctx, c.paperContract, walletOf(&paper.Owner))
+}
+
+func (c *SimulatedChain) MintReview(ctx context.Context, review *models.Review) (*MintResult, error) {
+	return c.mint(ctx, c.reviewContract, walletOf(&review.Reviewer))
+}
+
+// OwnerOf returns the current owner of a token and whether it exists
+func (c *SimulatedChain) OwnerOf(contract string, tokenID uint) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	owner, ok := c.owners[contract][tokenID]
+	return owner, ok
+}
+
+// Transfer moves a token from its owner to another wallet in a new block
+func (c *SimulatedChain) Transfer(ctx context.Context, nftType string, tokenID uint, from, to string) (*TransferResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	contract, err := c.contract(nftType)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	owner, ok := c.owners[contract][tokenID]
+	if !ok {
+		return nil, fmt.Errorf("token %d does not exist on %s", tokenID, contract)
+	}
+	if !strings.EqualFold(owner, from) {
+		return nil, ErrNotTokenOwner
+	}
+
+	c.blockNumber++
+	c.owners[contract][tokenID] = to
+
+	return &TransferResult{
+		TxHash:      simulatedTxHash(contract+":transfer:"+to, tokenID, c.blockNumber),
+		BlockNumber: c.blockNumber,
+	}, nil
+}
+
+func (c *SimulatedChain) contract(nftType string) (string, error) {
+	switch nftType {
+	case models.NFTTypePaper:
+		return c.paperContract, nil
+	case models.NFTTypeReview:
+		return c.reviewContract, nil
+	default:
+		return "", fmt.Errorf("unknown NFT type: %s", nftType)
+	}
+}
+
+// BlockNumber returns the number of the latest block
+func (c *SimulatedChain) BlockNumber() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.blockNumber
+}
+
+func (c *SimulatedChain) mint(ctx context.Context, contract, to string) (*MintResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blockNumber++
+	c.lastTokenID[contract]++
+	tokenID := c.lastTokenID[contract]
+
+	if c.owners[contract] == nil {
+		c.owners[contract] = make(map[uint]string)
+	}
+	c.owners[contract][tokenID] = to
+
+	result := &MintResult{
+		TokenID:      tokenID,
+		ContractAddr: contract,
+		TxHash:       simulatedTxHash(contract, tokenID, c.blockNumber),
+		BlockNumber:  c.blockNumber,
+		Owner:        to,
+	}
+	notifySubmitted(ctx, result.TxHash)
+
+	return result, nil
+}
+
+func simulatedTxHash(contract string, tokenID uint, blockNumber uint64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", contract, tokenID, blockNumber)))
+	return "0x" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package nft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedChainIsDeterministic(t *testing.T) {
+	wallet := "0x1111111111111111111111111111111111111111"
+	paper := &models.Paper{Owner: models.User{WalletAddr: &wallet}}
+	review := &models.Review{}
+
+	run := func() []*MintResult {
+		chain := NewSimulatedChain("", "")
+		var results []*MintResult
+		for i := 0; i < 2; i++ {
+			result, err := chain.MintPaper(context.Background(), paper)
+			assert.NoError(t, err)
+			results = append(results, result)
+		}
+		result, err := chain.MintReview(context.Background(), review)
+		assert.NoError(t, err)
+		return append(results, result)
+	}
+
+	first, second := run(), run()
+	assert.Equal(t, first, second)
+
+	assert.Equal(t, uint(1), first[0].TokenID)
+	assert.Equal(t, uint(2), first[1].TokenID)
+	assert.Equal(t, uint(1), first[2].TokenID)
+	assert.Equal(t, SimulatedReviewContract, first[2].ContractAddr)
+	assert.Equal(t, uint64(3), first[2].BlockNumber)
+	assert.Equal(t, wallet, first[0].Owner)
+	assert.Len(t, first[0].TxHash, 66)
+	assert.NotEqual(t, first[0].TxHash, first[1].TxHash)
+}
+
+func TestSimulatedChainTransfer(t *testing.T) {
+	wallet := "0x1111111111111111111111111111111111111111"
+	collector := "0x2222222222222222222222222222222222222222"
+	chain := NewSimulatedChain("", "")
+
+	minted, err := chain.MintPaper(context.Background(), &models.Paper{Owner: models.User{WalletAddr: &wallet}})
+	assert.NoError(t, err)
+
+	_, err = chain.Transfer(context.Background(), models.NFTTypePaper, minted.TokenID, collector, wallet)
+	assert.ErrorIs(t, err, ErrNotTokenOwner)
+
+	result, err := chain.Transfer(context.Background(), models.NFTTypePaper, minted.TokenID, "0x1111111111111111111111111111111111111111", collector)
+	assert.NoError(t, err)
+	assert.Equal(t, minted.BlockNumber+1, result.BlockNumber)
+
+	owner, ok := chain.OwnerOf(SimulatedPaperContract, minted.TokenID)
+	assert.True(t, ok)
+	assert.Equal(t, collector, owner)
+
+	_, err = chain.Transfer(context.Background(), models.NFTTypeReview, minted.TokenID, collector, wallet)
+	assert.Error(t, err)
+}
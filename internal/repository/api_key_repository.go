@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *APIKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListActive returns the unrevoked keys of a user, newest first
+func (r *APIKeyRepository) ListActive(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC, id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke revokes an unrevoked key of a user. It reports false if the user has no such key.
+func (r *APIKeyRepository) Revoke(userID, id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeAllForUser revokes every key of a user
+func (r *APIKeyRepository) RevokeAllForUser(userID uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records that a key was used at at, unless it was already
+// recorded as used since notBefore
+func (r *APIKeyRepository) TouchLastUsed(id uint, at, notBefore time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, notBefore).
+		Update("last_used_at", at).Error
+}
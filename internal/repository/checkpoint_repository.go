@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckpointRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckpointRepository(db *gorm.DB) *CheckpointRepository {
+	return &CheckpointRepository{db: db}
+}
+
+// Get returns the checkpoint called name, or gorm.ErrRecordNotFound if it was never saved
+func (r *CheckpointRepository) Get(name string) (*models.ChainCheckpoint, error) {
+	var checkpoint models.ChainCheckpoint
+	err := r.db.Where("name = ?", name).First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// Save creates or moves the checkpoint called checkpoint.Name
+func (r *CheckpointRepository) Save(checkpoint *models.ChainCheckpoint) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_number", "block_hash", "updated_at"}),
+	}).Create(checkpoint).Error
+}
+
+// Delete removes the checkpoint called name so processing starts over
+func (r *CheckpointRepository) Delete(name string) error {
+	return r.db.Where("name = ?", name).Delete(&models.ChainCheckpoint{}).Error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type EditorialDecisionRepository struct {
+	db *gorm.DB
+}
+
+func NewEditorialDecisionRepository(db *gorm.DB) *EditorialDecisionRepository {
+	return &EditorialDecisionRepository{db: db}
+}
+
+// Record moves the paper of a decision from status to the decision's paper
+// status, starts its next review round and stores the decision, in a single
+// transaction. It reports false, storing nothing, if the paper is no longer in
+// status or round.
+func (r *EditorialDecisionRepository) Record(decision *models.EditorialDecision, status string) (bool, error) {
+	recorded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Paper{}).
+			Where("id = ? AND status = ? AND review_round = ?", decision.PaperID, status, decision.Round).
+			Updates(map[string]interface{}{"status": decision.PaperStatus, "review_round": decision.Round + 1})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		if err := tx.Create(decision).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// ListByPaper returns the decisions on a paper, oldest first
+func (r *EditorialDecisionRepository) ListByPaper(paperID uint) ([]models.EditorialDecision, error) {
+	var decisions []models.EditorialDecision
+	err := r.db.Where("paper_id = ?", paperID).Order("created_at, id").Find(&decisions).Error
+	return decisions, err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type EmailTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailTokenRepository(db *gorm.DB) *EmailTokenRepository {
+	return &EmailTokenRepository{db: db}
+}
+
+func (r *EmailTokenRepository) Create(token *models.EmailToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *EmailTokenRepository) GetByHash(tokenHash string) (*models.EmailToken, error) {
+	var token models.EmailToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an unused token as used. It reports false if the token was
+// already used, so each token is accepted once.
+func (r *EmailTokenRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.EmailToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateForUser marks the unused tokens of a user for purpose as used, so
+// only a newly sent token works
+func (r *EmailTokenRepository) InvalidateForUser(userID uint, purpose string, at time.Time) error {
+	return r.db.Model(&models.EmailToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+// DeleteExpired removes tokens that expired before now
+func (r *EmailTokenRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.EmailToken{}).Error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type LoginFailureRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginFailureRepository(db *gorm.DB) *LoginFailureRepository {
+	return &LoginFailureRepository{db: db}
+}
+
+func (r *LoginFailureRepository) Create(failure *models.LoginFailure) error {
+	return r.db.Create(failure).Error
+}
+
+// List returns failures, newest first, optionally filtered by email and address
+func (r *LoginFailureRepository) List(email, ipAddress string, limit, offset int) ([]models.LoginFailure, error) {
+	query := r.db.Model(&models.LoginFailure{})
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if ipAddress != "" {
+		query = query.Where("ip_address = ?", ipAddress)
+	}
+
+	var failures []models.LoginFailure
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&failures).Error
+	return failures, err
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type MFAChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewMFAChallengeRepository(db *gorm.DB) *MFAChallengeRepository {
+	return &MFAChallengeRepository{db: db}
+}
+
+func (r *MFAChallengeRepository) Create(challenge *models.MFAChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *MFAChallengeRepository) GetByHash(tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	err := r.db.Where("token_hash = ?", tokenHash).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// AddAttempt counts a code tried with a challenge. It reports false once
+// maxAttempts codes were tried, so codes cannot be guessed with one token.
+func (r *MFAChallengeRepository) AddAttempt(id uint, maxAttempts int) (bool, error) {
+	result := r.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkUsed marks an unused challenge as used. It reports false if the
+// challenge was already used, so each challenge starts one session.
+func (r *MFAChallengeRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteExpired removes challenges that expired before now
+func (r *MFAChallengeRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.MFAChallenge{}).Error
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type MintJobRepository struct {
+	db *gorm.DB
+}
+
+func NewMintJobRepository(db *gorm.DB) *MintJobRepository {
+	return &MintJobRepository{db: db}
+}
+
+// Enqueue creates a job for the referenced paper or review, or returns the
+// existing one. A dead-lettered job is reset and queued again.
+func (r *MintJobRepository) Enqueue(nftType string, referenceID, requestedBy uint, maxAttempts int) (*models.MintJob, error) {
+	job := models.MintJob{
+		Type:        nftType,
+		ReferenceID: referenceID,
+	}
+
+	err := r.db.Where(&job).Attrs(models.MintJob{
+		RequestedBy: requestedBy,
+		Status:      models.MintJobStatusQueued,
+		MaxAttempts: maxAttempts,
+		NextRunAt:   time.Now(),
+	}).FirstOrCreate(&job).Error
+	if err != nil {
+		// A concurrent request may have created the job between the lookup and the insert
+		if lookupErr := r.db.Where("type = ? AND reference_id = ?", nftType, referenceID).First(&job).Error; lookupErr != nil {
+			return nil, err
+		}
+	}
+
+	if job.Status == models.MintJobStatusFailed && job.TxHash == "" {
+		job.Status = models.MintJobStatusQueued
+		job.RequestedBy = requestedBy
+		job.Attempts = 0
+		job.MaxAttempts = maxAttempts
+		job.NextRunAt = time.Now()
+		job.LastError = ""
+		if err := r.db.Save(&job).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &job, nil
+}
+
+func (r *MintJobRepository) GetByID(id uint) (*models.MintJob, error) {
+	var job models.MintJob
+	err := r.db.First(&job, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext leases the next due job for processing. It returns nil when no job
+// is due or another worker claimed it first.
+func (r *MintJobRepository) ClaimNext(now time.Time, lease time.Duration) (*models.MintJob, error) {
+	var job models.MintJob
+	err := r.db.Where("status IN ? AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)",
+		[]string{models.MintJobStatusQueued, models.MintJobStatusSubmitted}, now, now).
+		Order("next_run_at").First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	result := r.db.Model(&models.MintJob{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", job.ID, now).
+		Update("locked_until", lockedUntil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	job.LockedUntil = &lockedUntil
+	return &job, nil
+}
+
+func (r *MintJobRepository) Update(job *models.MintJob) error {
+	return r.db.Save(job).Error
+}
+
+// CountPendingForUser counts the unfinished jobs minting the papers or reviews
+// of a user, whose tokens will go to the user's wallet
+func (r *MintJobRepository) CountPendingForUser(userID uint) (int64, error) {
+	papers := r.db.Model(&models.Paper{}).Select("id").Where("owner_id = ?", userID)
+	reviews := r.db.Model(&models.Review{}).Select("id").Where("reviewer_id = ?", userID)
+
+	var count int64
+	err := r.db.Model(&models.MintJob{}).
+		Where("status IN ?", []string{models.MintJobStatusQueued, models.MintJobStatusSubmitted}).
+		Where(r.db.Where("type = ? AND reference_id IN (?)", models.NFTTypePaper, papers).
+			Or("type = ? AND reference_id IN (?)", models.NFTTypeReview, reviews)).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type NFTRepository struct {
+	db *gorm.DB
+}
+
+// NFTFilter narrows NFT listings; zero fields are ignored
+type NFTFilter struct {
+	Type        string
+	OwnerAddr   string
+	ReferenceID uint
+}
+
+func NewNFTRepository(db *gorm.DB) *NFTRepository {
+	return &NFTRepository{db: db}
+}
+
+// RecordMint stores the metadata of a minted token, links the token ID to the
+// referenced paper or review and records the mint as the token's first transfer,
+// all in a single transaction.
+func (r *NFTRepository) RecordMint(metadata *models.NFTMetadata) error {
+	var table string
+	switch metadata.Type {
+	case models.NFTTypePaper:
+		table = "papers"
+	case models.NFTTypeReview:
+		table = "reviews"
+	default:
+		return fmt.Errorf("unknown NFT type: %s", metadata.Type)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(metadata).Error; err != nil {
+			return err
+		}
+		mint := &models.NFTTransfer{
+			NFTID:       metadata.ID,
+			FromAddr:    models.ZeroAddress,
+			ToAddr:      metadata.OwnerAddr,
+			TxHash:      metadata.TxHash,
+			BlockNumber: metadata.BlockNumber,
+		}
+		if err := tx.Create(mint).Error; err != nil {
+			return err
+		}
+		return tx.Table(table).Where("id = ?", metadata.ReferenceID).
+			Update("nft_token_id", metadata.TokenID).Error
+	})
+}
+
+func (r *NFTRepository) GetByID(id uint) (*models.NFTMetadata, error) {
+	var metadata models.NFTMetadata
+	err := r.db.First(&metadata, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *NFTRepository) GetByToken(nftType string, tokenID uint) (*models.NFTMetadata, error) {
+	var metadata models.NFTMetadata
+	err := r.db.Where("type = ? AND token_id = ?", nftType, tokenID).First(&metadata).Error
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *NFTRepository) GetByReference(nftType string, referenceID uint) (*models.NFTMetadata, error) {
+	var metadata models.NFTMetadata
+	err := r.db.Where("type = ? AND reference_id = ?", nftType, referenceID).First(&metadata).Error
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *NFTRepository) Update(metadata *models.NFTMetadata) error {
+	return r.db.Save(metadata).Error
+}
+
+// List returns NFTs matching filter, newest first
+func (r *NFTRepository) List(filter NFTFilter, limit, offset int) ([]models.NFTMetadata, error) {
+	query := r.db.Model(&models.NFTMetadata{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.OwnerAddr != "" {
+		// Addresses may be stored checksummed or lowercase
+		query = query.Where("LOWER(owner_addr) = LOWER(?)", filter.OwnerAddr)
+	}
+	if filter.ReferenceID != 0 {
+		query = query.Where("reference_id = ?", filter.ReferenceID)
+	}
+
+	var nfts []models.NFTMetadata
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&nfts).Error
+	return nfts, err
+}
+
+// RecordTransfer stores a transfer and moves the token to its recipient in a single transaction
+func (r *NFTRepository) RecordTransfer(metadata *models.NFTMetadata, transfer *models.NFTTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		transfer.NFTID = metadata.ID
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		metadata.OwnerAddr = transfer.ToAddr
+		return tx.Model(metadata).Update("owner_addr", transfer.ToAddr).Error
+	})
+}
+
+// GetTransfers returns the ownership history of a token, oldest first
+func (r *NFTRepository) GetTransfers(nftID uint) ([]models.NFTTransfer, error) {
+	var transfers []models.NFTTransfer
+	err := r.db.Where("nft_id = ?", nftID).Order("block_number, id").Find(&transfers).Error
+	return transfers, err
+}
+
+// ApplyChainTransfer records a transfer observed on chain. Transfers already
+// recorded for the token in the same transaction, such as the mint or a transfer
+// made through the API, are skipped, as are tokens the platform did not mint.
+// The owner only moves when no later transfer of the token is known. It reports
+// whether the transfer was recorded.
+func (r *NFTRepository) ApplyChainTransfer(nftType string, tokenID uint, transfer *models.NFTTransfer) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var metadata models.NFTMetadata
+		err := tx.Where("type = ? AND token_id = ?", nftType, tokenID).First(&metadata).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&models.NFTTransfer{}).
+			Where("nft_id = ? AND LOWER(tx_hash) = LOWER(?)", metadata.ID, transfer.TxHash).
+			Count(&count).Error
+		if err != nil || count > 0 {
+			return err
+		}
+
+		transfer.NFTID = metadata.ID
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		applied = true
+
+		err = tx.Model(&models.NFTTransfer{}).
+			Where("nft_id = ? AND block_number > ?", metadata.ID, transfer.BlockNumber).
+			Count(&count).Error
+		if err != nil || count > 0 {
+			return err
+		}
+		return tx.Model(&metadata).Update("owner_addr", transfer.ToAddr).Error
+	})
+	return applied, err
+}
+
+// RevertTransfersFrom deletes the transfers included in blockNumber or later,
+// except mints, and moves the affected tokens back to the recipient of their
+// latest remaining transfer. It returns the number of transfers deleted.
+func (r *NFTRepository) RevertTransfersFrom(blockNumber uint64) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		reverted := tx.Model(&models.NFTTransfer{}).
+			Where("block_number >= ? AND from_addr <> ?", blockNumber, models.ZeroAddress)
+
+		var nftIDs []uint
+		if err := reverted.Distinct("nft_id").Pluck("nft_id", &nftIDs).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("block_number >= ? AND from_addr <> ?", blockNumber, models.ZeroAddress).
+			Delete(&models.NFTTransfer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		for _, nftID := range nftIDs {
+			var latest models.NFTTransfer
+			if err := tx.Where("nft_id = ?", nftID).Order("block_number DESC, id DESC").First(&latest).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.NFTMetadata{}).Where("id = ?", nftID).Update("owner_addr", latest.ToAddr).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -46,6 +46,13 @@ func (r *PaperRepository) GetByOwnerID(ownerID uint, limit, offset int) ([]model
 	return papers, err
 }
 
+// GetByOwnerIDs returns every paper of the given owners
+func (r *PaperRepository) GetByOwnerIDs(ownerIDs []uint) ([]models.Paper, error) {
+	var papers []models.Paper
+	err := r.db.Where("owner_id IN ?", ownerIDs).Find(&papers).Error
+	return papers, err
+}
+
 func (r *PaperRepository) GetByStatus(status string, limit, offset int) ([]models.Paper, error) {
 	var papers []models.Paper
 	err := r.db.Where("status = ?", status).Preload("Owner").Limit(limit).Offset(offset).Find(&papers).Error
@@ -59,16 +66,3 @@ func (r *PaperRepository) Search(query string, limit, offset int) ([]models.Pape
 		Preload("Owner").Limit(limit).Offset(offset).Find(&papers).Error
 	return papers, err
 }
-
-func (r *PaperRepository) GetPendingReviews(reviewerID uint, limit, offset int) ([]models.Paper, error) {
-	var papers []models.Paper
-
-	// Get papers that are submitted or under_review
-	// and exclude papers already reviewed by this reviewer
-	subQuery := r.db.Select("paper_id").Where("reviewer_id = ?", reviewerID).Table("reviews")
-
-	err := r.db.Where("status IN (?, ?) AND id NOT IN (?)", "submitted", "under_review", subQuery).
-		Preload("Owner").Limit(limit).Offset(offset).Find(&papers).Error
-
-	return papers, err
-}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReviewAssignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewAssignmentRepository(db *gorm.DB) *ReviewAssignmentRepository {
+	return &ReviewAssignmentRepository{db: db}
+}
+
+func (r *ReviewAssignmentRepository) Create(assignment *models.ReviewAssignment) error {
+	return r.db.Create(assignment).Error
+}
+
+func (r *ReviewAssignmentRepository) GetByID(id uint) (*models.ReviewAssignment, error) {
+	var assignment models.ReviewAssignment
+	err := r.db.Preload("Paper").Preload("Reviewer").First(&assignment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// GetOpen returns the invited, accepted or completed assignment of a reviewer
+// to a paper, or nil if there is none
+func (r *ReviewAssignmentRepository) GetOpen(paperID, reviewerID uint) (*models.ReviewAssignment, error) {
+	var assignment models.ReviewAssignment
+	err := r.db.Where("paper_id = ? AND reviewer_id = ? AND status IN ?", paperID, reviewerID,
+		[]string{models.AssignmentStatusInvited, models.AssignmentStatusAccepted, models.AssignmentStatusCompleted}).
+		First(&assignment).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ListByPaper returns every assignment of a paper, oldest first
+func (r *ReviewAssignmentRepository) ListByPaper(paperID uint) ([]models.ReviewAssignment, error) {
+	var assignments []models.ReviewAssignment
+	err := r.db.Where("paper_id = ?", paperID).
+		Preload("Reviewer").Order("created_at, id").Find(&assignments).Error
+	return assignments, err
+}
+
+// ListByReviewer returns the assignments of a reviewer, optionally only those
+// with status, soonest due first
+func (r *ReviewAssignmentRepository) ListByReviewer(reviewerID uint, status string, limit, offset int) ([]models.ReviewAssignment, error) {
+	var assignments []models.ReviewAssignment
+	query := r.db.Where("reviewer_id = ?", reviewerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Preload("Paper").Order("due_at, id").
+		Limit(limit).Offset(offset).Find(&assignments).Error
+	return assignments, err
+}
+
+// CountOpenByReviewers counts the invited and accepted assignments of each of
+// reviewerIDs; reviewers without any are missing from the result
+func (r *ReviewAssignmentRepository) CountOpenByReviewers(reviewerIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		ReviewerID uint
+		Count      int
+	}
+	err := r.db.Model(&models.ReviewAssignment{}).
+		Select("reviewer_id, COUNT(*) AS count").
+		Where("reviewer_id IN ? AND status IN ?", reviewerIDs,
+			[]string{models.AssignmentStatusInvited, models.AssignmentStatusAccepted}).
+		Group("reviewer_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.ReviewerID] = row.Count
+	}
+	return counts, nil
+}
+
+// Respond moves an assignment of a reviewer from one of from to status. It
+// reports false if the reviewer has no such assignment in one of from.
+func (r *ReviewAssignmentRepository) Respond(id, reviewerID uint, from []string, status, reason string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.ReviewAssignment{}).
+		Where("id = ? AND reviewer_id = ? AND status IN ?", id, reviewerID, from).
+		Updates(map[string]interface{}{"status": status, "decline_reason": reason, "responded_at": at})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Cancel cancels an invited or accepted assignment. It reports false if the
+// assignment is in any other status.
+func (r *ReviewAssignmentRepository) Cancel(id uint) (bool, error) {
+	result := r.db.Model(&models.ReviewAssignment{}).
+		Where("id = ? AND status IN ?", id, []string{models.AssignmentStatusInvited, models.AssignmentStatusAccepted}).
+		Update("status", models.AssignmentStatusCancelled)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Reopen returns the assignment fulfilled by a deleted review to accepted, so
+// the reviewer can submit a new one
+func (r *ReviewAssignmentRepository) Reopen(reviewID uint) error {
+	return r.db.Model(&models.ReviewAssignment{}).
+		Where("review_id = ? AND status = ?", reviewID, models.AssignmentStatusCompleted).
+		Updates(map[string]interface{}{"status": models.AssignmentStatusAccepted, "review_id": nil}).Error
+}
+
+// ReopenCompleted returns the completed assignments of a paper fulfilled by a
+// review of an earlier round than round to accepted, keeping the review, so
+// their reviewers review the revised paper by updating their reviews
+func (r *ReviewAssignmentRepository) ReopenCompleted(paperID uint, round int) error {
+	earlier := r.db.Model(&models.Review{}).Select("id").Where("paper_id = ? AND round < ?", paperID, round)
+	return r.db.Model(&models.ReviewAssignment{}).
+		Where("paper_id = ? AND status = ? AND review_id IN (?)", paperID, models.AssignmentStatusCompleted, earlier).
+		Update("status", models.AssignmentStatusAccepted).Error
+}
@@ -1,10 +1,16 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errAssignmentClosed rolls back a review submitted for an assignment that is no longer accepted
+var errAssignmentClosed = errors.New("assignment is no longer accepted")
+
 type ReviewRepository struct {
 	db *gorm.DB
 }
@@ -13,8 +19,56 @@ func NewReviewRepository(db *gorm.DB) *ReviewRepository {
 	return &ReviewRepository{db: db}
 }
 
-func (r *ReviewRepository) Create(review *models.Review) error {
-	return r.db.Create(review).Error
+// Submit stores a review fulfilling an accepted assignment, completes the
+// assignment and moves the review's paper from status to next, in a single
+// transaction. It reports false, storing nothing, if the assignment is no
+// longer accepted.
+func (r *ReviewRepository) Submit(review *models.Review, assignmentID uint, status, next string) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(review).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.ReviewAssignment{}).
+			Where("id = ? AND status = ?", assignmentID, models.AssignmentStatusAccepted).
+			Updates(map[string]interface{}{"status": models.AssignmentStatusCompleted, "review_id": review.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errAssignmentClosed
+		}
+		return movePaper(tx, review.PaperID, status, next)
+	})
+	if errors.Is(err, errAssignmentClosed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Revise saves a review, completes the reopened assignment of its reviewer if
+// there is one, and moves its paper from status to next, in a single transaction
+func (r *ReviewRepository) Revise(review *models.Review, status, next string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(review).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.ReviewAssignment{}).
+			Where("paper_id = ? AND reviewer_id = ? AND status = ?", review.PaperID, review.ReviewerID, models.AssignmentStatusAccepted).
+			Updates(map[string]interface{}{"status": models.AssignmentStatusCompleted, "review_id": review.ID}).Error
+		if err != nil {
+			return err
+		}
+		return movePaper(tx, review.PaperID, status, next)
+	})
+}
+
+// movePaper moves a paper still in status to next, unless they are the same
+func movePaper(tx *gorm.DB, paperID uint, status, next string) error {
+	if status == next {
+		return nil
+	}
+	return tx.Model(&models.Paper{}).Where("id = ? AND status = ?", paperID, status).
+		Update("status", next).Error
 }
 
 func (r *ReviewRepository) GetByID(id uint) (*models.Review, error) {
@@ -26,8 +80,9 @@ func (r *ReviewRepository) GetByID(id uint) (*models.Review, error) {
 	return &review, nil
 }
 
-func (r *ReviewRepository) Update(review *models.Review) error {
-	return r.db.Save(review).Error
+// SetIPFSHash records the CID of a review's pinned metadata
+func (r *ReviewRepository) SetIPFSHash(id uint, cid string) error {
+	return r.db.Model(&models.Review{}).Where("id = ?", id).Update("IPFSHash", cid).Error
 }
 
 func (r *ReviewRepository) Delete(id uint) error {
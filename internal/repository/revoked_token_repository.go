@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Create denylists a token; denylisting it again is a no-op
+func (r *RevokedTokenRepository) Create(token *models.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// Get returns the denylist entry of a token, or gorm.ErrRecordNotFound if it is not revoked
+func (r *RevokedTokenRepository) Get(jti string) (*models.RevokedToken, error) {
+	var token models.RevokedToken
+	err := r.db.Where("jti = ?", jti).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteExpired removes the entries of tokens that expired before now
+func (r *RevokedTokenRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error
+}
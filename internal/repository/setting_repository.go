@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository struct {
+	db *gorm.DB
+}
+
+func NewSettingRepository(db *gorm.DB) *SettingRepository {
+	return &SettingRepository{db: db}
+}
+
+// Get returns the value of a setting, or "" if it was never set
+func (r *SettingRepository) Get(key string) (string, error) {
+	var setting models.Setting
+	err := r.db.Where("key = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+// ListByPrefix returns every setting whose key starts with prefix
+func (r *SettingRepository) ListByPrefix(prefix string) ([]models.Setting, error) {
+	var settings []models.Setting
+	err := r.db.Where("key LIKE ?", prefix+"%").Order("key").Find(&settings).Error
+	return settings, err
+}
+
+// Delete removes a setting, restoring its default
+func (r *SettingRepository) Delete(key string) error {
+	return r.db.Where("key = ?", key).Delete(&models.Setting{}).Error
+}
+
+// Set creates or replaces the value of a setting
+func (r *SettingRepository) Set(key, value string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.Setting{Key: key, Value: value}).Error
+}
+
+// SetVersioned increments the integer setting counter, counting from floor if
+// it is unset or lower, and sets key to the value built for the new count, in
+// a single transaction. The counter stays locked until the transaction ends,
+// so concurrent callers never build values for the same count.
+func (r *SettingRepository) SetVersioned(counter string, floor int, key string, build func(version int) (string, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Make sure there is a counter row to lock
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Setting{Key: counter, Value: strconv.Itoa(floor)}).Error
+		if err != nil {
+			return err
+		}
+
+		var last models.Setting
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", counter).First(&last).Error
+		if err != nil {
+			return err
+		}
+		version, err := strconv.Atoi(last.Value)
+		if err != nil {
+			version = floor
+		}
+		version = max(version, floor) + 1
+
+		value, err := build(version)
+		if err != nil {
+			return err
+		}
+		repo := &SettingRepository{db: tx}
+		if err := repo.Set(counter, strconv.Itoa(version)); err != nil {
+			return err
+		}
+		return repo.Set(key, value)
+	})
+}
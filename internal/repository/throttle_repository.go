@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ThrottleRepository stores failure counters. Counters are changed with
+// single UPDATE statements so concurrent failures are all counted.
+type ThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewThrottleRepository(db *gorm.DB) *ThrottleRepository {
+	return &ThrottleRepository{db: db}
+}
+
+// Get returns the state of a subject, or gorm.ErrRecordNotFound if it never failed
+func (r *ThrottleRepository) Get(subject string) (*models.ThrottleState, error) {
+	var state models.ThrottleState
+	err := r.db.Where("subject = ?", subject).First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// AddFailure counts a failure of subject at time at. Failures before
+// staleBefore, and lockouts that ended before at, are forgotten first.
+func (r *ThrottleRepository) AddFailure(subject string, at, staleBefore time.Time) (*models.ThrottleState, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ThrottleState{Subject: subject, LastFailureAt: at}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.ThrottleState{}).
+			Where("subject = ? AND (last_failure_at < ? OR locked_until < ?)", subject, staleBefore, at).
+			Updates(map[string]interface{}{"failures": 0, "locked_until": nil}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.ThrottleState{}).
+			Where("subject = ?", subject).
+			Updates(map[string]interface{}{"failures": gorm.Expr("failures + 1"), "last_failure_at": at}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(subject)
+}
+
+// Lock locks subject out until until if it has at least maxFailures failures
+// and is not locked yet. It reports whether the subject was locked.
+func (r *ThrottleRepository) Lock(subject string, maxFailures int, until time.Time) (bool, error) {
+	result := r.db.Model(&models.ThrottleState{}).
+		Where("subject = ? AND failures >= ? AND locked_until IS NULL", subject, maxFailures).
+		Update("locked_until", until)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Delete forgets the failures and lockout of subject
+func (r *ThrottleRepository) Delete(subject string) error {
+	return r.db.Where("subject = ?", subject).Delete(&models.ThrottleState{}).Error
+}
+
+// DeleteStale removes the states whose last failure and lockout ended before before
+func (r *ThrottleRepository) DeleteStale(before time.Time) error {
+	return r.db.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&models.ThrottleState{}).Error
+}
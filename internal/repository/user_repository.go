@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -35,15 +38,64 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByWalletAddress finds the user a wallet is linked to, ignoring the address checksum casing
 func (r *UserRepository) GetByWalletAddress(walletAddr string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("wallet_addr = ?", walletAddr).First(&user).Error
+	err := r.db.Where("LOWER(wallet_addr) = LOWER(?)", walletAddr).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// SetWalletAddress links a wallet to a user, or unlinks it if addr is nil. Linking
+// a wallet that belongs to another user fails with gorm.ErrDuplicatedKey.
+func (r *UserRepository) SetWalletAddress(userID uint, addr *string) error {
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Update("wallet_addr", addr).Error
+	return translateError(r.db, err)
+}
+
+// SetEmailVerified records that a user proved control of their email address
+func (r *UserRepository) SetEmailVerified(userID uint, at time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", at).Error
+}
+
+// SetPassword replaces the password hash of a user
+func (r *UserRepository) SetPassword(userID uint, passwordHash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password", passwordHash).Error
+}
+
+// SetTOTPSecret stores the TOTP secret of a user setting up two-factor authentication
+func (r *UserRepository) SetTOTPSecret(userID uint, secret string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error
+}
+
+// EnableTOTP requires TOTP codes at login from a user whose secret is set up
+func (r *UserRepository) EnableTOTP(userID uint, at time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND totp_secret <> ''", userID).
+		Update("totp_enabled_at", at).Error
+}
+
+// DisableTOTP turns off two-factor authentication and forgets the secret of a user
+func (r *UserRepository) DisableTOTP(userID uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error
+}
+
+// ClaimTOTPStep records that a code of time step step was accepted. It reports
+// false if a code of that or a later step was accepted before, so each code
+// is accepted once.
+func (r *UserRepository) ClaimTOTPStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -57,3 +109,35 @@ func (r *UserRepository) List(limit, offset int) ([]models.User, error) {
 	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
+
+// ListByRoles returns every user with one of roles
+func (r *UserRepository) ListByRoles(roles []string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("role IN ?", roles).Order("id").Find(&users).Error
+	return users, err
+}
+
+// SetInterests replaces the declared research interests of a user
+func (r *UserRepository) SetInterests(userID uint, interests datatypes.JSON) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).
+		Update("interests", interests).Error
+}
+
+// CountByRole counts the users with a role
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// translateError maps driver errors, such as unique constraint violations, to
+// the matching gorm errors
+func translateError(db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return translator.Translate(err)
+	}
+	return err
+}
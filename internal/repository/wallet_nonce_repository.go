@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+type WalletNonceRepository struct {
+	db *gorm.DB
+}
+
+func NewWalletNonceRepository(db *gorm.DB) *WalletNonceRepository {
+	return &WalletNonceRepository{db: db}
+}
+
+func (r *WalletNonceRepository) Create(nonce *models.WalletNonce) error {
+	return r.db.Create(nonce).Error
+}
+
+func (r *WalletNonceRepository) GetByNonce(nonce string) (*models.WalletNonce, error) {
+	var record models.WalletNonce
+	err := r.db.Where("nonce = ?", nonce).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Consume marks an unexpired, unused nonce issued to userID for purpose as used.
+// It reports false if there is no such nonce, so each nonce is accepted once.
+func (r *WalletNonceRepository) Consume(nonce, purpose string, userID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.WalletNonce{}).
+		Where("nonce = ? AND purpose = ? AND user_id = ? AND used_at IS NULL AND expires_at > ?", nonce, purpose, userID, at).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteExpired removes nonces that expired before now
+func (r *WalletNonceRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.WalletNonce{}).Error
+}
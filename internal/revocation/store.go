@@ -0,0 +1,126 @@
+package revocation
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Store is the access token denylist. Revocations are persisted so they
+// survive restarts and are shared between instances; lookups are served from
+// an LRU cache. A token found revoked stays cached as revoked, while a token
+// found valid is re-checked against the database after NegativeTTL so
+// revocations made by other instances are picked up.
+type Store struct {
+	repo *repository.RevokedTokenRepository
+
+	// NegativeTTL bounds how long a token is trusted as not revoked without
+	// asking the database
+	NegativeTTL time.Duration
+
+	mu    sync.Mutex
+	size  int
+	order *list.List // Most recently used first
+	items map[string]*list.Element
+}
+
+type entry struct {
+	jti     string
+	revoked bool
+	until   time.Time // When the entry must be looked up again
+}
+
+// NewStore creates a denylist caching up to size lookups
+func NewStore(repo *repository.RevokedTokenRepository, size int) *Store {
+	if size <= 0 {
+		size = 10000
+	}
+	return &Store{
+		repo:        repo,
+		NegativeTTL: 30 * time.Second,
+		size:        size,
+		order:       list.New(),
+		items:       make(map[string]*list.Element),
+	}
+}
+
+// Revoke denylists the token with the given ID until it expires
+func (s *Store) Revoke(jti string, expiresAt time.Time) error {
+	if jti == "" || !expiresAt.After(time.Now()) {
+		return nil
+	}
+
+	if err := s.repo.Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}); err != nil {
+		return err
+	}
+	s.put(jti, true, expiresAt)
+
+	// Revocations are rare, so expired entries are pruned along the way
+	return s.repo.DeleteExpired(time.Now())
+}
+
+// IsRevoked reports whether the token with the given ID has been revoked
+func (s *Store) IsRevoked(jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	if revoked, ok := s.get(jti); ok {
+		return revoked, nil
+	}
+
+	token, err := s.repo.Get(jti)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.put(jti, false, time.Now().Add(s.NegativeTTL))
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.put(jti, true, token.ExpiresAt)
+	return true, nil
+}
+
+func (s *Store) get(jti string) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[jti]
+	if !ok {
+		return false, false
+	}
+	e := elem.Value.(*entry)
+	if time.Now().After(e.until) {
+		s.order.Remove(elem)
+		delete(s.items, jti)
+		return false, false
+	}
+
+	s.order.MoveToFront(elem)
+	return e.revoked, true
+}
+
+func (s *Store) put(jti string, revoked bool, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[jti]; ok {
+		e := elem.Value.(*entry)
+		e.revoked, e.until = revoked, until
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.items[jti] = s.order.PushFront(&entry{jti: jti, revoked: revoked, until: until})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*entry).jti)
+	}
+}
@@ -0,0 +1,74 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestStore(t *testing.T, size int) (*Store, *repository.RevokedTokenRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.RevokedToken{}))
+
+	repo := repository.NewRevokedTokenRepository(db)
+	return NewStore(repo, size), repo
+}
+
+func TestStoreRevoke(t *testing.T) {
+	store, _ := newTestStore(t, 10)
+
+	revoked, err := store.IsRevoked("a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.Revoke("a", time.Now().Add(time.Minute)))
+	revoked, err = store.IsRevoked("a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Tokens that already expired need no entry
+	require.NoError(t, store.Revoke("b", time.Now().Add(-time.Minute)))
+	revoked, err = store.IsRevoked("b")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestStoreSharesRevocationsThroughDatabase(t *testing.T) {
+	store, repo := newTestStore(t, 10)
+	other := NewStore(repo, 10)
+
+	// The valid lookup is cached until NegativeTTL passes
+	revoked, err := store.IsRevoked("a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, other.Revoke("a", time.Now().Add(time.Minute)))
+	revoked, err = store.IsRevoked("a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	store.NegativeTTL = 0
+	store.put("a", false, time.Now().Add(-time.Second))
+	revoked, err = store.IsRevoked("a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Evicted entries are looked up again
+	small := NewStore(repo, 1)
+	revoked, err = small.IsRevoked("a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	_, err = small.IsRevoked("c")
+	require.NoError(t, err)
+	assert.Equal(t, 1, small.order.Len())
+	revoked, err = small.IsRevoked("a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
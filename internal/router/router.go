@@ -2,64 +2,290 @@ package router
 
 import (
 	"net/http"
-	"strings"
+	"net/netip"
+	"sort"
 
+	"github.com/nshmdayo/nft-platform-sample/internal/apikey"
 	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/internal/errors"
 	"github.com/nshmdayo/nft-platform-sample/internal/handlers"
 	"github.com/nshmdayo/nft-platform-sample/internal/middleware"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/revocation"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
 )
 
+// Route is a single entry of the route table
+type Route struct {
+	Method       string
+	Path         string
+	Public       bool
+	OptionalAuth bool                  // Public, but authenticated when a token is presented
+	MFASetup     bool                  // Usable by users who still have to set up required two-factor authentication
+	Permission   middleware.Permission // Required of the user's role, if set
+	Scope        string                // Required of API keys; routes without one reject API keys
+	Handler      http.HandlerFunc
+}
+
+// Pattern returns the http.ServeMux pattern for the route, e.g. "GET /api/v1/papers/{id}"
+func (rt Route) Pattern() string {
+	return rt.Method + " " + rt.Path
+}
+
+// RouteInfo is the debug representation of a route
+type RouteInfo struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Public     bool   `json:"public"`
+	Permission string `json:"permission,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+}
+
 type Router struct {
-	cfg          *config.Config
-	routeHandler *handlers.RouteHandler
+	handlers.BaseHandler
+	cfg            *config.Config
+	trustedProxies []netip.Prefix // Proxies whose X-Forwarded-For is believed
+	tokens         utils.JWTOptions
+	revocations    *revocation.Store
+	apiKeys        *apikey.Verifier
+	routeHandler   *handlers.RouteHandler
 }
 
 func NewRouter(
 	cfg *config.Config,
+	trustedProxies []netip.Prefix,
+	tokens utils.JWTOptions,
+	revocations *revocation.Store,
+	apiKeys *apikey.Verifier,
 	authHandler *handlers.AuthHandler,
 	paperHandler *handlers.PaperHandler,
 	reviewHandler *handlers.ReviewHandler,
+	nftHandler *handlers.NFTHandler,
+	adminHandler *handlers.AdminHandler,
 ) *Router {
 	return &Router{
-		cfg:          cfg,
-		routeHandler: handlers.NewRouteHandler(authHandler, paperHandler, reviewHandler),
+		cfg:            cfg,
+		trustedProxies: trustedProxies,
+		tokens:         tokens,
+		revocations:    revocations,
+		apiKeys:        apiKeys,
+		routeHandler:   handlers.NewRouteHandler(authHandler, paperHandler, reviewHandler, nftHandler, adminHandler, handlers.NewJWKSHandler(tokens.Keys)),
 	}
 }
 
-func (r *Router) SetupRoutes() http.Handler {
-	mux := http.NewServeMux()
+// Routes returns the route table served by the API
+func (r *Router) Routes() []Route {
+	h := r.routeHandler
 
-	// Health check
-	mux.HandleFunc("/health", r.routeHandler.HealthHandler.Health)
+	routes := []Route{
+		// Health check
+		{Method: http.MethodGet, Path: "/health", Public: true, Handler: h.HealthHandler.Health},
 
-	// Auth routes (public)
-	mux.HandleFunc("/api/v1/auth/register", r.routeHandler.AuthHandler.Register)
-	mux.HandleFunc("/api/v1/auth/login", r.routeHandler.AuthHandler.Login)
+		// Public keys verifying access tokens
+		{Method: http.MethodGet, Path: "/.well-known/jwks.json", Public: true, Handler: h.JWKSHandler.JWKS},
 
-	// Protected routes
-	authMiddleware := middleware.AuthMiddleware(r.cfg)
+		// Auth routes
+		{Method: http.MethodPost, Path: "/api/v1/auth/register", Public: true, Handler: h.AuthHandler.Register},
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Public: true, Handler: h.AuthHandler.Login},
+		{Method: http.MethodPost, Path: "/api/v1/auth/refresh", Public: true, Handler: h.AuthHandler.Refresh},
+		{Method: http.MethodPost, Path: "/api/v1/auth/logout", Public: true, Handler: h.AuthHandler.Logout},
+		{Method: http.MethodPost, Path: "/api/v1/auth/verify-email", Public: true, Handler: h.AuthHandler.VerifyEmail},
+		{Method: http.MethodPost, Path: "/api/v1/auth/verify-email/resend", Handler: h.AuthHandler.ResendVerificationEmail},
+		{Method: http.MethodPost, Path: "/api/v1/auth/forgot-password", Public: true, Handler: h.AuthHandler.ForgotPassword},
+		{Method: http.MethodPost, Path: "/api/v1/auth/reset-password", Public: true, Handler: h.AuthHandler.ResetPassword},
+		{Method: http.MethodGet, Path: "/api/v1/auth/siwe/nonce", Public: true, Handler: h.AuthHandler.SIWENonce},
+		{Method: http.MethodPost, Path: "/api/v1/auth/siwe/verify", Public: true, OptionalAuth: true, Handler: h.AuthHandler.SIWEVerify},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/verify", Public: true, Handler: h.AuthHandler.VerifyMFA},
+		{Method: http.MethodGet, Path: "/api/v1/auth/2fa", MFASetup: true, Handler: h.AuthHandler.TwoFactorStatus},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/setup", MFASetup: true, Handler: h.AuthHandler.SetupTOTP},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/enable", MFASetup: true, Handler: h.AuthHandler.EnableTOTP},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/disable", Handler: h.AuthHandler.DisableTOTP},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/recovery-codes", Handler: h.AuthHandler.RegenerateRecoveryCodes},
+		{Method: http.MethodGet, Path: "/api/v1/auth/profile", MFASetup: true, Handler: h.AuthHandler.GetProfile},
+		{Method: http.MethodPut, Path: "/api/v1/auth/profile/interests", Handler: h.AuthHandler.UpdateInterests},
+		{Method: http.MethodGet, Path: "/api/v1/auth/wallet/challenge", Handler: h.AuthHandler.WalletChallenge},
+		{Method: http.MethodPost, Path: "/api/v1/auth/wallet", Handler: h.AuthHandler.LinkWallet},
+		{Method: http.MethodDelete, Path: "/api/v1/auth/wallet", Handler: h.AuthHandler.UnlinkWallet},
+		{Method: http.MethodGet, Path: "/api/v1/auth/sessions", MFASetup: true, Handler: h.AuthHandler.ListSessions},
+		{Method: http.MethodDelete, Path: "/api/v1/auth/sessions/{id}", MFASetup: true, Handler: h.AuthHandler.RevokeSession},
+		{Method: http.MethodPost, Path: "/api/v1/auth/api-keys", Handler: h.AuthHandler.CreateAPIKey},
+		{Method: http.MethodGet, Path: "/api/v1/auth/api-keys", Handler: h.AuthHandler.ListAPIKeys},
+		{Method: http.MethodDelete, Path: "/api/v1/auth/api-keys/{id}", Handler: h.AuthHandler.RevokeAPIKey},
 
-	// Auth protected routes
-	mux.Handle("/api/v1/auth/profile", authMiddleware(http.HandlerFunc(r.routeHandler.AuthHandler.GetProfile)))
+		// Admin routes
+		{Method: http.MethodGet, Path: "/api/v1/admin/users", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.ListUsers},
+		{Method: http.MethodPut, Path: "/api/v1/admin/users/{id}/role", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.ChangeUserRole},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/{id}/sessions", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.RevokeUserSessions},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/{id}/unlock", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.UnlockUser},
+		{Method: http.MethodPost, Path: "/api/v1/admin/ips/{ip}/unlock", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.UnlockIP},
+		{Method: http.MethodGet, Path: "/api/v1/admin/login-failures", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.ListLoginFailures},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/{id}/2fa", Permission: middleware.PermUsersManage, Handler: h.AdminHandler.ResetUserTwoFactor},
+		{Method: http.MethodGet, Path: "/api/v1/admin/settings/2fa", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.GetTwoFactorPolicy},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/2fa", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetTwoFactorPolicy},
+		{Method: http.MethodGet, Path: "/api/v1/admin/settings/review-policy", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.GetReviewPolicies},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/review-policy", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetReviewPolicy},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/review-policy/categories/{category}", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetCategoryReviewPolicy},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/settings/review-policy/categories/{category}", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.DeleteCategoryReviewPolicy},
+		{Method: http.MethodGet, Path: "/api/v1/admin/settings/review-rubric", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.GetReviewRubrics},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/review-rubric", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetReviewRubric},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/review-rubric/categories/{category}", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetCategoryReviewRubric},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/settings/review-rubric/categories/{category}", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.DeleteCategoryReviewRubric},
+		{Method: http.MethodGet, Path: "/api/v1/admin/settings/decision-policy", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.GetDecisionPolicy},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/decision-policy", Permission: middleware.PermSettingsManage, Handler: h.AdminHandler.SetDecisionPolicy},
 
-	// Paper routes
-	mux.Handle("/api/v1/papers/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if strings.Contains(req.URL.Path, "/reviews") {
-			r.routeHandler.HandlePaperReviews(w, req)
-		} else {
-			r.routeHandler.HandlePapers(w, req)
-		}
-	})))
-	mux.Handle("/api/v1/papers/my", authMiddleware(http.HandlerFunc(r.routeHandler.PaperHandler.GetMyPapers)))
+		// Paper routes
+		{Method: http.MethodGet, Path: "/api/v1/papers", Scope: models.ScopePapersRead, Handler: h.PaperHandler.ListPapers},
+		{Method: http.MethodPost, Path: "/api/v1/papers", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.CreatePaper},
+		{Method: http.MethodGet, Path: "/api/v1/papers/my", Scope: models.ScopePapersRead, Handler: h.PaperHandler.GetMyPapers},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}", Scope: models.ScopePapersRead, Handler: h.PaperHandler.GetPaper},
+		{Method: http.MethodPut, Path: "/api/v1/papers/{id}", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.UpdatePaper},
+		{Method: http.MethodDelete, Path: "/api/v1/papers/{id}", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.DeletePaper},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/file", Scope: models.ScopePapersRead, Handler: h.PaperHandler.DownloadFile},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/file", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.UploadFile},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/submit", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.SubmitPaper},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/withdraw", Scope: models.ScopePapersWrite, Handler: h.PaperHandler.WithdrawPaper},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/publish", Permission: middleware.PermPaperDecide, Handler: h.PaperHandler.PublishPaper},
+		{Method: http.MethodPut, Path: "/api/v1/papers/{id}/review-policy", Permission: middleware.PermPaperDecide, Handler: h.PaperHandler.SetReviewPolicy},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/reviews", Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetPaperReviews},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/rubric", Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetPaperRubric},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/assignments", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.AssignReviewer},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/assignments", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.GetPaperAssignments},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/reviewer-suggestions", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.GetReviewerSuggestions},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/decision", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.GetDecisionDraft},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/decision", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.ConfirmDecision},
+		{Method: http.MethodGet, Path: "/api/v1/papers/{id}/decisions", Scope: models.ScopePapersRead, Handler: h.ReviewHandler.ListDecisions},
+		{Method: http.MethodPost, Path: "/api/v1/papers/{id}/mint", Permission: middleware.PermNFTMint, Handler: h.NFTHandler.MintPaper},
 
-	// Review routes
-	mux.Handle("/api/v1/reviews/", authMiddleware(http.HandlerFunc(r.routeHandler.HandleReviews)))
-	mux.Handle("/api/v1/reviews/my", authMiddleware(http.HandlerFunc(r.routeHandler.ReviewHandler.GetMyReviews)))
-	mux.Handle("/api/v1/reviews/pending", authMiddleware(http.HandlerFunc(r.routeHandler.ReviewHandler.GetPendingReviews)))
+		// Review routes
+		{Method: http.MethodPost, Path: "/api/v1/reviews", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsWrite, Handler: h.ReviewHandler.CreateReview},
+		{Method: http.MethodGet, Path: "/api/v1/reviews/my", Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetMyReviews},
+		{Method: http.MethodGet, Path: "/api/v1/reviews/pending", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetPendingReviews},
+		{Method: http.MethodGet, Path: "/api/v1/reviews/{id}", Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetReview},
+		{Method: http.MethodPut, Path: "/api/v1/reviews/{id}", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsWrite, Handler: h.ReviewHandler.UpdateReview},
+		{Method: http.MethodDelete, Path: "/api/v1/reviews/{id}", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsWrite, Handler: h.ReviewHandler.DeleteReview},
+		{Method: http.MethodPost, Path: "/api/v1/reviews/{id}/mint", Permission: middleware.PermNFTMint, Handler: h.NFTHandler.MintReview},
+
+		// Review assignment routes
+		{Method: http.MethodGet, Path: "/api/v1/assignments/my", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsRead, Handler: h.ReviewHandler.GetMyAssignments},
+		{Method: http.MethodPost, Path: "/api/v1/assignments/{id}/accept", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsWrite, Handler: h.ReviewHandler.AcceptAssignment},
+		{Method: http.MethodPost, Path: "/api/v1/assignments/{id}/decline", Permission: middleware.PermReviewWrite, Scope: models.ScopeReviewsWrite, Handler: h.ReviewHandler.DeclineAssignment},
+		{Method: http.MethodDelete, Path: "/api/v1/assignments/{id}", Permission: middleware.PermPaperDecide, Handler: h.ReviewHandler.CancelAssignment},
+
+		// NFT routes
+		{Method: http.MethodGet, Path: "/api/v1/mint-jobs/{id}", Scope: models.ScopeNFTsRead, Handler: h.NFTHandler.GetMintJob},
+		{Method: http.MethodGet, Path: "/api/v1/nfts", Scope: models.ScopeNFTsRead, Handler: h.NFTHandler.ListNFTs},
+		{Method: http.MethodPost, Path: "/api/v1/nfts/transfer", Handler: h.NFTHandler.TransferNFT},
+		{Method: http.MethodGet, Path: "/api/v1/nfts/{tokenId}", Scope: models.ScopeNFTsRead, Handler: h.NFTHandler.GetNFT},
+		{Method: http.MethodGet, Path: "/api/v1/nfts/{tokenId}/transfers", Scope: models.ScopeNFTsRead, Handler: h.NFTHandler.GetNFTTransfers},
+		{Method: http.MethodGet, Path: "/api/v1/nfts/{tokenId}/metadata", Public: true, Handler: h.NFTHandler.GetTokenMetadata},
+		{Method: http.MethodGet, Path: "/api/v1/users/{id}/nfts", Scope: models.ScopeNFTsRead, Handler: h.NFTHandler.GetUserNFTs},
+	}
+
+	// Route dump for debugging, never exposed in production
+	if r.cfg.App.Environment == "development" {
+		routes = append(routes, Route{Method: http.MethodGet, Path: "/debug/routes", Public: true, Handler: r.dumpRoutes})
+	}
+
+	return routes
+}
+
+func (r *Router) SetupRoutes() http.Handler {
+	mux := http.NewServeMux()
+	authMiddleware := middleware.AuthMiddleware(r.tokens, r.revocations, r.apiKeys)
+	optionalAuthMiddleware := middleware.OptionalAuthMiddleware(r.tokens, r.revocations, r.apiKeys)
+	mfaSetupMiddleware := middleware.RequireMFASetupComplete()
+
+	for _, route := range r.Routes() {
+		var handler http.Handler = route.Handler
+		// Wrapped first so it runs after authentication
+		if route.Permission != "" {
+			handler = middleware.RequirePermission(route.Permission)(handler)
+		}
+		if !route.Public && !route.MFASetup {
+			handler = mfaSetupMiddleware(handler)
+		}
+		if !route.Public || route.OptionalAuth {
+			handler = middleware.RequireScope(route.Scope)(handler)
+		}
+		switch {
+		case route.OptionalAuth:
+			handler = optionalAuthMiddleware(handler)
+		case !route.Public:
+			handler = authMiddleware(handler)
+		}
+		mux.Handle(route.Pattern(), handler)
+	}
 
 	// Apply global middleware
-	handler := middleware.LoggerMiddleware()(mux)
+	handler := r.fallbackMiddleware(mux)
+	handler = middleware.LoggerMiddleware()(handler)
 	handler = middleware.CORSMiddleware()(handler)
+	handler = middleware.ClientIPMiddleware(r.trustedProxies)(handler)
 
 	return handler
 }
+
+// dumpRoutes lists the registered routes sorted by path and method
+func (r *Router) dumpRoutes(w http.ResponseWriter, req *http.Request) {
+	routes := r.Routes()
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		infos = append(infos, RouteInfo{Method: route.Method, Path: route.Path, Public: route.Public, Permission: string(route.Permission), Scope: route.Scope})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		return infos[i].Method < infos[j].Method
+	})
+
+	r.SendResponse(w, http.StatusOK, infos)
+}
+
+// fallbackMiddleware renders the mux's own 404 and 405 responses in the standard
+// JSON error envelope. The Allow header set by the mux on 405 is preserved.
+func (r *Router) fallbackMiddleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, pattern := mux.Handler(req); pattern != "" {
+			mux.ServeHTTP(w, req)
+			return
+		}
+		mux.ServeHTTP(&fallbackWriter{ResponseWriter: w, router: r}, req)
+	})
+}
+
+// fallbackWriter replaces the plain-text body the mux writes for unmatched requests
+type fallbackWriter struct {
+	http.ResponseWriter
+	router  *Router
+	handled bool
+}
+
+func (fw *fallbackWriter) WriteHeader(code int) {
+	if fw.handled {
+		return
+	}
+	fw.handled = true
+
+	switch code {
+	case http.StatusMethodNotAllowed:
+		fw.router.SendError(fw.ResponseWriter, errors.MethodNotAllowed())
+	case http.StatusNotFound:
+		fw.router.SendError(fw.ResponseWriter, errors.NotFound("Route"))
+	default:
+		fw.handled = false
+		fw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (fw *fallbackWriter) Write(b []byte) (int, error) {
+	if !fw.handled {
+		fw.WriteHeader(http.StatusOK)
+	}
+	if fw.handled {
+		// Swallow the mux's plain-text body; the JSON error was already written
+		return len(b), nil
+	}
+	return fw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,248 @@
+// Package rubric defines the structured forms reviewers fill in: criteria
+// scored on a common scale and weighted into an overall score, free-text
+// sections, and optional comments only editors read.
+package rubric
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+)
+
+const (
+	maxCriteria      = 20
+	maxSections      = 10
+	maxScale         = 100
+	maxSectionLength = 20000
+	// reviewScoreMin and reviewScoreMax bound Review.Score, which overall
+	// scores are mapped onto so papers compare across rubrics
+	reviewScoreMin = 1
+	reviewScoreMax = 10
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Rubric is the form of a review
+type Rubric struct {
+	Version              int         `json:"version"` // Assigned when the rubric is saved
+	Scale                Scale       `json:"scale"`
+	Criteria             []Criterion `json:"criteria"`
+	Sections             []Section   `json:"sections"`
+	ConfidentialComments bool        `json:"confidential_comments"` // Whether reviewers may leave comments for editors only
+}
+
+// Scale is the inclusive range every criterion is scored in
+type Scale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Criterion is an aspect of the paper the reviewer scores
+type Criterion struct {
+	Key         string  `json:"key"`
+	Label       string  `json:"label"`
+	Description string  `json:"description,omitempty"`
+	Weight      float64 `json:"weight"` // Relative to the other criteria
+}
+
+// Section is a free-text part of the review
+type Section struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Required  bool   `json:"required"`
+	MinLength int    `json:"min_length,omitempty"` // In characters, when given
+}
+
+// Submission is what a reviewer filled in
+type Submission struct {
+	Criteria             map[string]int
+	Sections             map[string]string
+	ConfidentialComments string
+}
+
+// Default is the rubric of papers whose category has none configured
+func Default() Rubric {
+	return Rubric{
+		Version: 1,
+		Scale:   Scale{Min: 1, Max: 10},
+		Criteria: []Criterion{
+			{Key: "originality", Label: "Originality", Weight: 1},
+			{Key: "methodology", Label: "Methodology", Weight: 1},
+			{Key: "clarity", Label: "Clarity", Weight: 1},
+			{Key: "significance", Label: "Significance", Weight: 1},
+		},
+		Sections: []Section{
+			{Key: "summary", Label: "Summary", Required: true},
+			{Key: "strengths", Label: "Strengths"},
+			{Key: "weaknesses", Label: "Weaknesses"},
+		},
+		ConfidentialComments: true,
+	}
+}
+
+// Check reports what makes r unusable as a rubric
+func (r Rubric) Check() validation.ValidationErrors {
+	v := validation.NewValidator()
+
+	if r.Scale.Min < 0 || r.Scale.Max > maxScale || r.Scale.Min >= r.Scale.Max {
+		v.AddError("scale", fmt.Sprintf("must satisfy 0 <= min < max <= %d", maxScale))
+	}
+
+	if len(r.Criteria) == 0 || len(r.Criteria) > maxCriteria {
+		v.AddError("criteria", fmt.Sprintf("must have between 1 and %d entries", maxCriteria))
+	}
+	keys := make(map[string]bool)
+	for i, criterion := range r.Criteria {
+		field := fmt.Sprintf("criteria[%d]", i)
+		checkKey(v, field, criterion.Key, keys)
+		v.Required(field+".label", criterion.Label)
+		if !(criterion.Weight > 0) || math.IsInf(criterion.Weight, 0) {
+			v.AddError(field+".weight", "must be a positive number")
+		}
+	}
+
+	if len(r.Sections) > maxSections {
+		v.AddError("sections", fmt.Sprintf("must have at most %d entries", maxSections))
+	}
+	keys = make(map[string]bool)
+	for i, section := range r.Sections {
+		field := fmt.Sprintf("sections[%d]", i)
+		checkKey(v, field, section.Key, keys)
+		v.Required(field+".label", section.Label)
+		if section.MinLength < 0 || section.MinLength > maxSectionLength {
+			v.AddError(field+".min_length", fmt.Sprintf("must be between 0 and %d", maxSectionLength))
+		}
+	}
+
+	return v.Errors()
+}
+
+func checkKey(v *validation.Validator, field, key string, seen map[string]bool) {
+	switch {
+	case !keyPattern.MatchString(key):
+		v.AddError(field+".key", "must be lowercase letters, digits and underscores, starting with a letter")
+	case seen[key]:
+		v.AddError(field+".key", "is duplicated")
+	}
+	seen[key] = true
+}
+
+// Evaluate checks submission against r and returns the review metadata with
+// the weighted overall score, or what the reviewer must correct
+func (r Rubric) Evaluate(submission Submission, category string) (*models.ReviewMetadata, validation.ValidationErrors) {
+	v := validation.NewValidator()
+
+	metadata := &models.ReviewMetadata{
+		Version:        models.ReviewMetadataVersion,
+		ReviewType:     "peer_review",
+		RubricVersion:  r.Version,
+		RubricCategory: category,
+		Scale:          models.ReviewScale{Min: r.Scale.Min, Max: r.Scale.Max},
+		Criteria:       make([]models.CriterionScore, 0, len(r.Criteria)),
+	}
+
+	var weighted, weights float64
+	for _, criterion := range r.Criteria {
+		field := "criteria." + criterion.Key
+		score, ok := submission.Criteria[criterion.Key]
+		if !ok {
+			v.AddError(field, "is required")
+			continue
+		}
+		if score < r.Scale.Min || score > r.Scale.Max {
+			v.AddError(field, fmt.Sprintf("must be between %d and %d", r.Scale.Min, r.Scale.Max))
+			continue
+		}
+		metadata.Criteria = append(metadata.Criteria, models.CriterionScore{
+			Key:    criterion.Key,
+			Label:  criterion.Label,
+			Weight: criterion.Weight,
+			Score:  score,
+		})
+		weighted += criterion.Weight * float64(score)
+		weights += criterion.Weight
+	}
+	for _, key := range unknownKeys(submission.Criteria, r.criterionKeys()) {
+		v.AddError("criteria."+key, "is not a criterion of this rubric")
+	}
+
+	for _, section := range r.Sections {
+		field := "sections." + section.Key
+		text := strings.TrimSpace(submission.Sections[section.Key])
+		length := utf8.RuneCountInString(text)
+		switch {
+		case text == "" && section.Required:
+			v.AddError(field, "is required")
+		case text == "":
+		case length < section.MinLength:
+			v.AddError(field, fmt.Sprintf("must be at least %d characters", section.MinLength))
+		case length > maxSectionLength:
+			v.AddError(field, fmt.Sprintf("must be at most %d characters", maxSectionLength))
+		default:
+			if metadata.Sections == nil {
+				metadata.Sections = make(map[string]string)
+			}
+			metadata.Sections[section.Key] = text
+		}
+	}
+	for _, key := range unknownKeys(submission.Sections, r.sectionKeys()) {
+		v.AddError("sections."+key, "is not a section of this rubric")
+	}
+
+	if submission.ConfidentialComments != "" {
+		if !r.ConfidentialComments {
+			v.AddError("confidential_comments", "are not accepted by this rubric")
+		}
+		v.MaxLength("confidential_comments", submission.ConfidentialComments, maxSectionLength)
+	}
+
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+	metadata.OverallScore = math.Round(weighted/weights*100) / 100
+	return metadata, nil
+}
+
+// ReviewScore maps an overall score on scale onto the 1-10 review score
+func ReviewScore(overall float64, scale models.ReviewScale) int {
+	if scale.Max <= scale.Min {
+		return reviewScoreMin
+	}
+	share := (overall - float64(scale.Min)) / float64(scale.Max-scale.Min)
+	score := int(math.Round(reviewScoreMin + share*(reviewScoreMax-reviewScoreMin)))
+	return max(reviewScoreMin, min(reviewScoreMax, score))
+}
+
+func (r Rubric) criterionKeys() map[string]bool {
+	keys := make(map[string]bool, len(r.Criteria))
+	for _, criterion := range r.Criteria {
+		keys[criterion.Key] = true
+	}
+	return keys
+}
+
+func (r Rubric) sectionKeys() map[string]bool {
+	keys := make(map[string]bool, len(r.Sections))
+	for _, section := range r.Sections {
+		keys[section.Key] = true
+	}
+	return keys
+}
+
+// unknownKeys returns the keys of submitted that are not in known, sorted
+func unknownKeys[V any](submitted map[string]V, known map[string]bool) []string {
+	var unknown []string
+	for key := range submitted {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,99 @@
+package rubric
+
+import (
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messages returns each error as "field: message"
+func messages(errs validation.ValidationErrors) []string {
+	all := make([]string, 0, len(errs))
+	for _, err := range errs {
+		all = append(all, err.Error())
+	}
+	return all
+}
+
+func TestCheck(t *testing.T) {
+	assert.Empty(t, Default().Check())
+
+	r := Rubric{
+		Scale: Scale{Min: 3, Max: 3},
+		Criteria: []Criterion{
+			{Key: "rigor", Label: "Rigor", Weight: 2},
+			{Key: "rigor", Label: "Rigor again", Weight: 1},
+			{Key: "Bad Key", Label: "", Weight: -1},
+		},
+		Sections: []Section{{Key: "summary", Label: "Summary", MinLength: -1}},
+	}
+
+	errs := r.Check()
+	names := make([]string, 0, len(errs))
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+	assert.Equal(t, []string{
+		"scale",
+		"criteria[1].key",
+		"criteria[2].key",
+		"criteria[2].label",
+		"criteria[2].weight",
+		"sections[0].min_length",
+	}, names)
+}
+
+func TestEvaluate(t *testing.T) {
+	r := Rubric{
+		Version: 4,
+		Scale:   Scale{Min: 0, Max: 4},
+		Criteria: []Criterion{
+			{Key: "rigor", Label: "Rigor", Weight: 2},
+			{Key: "novelty", Label: "Novelty", Weight: 1},
+		},
+		Sections: []Section{
+			{Key: "summary", Label: "Summary", Required: true, MinLength: 5},
+			{Key: "minor", Label: "Minor issues"},
+		},
+	}
+
+	metadata, errs := r.Evaluate(Submission{
+		Criteria: map[string]int{"rigor": 4, "novelty": 1},
+		Sections: map[string]string{"summary": "  Solid proofs.  ", "minor": " "},
+	}, "math")
+	require.Empty(t, errs)
+	assert.Equal(t, models.ReviewMetadataVersion, metadata.Version)
+	assert.Equal(t, 4, metadata.RubricVersion)
+	assert.Equal(t, "math", metadata.RubricCategory)
+	assert.Equal(t, 3.0, metadata.OverallScore)
+	assert.Equal(t, map[string]string{"summary": "Solid proofs."}, metadata.Sections)
+	assert.Equal(t, []models.CriterionScore{
+		{Key: "rigor", Label: "Rigor", Weight: 2, Score: 4},
+		{Key: "novelty", Label: "Novelty", Weight: 1, Score: 1},
+	}, metadata.Criteria)
+
+	_, errs = r.Evaluate(Submission{
+		Criteria:             map[string]int{"rigor": 5, "clarity": 3},
+		Sections:             map[string]string{"summary": "Meh", "extra": "text"},
+		ConfidentialComments: "for the editor",
+	}, "")
+	assert.Equal(t, []string{
+		"criteria.rigor: must be between 0 and 4",
+		"criteria.novelty: is required",
+		"criteria.clarity: is not a criterion of this rubric",
+		"sections.summary: must be at least 5 characters",
+		"sections.extra: is not a section of this rubric",
+		"confidential_comments: are not accepted by this rubric",
+	}, messages(errs))
+}
+
+func TestReviewScore(t *testing.T) {
+	assert.Equal(t, 8, ReviewScore(8, models.ReviewScale{Min: 1, Max: 10}))
+	assert.Equal(t, 1, ReviewScore(1, models.ReviewScale{Min: 1, Max: 5}))
+	assert.Equal(t, 10, ReviewScore(5, models.ReviewScale{Min: 1, Max: 5}))
+	assert.Equal(t, 8, ReviewScore(4.25, models.ReviewScale{Min: 1, Max: 5}))
+	assert.Equal(t, 6, ReviewScore(2, models.ReviewScale{Min: 0, Max: 4}))
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// ListUsers returns a page of users for administration
+func (s *AuthService) ListUsers(page, limit int) ([]models.User, error) {
+	offset := (page - 1) * limit
+	return s.userRepo.List(limit, offset)
+}
+
+// ChangeUserRole sets the role of a user. Access tokens issued with the old
+// role are revoked; the user's sessions stay signed in and pick up the new
+// role on their next refresh.
+func (s *AuthService) ChangeUserRole(userID uint, role string) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	// The platform must keep an admin to manage roles at all
+	if user.Role == models.RoleAdmin {
+		admins, err := s.userRepo.CountByRole(models.RoleAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, apperrors.Conflict("The last admin cannot be demoted")
+		}
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions, err := s.sessionRepo.ListActive(user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if err := s.revocations.Revoke(session.CurrentJTI, session.AccessExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("User role changed", "user_id", user.ID, "from", previous, "to", role, "sessions", len(sessions))
+	return user, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// apiKeyDisplayLength is how much of a key is kept to tell keys apart
+const apiKeyDisplayLength = len(models.APIKeyPrefix) + 8
+
+// CreateAPIKeyRequest describes a new API key
+type CreateAPIKeyRequest struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // nil if the key never expires
+}
+
+// CreateAPIKey issues an API key acting for a user. The key itself is returned
+// only here; it is stored hashed.
+func (s *AuthService) CreateAPIKey(userID uint, req *CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return nil, "", err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", apperrors.BadRequest("expires_at must be in the future")
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := models.APIKeyPrefix + token
+
+	scopes := strings.Join(req.Scopes, " ")
+
+	record := &models.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(record); err != nil {
+		return nil, "", err
+	}
+
+	logger.Info("API key created", "user_id", userID, "key_id", record.ID, "scopes", scopes)
+	return record, key, nil
+}
+
+// ListAPIKeys returns the unrevoked API keys of a user
+func (s *AuthService) ListAPIKeys(userID uint) ([]models.APIKey, error) {
+	return s.apiKeyRepo.ListActive(userID)
+}
+
+// RevokeAPIKey revokes one of a user's API keys
+func (s *AuthService) RevokeAPIKey(userID, keyID uint) error {
+	revoked, err := s.apiKeyRepo.Revoke(userID, keyID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return apperrors.NotFound("API key")
+	}
+
+	logger.Info("API key revoked", "user_id", userID, "key_id", keyID)
+	return nil
+}
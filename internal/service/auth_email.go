@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/mail"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// mailTimeout bounds sending an email from a request
+const mailTimeout = 15 * time.Second
+
+// ResendVerificationEmail sends a new verification link to a user whose email
+// is not verified yet. Links sent before stop working.
+func (s *AuthService) ResendVerificationEmail(userID uint) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return apperrors.Conflict("Email address is already verified")
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// VerifyEmail marks the email of the user a verification token was sent to as verified
+func (s *AuthService) VerifyEmail(token string) (*models.User, error) {
+	record, err := s.consumeEmailToken(token, models.EmailTokenVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetEmailVerified(record.UserID, time.Now()); err != nil {
+		return nil, err
+	}
+	logger.Info("Email verified", "user_id", record.UserID)
+	return s.getUser(record.UserID)
+}
+
+// ForgotPassword emails a password reset link if an account uses email.
+// Unknown addresses are ignored so the endpoint does not reveal which exist.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(s.config.Account.PasswordResetTTL)
+	token, err := s.issueEmailToken(user, models.EmailTokenPasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.sendMail(&mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Someone asked to reset the password of your account. To choose a new password, open:\n\n%s\n\n"+
+			"The link expires in %s and can be used once. If you did not ask for it, ignore this email; "+
+			"your password stays the same.\n",
+			user.Name, s.emailLink("reset-password", token), ttl),
+	})
+}
+
+// ResetPassword sets a new password with a password reset token. Every session
+// and API key of the user is revoked, as the old password may have been
+// compromised.
+func (s *AuthService) ResetPassword(token, password string) error {
+	record, err := s.consumeEmailToken(token, models.EmailTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.SetPassword(record.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.emailTokenRepo.InvalidateForUser(record.UserID, models.EmailTokenPasswordReset, now); err != nil {
+		return err
+	}
+	// Receiving the link proves control of the address as well
+	if err := s.userRepo.SetEmailVerified(record.UserID, now); err != nil {
+		return err
+	}
+
+	revoked, err := s.RevokeAllSessions(record.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.apiKeyRepo.RevokeAllForUser(record.UserID, now); err != nil {
+		return err
+	}
+	logger.Info("Password reset", "user_id", record.UserID, "sessions_revoked", revoked)
+	return nil
+}
+
+// sendVerificationEmail emails user a link verifying their address
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	ttl, _ := time.ParseDuration(s.config.Account.VerificationTTL)
+	token, err := s.issueEmailToken(user, models.EmailTokenVerify, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.sendMail(&mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Please confirm that this is your email address by opening:\n\n%s\n\n"+
+			"The link expires in %s. You need a verified address to submit papers.\n",
+			user.Name, s.emailLink("verify-email", token), ttl),
+	})
+}
+
+// issueEmailToken creates a token for purpose, superseding the user's earlier ones
+func (s *AuthService) issueEmailToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.emailTokenRepo.DeleteExpired(now); err != nil {
+		return "", err
+	}
+	if err := s.emailTokenRepo.InvalidateForUser(user.ID, purpose, now); err != nil {
+		return "", err
+	}
+	err = s.emailTokenRepo.Create(&models.EmailToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeEmailToken accepts an unexpired, unused token issued for purpose
+func (s *AuthService) consumeEmailToken(token, purpose string) (*models.EmailToken, error) {
+	invalid := apperrors.New(apperrors.ErrInvalidToken, "Link is invalid or has already been used")
+
+	record, err := s.emailTokenRepo.GetByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if record.Purpose != purpose || record.UsedAt != nil {
+		return nil, invalid
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, apperrors.New(apperrors.ErrTokenExpired, "Link has expired; request a new one")
+	}
+
+	claimed, err := s.emailTokenRepo.MarkUsed(record.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, invalid
+	}
+	return record, nil
+}
+
+// emailLink returns the frontend URL handling a token sent by email
+func (s *AuthService) emailLink(path, token string) string {
+	return strings.TrimSuffix(s.config.App.AppURL, "/") + "/" + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *AuthService) sendMail(msg *mail.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"github.com/nshmdayo/nft-platform-sample/pkg/totp"
+	"gorm.io/gorm"
+)
+
+// totpSkew is how many periods a code may be off, tolerating clock drift
+const totpSkew = 1
+
+// recoveryCodeEncoding spells recovery codes in lowercase base32, e.g. "k3xq-7mfa"
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TOTPSetup is what an authenticator app needs to generate codes
+type TOTPSetup struct {
+	Secret string
+	URI    string // otpauth:// URI, usually shown as a QR code
+}
+
+// TwoFactorStatus describes the two-factor authentication of a user
+type TwoFactorStatus struct {
+	Enabled       bool
+	Required      bool // Whether the platform requires every user to enable it
+	RecoveryCodes int  // Unused recovery codes left
+}
+
+// GetTwoFactorStatus returns the two-factor authentication status of a user
+func (s *AuthService) GetTwoFactorStatus(userID uint) (*TwoFactorStatus, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.TwoFactorRequired()
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := s.recoveryCodeRepo.CountUnused(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorStatus{
+		Enabled:       user.TOTPEnabledAt != nil,
+		Required:      required,
+		RecoveryCodes: int(remaining),
+	}, nil
+}
+
+// SetupTOTP generates a new TOTP secret for a user. Logins do not ask for
+// codes until EnableTOTP confirms the authenticator app works.
+func (s *AuthService) SetupTOTP(userID uint) (*TOTPSetup, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperrors.Conflict("Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret: secret,
+		URI:    totp.URI(s.config.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTOTP turns on two-factor authentication once code shows the
+// authenticator app was set up with the secret from SetupTOTP. It returns the
+// user's recovery codes, which are not shown again.
+func (s *AuthService) EnableTOTP(userID uint, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, apperrors.Conflict("Two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperrors.Conflict("Set up two-factor authentication first")
+	}
+
+	ok, err := s.checkTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidMFACode()
+	}
+
+	if err := s.userRepo.EnableTOTP(user.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	codes, err := s.issueRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Two-factor authentication enabled", "user_id", user.ID)
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor authentication after checking a current
+// TOTP or recovery code. It cannot be turned off while the platform requires it.
+func (s *AuthService) DisableTOTP(userID uint, code string) error {
+	user, err := s.requireSecondFactor(userID, code)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.TwoFactorRequired()
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperrors.Conflict("Two-factor authentication is required on this platform")
+	}
+
+	if err := s.removeTwoFactor(user.ID); err != nil {
+		return err
+	}
+	logger.Info("Two-factor authentication disabled", "user_id", user.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking
+// a current TOTP or recovery code
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.requireSecondFactor(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(user.ID)
+}
+
+// ResetTwoFactor turns off two-factor authentication of a user who lost their
+// authenticator and recovery codes. If the platform requires it, the user has
+// to set it up again on their next login.
+func (s *AuthService) ResetTwoFactor(userID uint) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.removeTwoFactor(user.ID); err != nil {
+		return nil, err
+	}
+	return s.getUser(user.ID)
+}
+
+// TwoFactorRequired reports whether every user must enable two-factor authentication
+func (s *AuthService) TwoFactorRequired() (bool, error) {
+	value, err := s.settingRepo.Get(models.SettingRequire2FA)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+// SetTwoFactorRequired sets whether every user must enable two-factor
+// authentication. Users without it are limited to setting it up from their
+// next login or token refresh on. An admin requiring it must have it enabled,
+// so they cannot lock themselves out of the setting.
+func (s *AuthService) SetTwoFactorRequired(adminID uint, required bool) error {
+	if required {
+		admin, err := s.getUser(adminID)
+		if err != nil {
+			return err
+		}
+		if admin.TOTPEnabledAt == nil {
+			return apperrors.Conflict("Enable two-factor authentication on your own account first")
+		}
+	}
+
+	if err := s.settingRepo.Set(models.SettingRequire2FA, strconv.FormatBool(required)); err != nil {
+		return err
+	}
+	logger.Info("Two-factor authentication requirement changed", "admin_id", adminID, "required", required)
+	return nil
+}
+
+// VerifyMFA completes a login started by Login or SignInWithEthereum with a
+// TOTP or recovery code. Wrong codes count as failed logins of the account.
+func (s *AuthService) VerifyMFA(token, code string, client ClientInfo) (*AuthResponse, error) {
+	invalid := apperrors.New(apperrors.ErrInvalidToken, "Login is invalid or has expired; log in again")
+
+	challenge, err := s.mfaChallengeRepo.GetByHash(utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, invalid
+	}
+
+	user, err := s.getUser(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(user.Email)
+	if err := s.checkLoginThrottle(email, client); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.mfaChallengeRepo.AddAttempt(challenge.ID, s.config.MFA.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperrors.New(apperrors.ErrInvalidToken, "Too many codes tried; log in again")
+	}
+
+	ok, err := s.checkSecondFactor(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.loginFailed(email, user.ID, models.LoginFailureInvalidMFACode, client); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidMFACode()
+	}
+
+	claimed, err := s.mfaChallengeRepo.MarkUsed(challenge.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, invalid
+	}
+
+	if err := s.throttle.Reset(accountSubject(email)); err != nil {
+		return nil, err
+	}
+	return s.startSession(user, client)
+}
+
+// startMFAChallenge answers a login of a user with two-factor authentication
+// with a token for VerifyMFA instead of a session
+func (s *AuthService) startMFAChallenge(user *models.User) (*AuthResponse, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ttl, _ := time.ParseDuration(s.config.MFA.ChallengeTTL)
+	if err := s.mfaChallengeRepo.DeleteExpired(now); err != nil {
+		return nil, err
+	}
+	err = s.mfaChallengeRepo.Create(&models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		MFAToken:  token,
+		ExpiresIn: int64(ttl.Seconds()),
+	}, nil
+}
+
+// requireSecondFactor returns a user with two-factor authentication enabled
+// if code is one of their current TOTP or recovery codes
+func (s *AuthService) requireSecondFactor(userID uint, code string) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, apperrors.Conflict("Two-factor authentication is not enabled")
+	}
+
+	ok, err := s.checkSecondFactor(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidMFACode()
+	}
+	return user, nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code of user
+func (s *AuthService) checkSecondFactor(user *models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.checkTOTP(user, code)
+	}
+
+	used, err := s.recoveryCodeRepo.Consume(user.ID, utils.HashToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil || !used {
+		return false, err
+	}
+	remaining, err := s.recoveryCodeRepo.CountUnused(user.ID)
+	if err != nil {
+		return false, err
+	}
+	logger.Info("Recovery code used", "user_id", user.ID, "remaining", remaining)
+	return true, nil
+}
+
+// checkTOTP accepts a TOTP code of user that was not accepted before
+func (s *AuthService) checkTOTP(user *models.User, code string) (bool, error) {
+	if user.TOTPSecret == "" {
+		return false, nil
+	}
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	return s.userRepo.ClaimTOTPStep(user.ID, step)
+}
+
+// issueRecoveryCodes replaces the recovery codes of a user and returns the new ones
+func (s *AuthService) issueRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, s.config.MFA.RecoveryCodes)
+	hashes := make([]string, 0, s.config.MFA.RecoveryCodes)
+	for i := 0; i < s.config.MFA.RecoveryCodes; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(b)
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	if err := s.recoveryCodeRepo.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// removeTwoFactor turns off two-factor authentication of a user and discards
+// their recovery codes
+func (s *AuthService) removeTwoFactor(userID uint) error {
+	if err := s.userRepo.DisableTOTP(userID); err != nil {
+		return err
+	}
+	return s.recoveryCodeRepo.DeleteForUser(userID)
+}
+
+// normalizeRecoveryCode undoes the formatting of a recovery code as typed by a user
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func errInvalidMFACode() error {
+	return apperrors.New(apperrors.ErrInvalidMFACode, "Invalid two-factor authentication code")
+}
@@ -1,47 +1,115 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/mail"
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/revocation"
+	"github.com/nshmdayo/nft-platform-sample/internal/throttle"
 	"github.com/nshmdayo/nft-platform-sample/internal/utils"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
 	"gorm.io/gorm"
 )
 
 type AuthService struct {
-	userRepo *repository.UserRepository
-	config   *config.Config
+	userRepo         *repository.UserRepository
+	refreshTokenRepo *repository.RefreshTokenRepository
+	sessionRepo      *repository.SessionRepository
+	nonceRepo        *repository.WalletNonceRepository
+	mintJobRepo      *repository.MintJobRepository
+	emailTokenRepo   *repository.EmailTokenRepository
+	loginFailureRepo *repository.LoginFailureRepository
+	recoveryCodeRepo *repository.RecoveryCodeRepository
+	mfaChallengeRepo *repository.MFAChallengeRepository
+	settingRepo      *repository.SettingRepository
+	apiKeyRepo       *repository.APIKeyRepository
+	revocations      *revocation.Store
+	throttle         *throttle.Throttle
+	tokens           utils.JWTOptions
+	mailer           mail.Mailer
+	config           *config.Config
+}
+
+// ClientInfo describes the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
 
 type RegisterRequest struct {
-	Email       string `json:"email" binding:"required,email"`
-	Password    string `json:"password" binding:"required,min=6"`
-	Name        string `json:"name" binding:"required"`
-	Institution string `json:"institution"`
-	WalletAddr  string `json:"wallet_address"`
+	Email       string     `json:"email" binding:"required,email"`
+	Password    string     `json:"password" binding:"required,min=6"`
+	Name        string     `json:"name" binding:"required"`
+	Institution string     `json:"institution"`
+	Client      ClientInfo `json:"-"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email    string     `json:"email" binding:"required,email"`
+	Password string     `json:"password" binding:"required"`
+	Client   ClientInfo `json:"-"`
 }
 
+// AuthResponse is the outcome of a login. Users with two-factor
+// authentication first receive only MFAToken, to exchange with VerifyMFA.
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	MFAToken     string       `json:"mfa_token,omitempty"`
+	ExpiresIn    int64        `json:"expires_in"` // Lifetime of Token, or MFAToken if set, in seconds
+	User         *models.User `json:"user"`
 }
 
-func NewAuthService(userRepo *repository.UserRepository, config *config.Config) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	refreshTokenRepo *repository.RefreshTokenRepository,
+	sessionRepo *repository.SessionRepository,
+	nonceRepo *repository.WalletNonceRepository,
+	mintJobRepo *repository.MintJobRepository,
+	emailTokenRepo *repository.EmailTokenRepository,
+	loginFailureRepo *repository.LoginFailureRepository,
+	recoveryCodeRepo *repository.RecoveryCodeRepository,
+	mfaChallengeRepo *repository.MFAChallengeRepository,
+	settingRepo *repository.SettingRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	revocations *revocation.Store,
+	throttle *throttle.Throttle,
+	tokens utils.JWTOptions,
+	mailer mail.Mailer,
+	config *config.Config,
+) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		config:   config,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		nonceRepo:        nonceRepo,
+		mintJobRepo:      mintJobRepo,
+		emailTokenRepo:   emailTokenRepo,
+		loginFailureRepo: loginFailureRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		mfaChallengeRepo: mfaChallengeRepo,
+		settingRepo:      settingRepo,
+		apiKeyRepo:       apiKeyRepo,
+		revocations:      revocations,
+		throttle:         throttle,
+		tokens:           tokens,
+		mailer:           mailer,
+		config:           config,
 	}
 }
 
 func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+	if err := s.checkRegistrationLimit(req.Client.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Check if user already exists
 	_, err := s.userRepo.GetByEmail(req.Email)
 	if err == nil {
@@ -63,61 +131,240 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		Password:    hashedPassword,
 		Name:        req.Name,
 		Institution: req.Institution,
-		WalletAddr:  req.WalletAddr,
-		Role:        "researcher",
+		Role:        models.RoleResearcher,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
-
-	// Generate JWT token
-	expiresIn, _ := time.ParseDuration(s.config.JWT.ExpiresIn)
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, s.config.JWT.Secret, expiresIn)
-	if err != nil {
-		return nil, err
+	if err := s.countRegistration(req.Client.IPAddress); err != nil {
+		logger.Warn("Failed to count registration", "ip", req.Client.IPAddress, "error", err)
 	}
 
-	// Clear password from response
-	user.Password = ""
+	// The account is usable before verification, and the link can be resent
+	if err := s.sendVerificationEmail(user); err != nil {
+		logger.Warn("Failed to send verification email", "user_id", user.ID, "error", err)
+	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.startSession(user, req.Client)
 }
 
+// Login checks an email and password. Failed attempts are throttled per
+// account and per client address, see checkLoginThrottle. Users with
+// two-factor authentication still have to pass VerifyMFA.
 func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := s.checkLoginThrottle(email, req.Client); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid email or password")
+			if err := s.loginFailed(email, 0, models.LoginFailureUnknownAccount, req.Client); err != nil {
+				return nil, err
+			}
+			return nil, errInvalidCredentials
 		}
 		return nil, err
 	}
 
 	// Check password
 	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
-		return nil, errors.New("invalid email or password")
+		if err := s.loginFailed(email, user.ID, models.LoginFailureInvalidPassword, req.Client); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+
+	// Failures are forgotten once the whole login succeeded
+	if user.TOTPEnabledAt != nil {
+		return s.startMFAChallenge(user)
+	}
+	if err := s.throttle.Reset(accountSubject(email)); err != nil {
+		return nil, err
+	}
+	return s.startSession(user, req.Client)
+}
+
+func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
+	return s.userRepo.GetByID(id)
+}
+
+// SetInterests replaces the declared research interests of a user, which
+// reviewer matching compares with paper keywords. Duplicates are dropped
+// regardless of case.
+func (s *AuthService) SetInterests(userID uint, interests []string) (*models.User, error) {
+	seen := make(map[string]bool, len(interests))
+	cleaned := make([]string, 0, len(interests))
+	for _, interest := range interests {
+		interest = strings.TrimSpace(interest)
+		key := strings.ToLower(interest)
+		if interest == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, interest)
+	}
+
+	interestsJSON, err := json.Marshal(cleaned)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetInterests(userID, interestsJSON); err != nil {
+		return nil, err
+	}
+	return s.getUser(userID)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token.
+// Each refresh token can be used once; presenting a used token again means it
+// leaked, so its whole session is revoked and the login must be repeated.
+func (s *AuthService) Refresh(refreshToken string, client ClientInfo) (*AuthResponse, error) {
+	stored, err := s.refreshTokenRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrInvalidToken, "Invalid refresh token")
+		}
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil || session.RevokedAt != nil {
+		return nil, apperrors.New(apperrors.ErrInvalidToken, "Refresh token has been revoked")
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedSession(session, now)
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, apperrors.New(apperrors.ErrTokenExpired, "Refresh token has expired")
+	}
+
+	// Claim the token; losing the race to a concurrent refresh counts as reuse
+	claimed, err := s.refreshTokenRepo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, s.revokeReusedSession(session, now)
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrInvalidToken, "Invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if client.IPAddress != "" {
+		session.IPAddress = client.IPAddress
+	}
+	if client.UserAgent != "" {
+		session.UserAgent = client.UserAgent
+	}
+	return s.issueTokens(user, session)
+}
+
+// Logout ends the session of a refresh token. Unknown tokens are ignored so the
+// endpoint does not reveal which tokens exist.
+func (s *AuthService) Logout(refreshToken string) error {
+	stored, err := s.refreshTokenRepo.GetByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	session, err := s.sessionRepo.GetByID(stored.SessionID)
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(session, time.Now())
+}
+
+func (s *AuthService) revokeReusedSession(session *models.Session, now time.Time) error {
+	logger.Warn("Refresh token reuse detected, revoking session", "user_id", session.UserID, "session_id", session.ID)
+	if err := s.revokeSession(session, now); err != nil {
+		return err
+	}
+	return apperrors.New(apperrors.ErrInvalidToken, "Refresh token has already been used")
+}
+
+// startSession records a new login from client and issues its first tokens
+func (s *AuthService) startSession(user *models.User, client ClientInfo) (*AuthResponse, error) {
+	session := &models.Session{
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, session)
+}
+
+// issueTokens signs a new access token and creates a new refresh token for a
+// session. The session's previous access token is revoked.
+func (s *AuthService) issueTokens(user *models.User, session *models.Session) (*AuthResponse, error) {
+	// Users who have to set up required two-factor authentication get tokens that only allow that
+	mfaSetup := false
+	if user.TOTPEnabledAt == nil {
+		required, err := s.TwoFactorRequired()
+		if err != nil {
+			return nil, err
+		}
+		mfaSetup = required
 	}
 
-	// Generate JWT token
 	expiresIn, _ := time.ParseDuration(s.config.JWT.ExpiresIn)
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, s.config.JWT.Secret, expiresIn)
+	token, jti, err := utils.GenerateJWT(s.tokens, user.ID, user.Email, user.Role, session.ID, mfaSetup, expiresIn)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	refreshExpiresIn, _ := time.ParseDuration(s.config.JWT.RefreshExpiresIn)
+	err = s.refreshTokenRepo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: now.Add(refreshExpiresIn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.revocations.Revoke(session.CurrentJTI, session.AccessExpiresAt); err != nil {
+		return nil, err
+	}
+	session.CurrentJTI = jti
+	session.AccessExpiresAt = now.Add(expiresIn)
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(refreshExpiresIn)
+	if err := s.sessionRepo.Update(session); err != nil {
+		return nil, err
+	}
+
 	// Clear password from response
 	user.Password = ""
 
 	return &AuthResponse{
-		Token: token,
-		User:  user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(expiresIn.Seconds()),
+		User:         user,
 	}, nil
 }
-
-func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
-	return s.userRepo.GetByID(id)
-}
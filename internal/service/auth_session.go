@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"gorm.io/gorm"
+)
+
+// ListSessions returns the active sessions of a user, most recently seen first
+func (s *AuthService) ListSessions(userID uint) ([]models.Session, error) {
+	return s.sessionRepo.ListActive(userID, time.Now())
+}
+
+// RevokeSession ends one of the user's sessions
+func (s *AuthService) RevokeSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("Session")
+		}
+		return err
+	}
+
+	// Other users' sessions are reported as missing rather than forbidden
+	if session.UserID != userID {
+		return apperrors.NotFound("Session")
+	}
+
+	return s.revokeSession(session, time.Now())
+}
+
+// RevokeAllSessions ends every active session of a user and returns how many were ended
+func (s *AuthService) RevokeAllSessions(userID uint) (int, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	sessions, err := s.sessionRepo.ListActive(userID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range sessions {
+		if err := s.revokeSession(&sessions[i], now); err != nil {
+			return 0, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// revokeSession revokes a session, its refresh tokens and its current access token
+func (s *AuthService) revokeSession(session *models.Session, now time.Time) error {
+	if err := s.refreshTokenRepo.RevokeSession(session.ID, now); err != nil {
+		return err
+	}
+	if err := s.revocations.Revoke(session.CurrentJTI, session.AccessExpiresAt); err != nil {
+		return err
+	}
+
+	if session.RevokedAt == nil {
+		session.RevokedAt = &now
+		return s.sessionRepo.Update(session)
+	}
+	return nil
+}
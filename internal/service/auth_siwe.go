@@ -0,0 +1,162 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"github.com/nshmdayo/nft-platform-sample/pkg/siwe"
+	"gorm.io/gorm"
+)
+
+// SIWEChallenge is what a client needs to build a Sign-In with Ethereum message
+type SIWEChallenge struct {
+	Nonce     string
+	Domain    string
+	Origin    string
+	ChainID   int
+	ExpiresAt time.Time
+}
+
+// SIWERequest is a signed Sign-In with Ethereum message
+type SIWERequest struct {
+	Message   string
+	Signature string // 0x-prefixed 65-byte personal_sign signature
+	UserID    uint   // Authenticated user to link the wallet to, 0 if signed out
+	Client    ClientInfo
+}
+
+// IssueSIWENonce issues a single-use nonce for a Sign-In with Ethereum message
+func (s *AuthService) IssueSIWENonce() (*SIWEChallenge, error) {
+	now := time.Now()
+	nonceTTL, _ := time.ParseDuration(s.config.SIWE.NonceTTL)
+
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	// Nonces are issued to anyone, so abandoned ones are cleaned up along the way
+	if err := s.nonceRepo.DeleteExpired(now); err != nil {
+		return nil, err
+	}
+	record := &models.WalletNonce{
+		Nonce:     nonce,
+		Purpose:   models.NoncePurposeSIWE,
+		ExpiresAt: now.Add(nonceTTL),
+	}
+	if err := s.nonceRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	return &SIWEChallenge{
+		Nonce:     nonce,
+		Domain:    s.config.SIWE.Domain,
+		Origin:    s.config.SIWE.Origin,
+		ChainID:   s.config.SIWE.ChainID,
+		ExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// SignInWithEthereum verifies a signed SIWE message and logs in the user the
+// wallet is linked to. If the request is made by a signed-in user whose account
+// has no wallet yet, the wallet is linked to it first. Users with two-factor
+// authentication still have to pass VerifyMFA.
+func (s *AuthService) SignInWithEthereum(req *SIWERequest) (*AuthResponse, error) {
+	msg, err := siwe.ParseMessage(req.Message)
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrInvalidFormat, "Invalid SIWE message: "+err.Error())
+	}
+
+	now := time.Now()
+	if msg.Domain != s.config.SIWE.Domain {
+		return nil, apperrors.Unauthorized("SIWE message was issued for another domain")
+	}
+	if !sameOrigin(msg.URI, s.config.SIWE.Origin) {
+		return nil, apperrors.Unauthorized("SIWE message was issued for another origin")
+	}
+	if s.config.SIWE.ChainID != 0 && msg.ChainID != uint64(s.config.SIWE.ChainID) {
+		return nil, apperrors.Unauthorized("SIWE message was issued for another chain")
+	}
+	if err := msg.Valid(now); err != nil {
+		return nil, apperrors.New(apperrors.ErrTokenExpired, "SIWE "+err.Error())
+	}
+
+	if err := verifyPersonalSignature(req.Message, req.Signature, msg.Address); err != nil {
+		return nil, err
+	}
+
+	// The nonce is consumed only once the signature holds, so forged messages cannot burn it
+	consumed, err := s.nonceRepo.Consume(msg.Nonce, models.NoncePurposeSIWE, 0, now)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, apperrors.New(apperrors.ErrInvalidToken, "Nonce is invalid, expired or already used")
+	}
+
+	user, err := s.userRepo.GetByWalletAddress(msg.Address)
+	switch {
+	case err == nil:
+		if req.UserID != 0 && user.ID != req.UserID {
+			return nil, apperrors.Conflict("Wallet is linked to another account")
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if req.UserID == 0 {
+			return nil, apperrors.New(apperrors.ErrUserNotFound, "No account is linked to this wallet; sign in and link it first")
+		}
+		if user, err = s.getUser(req.UserID); err != nil {
+			return nil, err
+		}
+		if err := s.setWallet(user, msg.Address); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	logger.Info("User signed in with Ethereum", "user_id", user.ID, "wallet", msg.Address)
+	if user.TOTPEnabledAt != nil {
+		return s.startMFAChallenge(user)
+	}
+	return s.startSession(user, req.Client)
+}
+
+// verifyPersonalSignature checks that signature is a personal_sign of message by address
+func verifyPersonalSignature(message, signature, address string) error {
+	sig, err := ethereum.DecodeHex(signature)
+	if err != nil || len(sig) != 65 {
+		return apperrors.New(apperrors.ErrInvalidSignature, "Signature must be 65 hex encoded bytes")
+	}
+
+	signer, err := ethereum.RecoverAddress(ethereum.HashPersonalMessage([]byte(message)), sig)
+	if err != nil || !strings.EqualFold(signer.Hex(), address) {
+		return apperrors.New(apperrors.ErrInvalidSignature, "Signature does not match the wallet address")
+	}
+	return nil
+}
+
+// generateNonce returns a random alphanumeric nonce as required by EIP-4361
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sameOrigin reports whether uri has the scheme and host of origin
+func sameOrigin(uri, origin string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Scheme+"://"+u.Host, strings.TrimSuffix(origin, "/"))
+}
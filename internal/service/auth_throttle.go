@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/throttle"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// errInvalidCredentials is returned for every failed login, whatever the reason
+var errInvalidCredentials = errors.New("invalid email or password")
+
+func accountSubject(email string) string {
+	return "login:account:" + email
+}
+
+func ipSubject(ip string) string {
+	return "login:ip:" + ip
+}
+
+func registrationSubject(ip string) string {
+	return "register:ip:" + ip
+}
+
+// accountPolicy slows down and locks out password guessing against one
+// account. Unknown emails are throttled alike so lockouts do not reveal which
+// accounts exist.
+func (s *AuthService) accountPolicy() throttle.Policy {
+	cfg := s.config.Login
+	window, _ := time.ParseDuration(cfg.FailureWindow)
+	lockout, _ := time.ParseDuration(cfg.Lockout)
+	baseDelay, _ := time.ParseDuration(cfg.BaseDelay)
+	maxDelay, _ := time.ParseDuration(cfg.MaxDelay)
+	return throttle.Policy{
+		MaxFailures: cfg.MaxAccountFailures,
+		Window:      window,
+		Lockout:     lockout,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+	}
+}
+
+// ipPolicy locks out an address guessing across many accounts. Addresses are
+// not delayed, since many users may share one behind a NAT.
+func (s *AuthService) ipPolicy() throttle.Policy {
+	cfg := s.config.Login
+	window, _ := time.ParseDuration(cfg.FailureWindow)
+	lockout, _ := time.ParseDuration(cfg.Lockout)
+	return throttle.Policy{
+		MaxFailures: cfg.MaxIPFailures,
+		Window:      window,
+		Lockout:     lockout,
+	}
+}
+
+// registrationPolicy limits how many accounts one address can create per window
+func (s *AuthService) registrationPolicy() throttle.Policy {
+	window, _ := time.ParseDuration(s.config.Login.RegistrationWindow)
+	return throttle.Policy{
+		MaxFailures: s.config.Login.MaxRegistrationsPerIP,
+		Window:      window,
+		Lockout:     window,
+	}
+}
+
+// checkLoginThrottle rejects a login attempted while the account or the client
+// address is locked out or has to wait after its last failure. Rejected
+// attempts are logged but not counted, so waiting out the delay is enough.
+func (s *AuthService) checkLoginThrottle(email string, client ClientInfo) error {
+	now := time.Now()
+	wait, err := s.throttle.Wait(accountSubject(email), s.accountPolicy(), now)
+	if err != nil {
+		return err
+	}
+	if client.IPAddress != "" {
+		ipWait, err := s.throttle.Wait(ipSubject(client.IPAddress), s.ipPolicy(), now)
+		if err != nil {
+			return err
+		}
+		wait = max(wait, ipWait)
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	s.recordLoginFailure(email, 0, models.LoginFailureThrottled, client)
+	return apperrors.TooManyRequests("Too many failed login attempts, try again later", wait)
+}
+
+// loginFailed logs and counts a failed login against the account and the
+// client address
+func (s *AuthService) loginFailed(email string, userID uint, reason string, client ClientInfo) error {
+	s.recordLoginFailure(email, userID, reason, client)
+
+	now := time.Now()
+	locked, err := s.throttle.Fail(accountSubject(email), s.accountPolicy(), now)
+	if err != nil {
+		return err
+	}
+	if locked {
+		logger.Warn("Account locked out after failed logins", "email", email, "user_id", userID, "ip", client.IPAddress)
+	}
+
+	if client.IPAddress != "" {
+		locked, err := s.throttle.Fail(ipSubject(client.IPAddress), s.ipPolicy(), now)
+		if err != nil {
+			return err
+		}
+		if locked {
+			logger.Warn("Address locked out after failed logins", "ip", client.IPAddress)
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure adds a failed login to the audit log. Failing to log does
+// not fail the request.
+func (s *AuthService) recordLoginFailure(email string, userID uint, reason string, client ClientInfo) {
+	err := s.loginFailureRepo.Create(&models.LoginFailure{
+		Email:     email,
+		UserID:    userID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Reason:    reason,
+	})
+	if err != nil {
+		logger.Error("Failed to record login failure", "email", email, "error", err)
+	}
+}
+
+// checkRegistrationLimit rejects a registration from an address that created
+// too many accounts recently
+func (s *AuthService) checkRegistrationLimit(ip string) error {
+	if ip == "" {
+		return nil
+	}
+	wait, err := s.throttle.Wait(registrationSubject(ip), s.registrationPolicy(), time.Now())
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return apperrors.TooManyRequests("Too many registrations from this address, try again later", wait)
+	}
+	return nil
+}
+
+// countRegistration counts an account created from an address towards its limit
+func (s *AuthService) countRegistration(ip string) error {
+	if ip == "" {
+		return nil
+	}
+	locked, err := s.throttle.Fail(registrationSubject(ip), s.registrationPolicy(), time.Now())
+	if locked {
+		logger.Warn("Registration limit reached", "ip", ip)
+	}
+	return err
+}
+
+// UnlockUser lifts the login lockout and delays of a user's account
+func (s *AuthService) UnlockUser(userID uint) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.Reset(accountSubject(strings.ToLower(user.Email))); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnlockIP lifts the login lockout and registration limit of a client address
+func (s *AuthService) UnlockIP(ip string) error {
+	if err := s.throttle.Reset(ipSubject(ip)); err != nil {
+		return err
+	}
+	return s.throttle.Reset(registrationSubject(ip))
+}
+
+// ListLoginFailures returns a page of the failed login audit log, newest
+// first, optionally filtered by email and client address
+func (s *AuthService) ListLoginFailures(email, ip string, page, limit int) ([]models.LoginFailure, error) {
+	offset := (page - 1) * limit
+	return s.loginFailureRepo.List(strings.ToLower(email), ip, limit, offset)
+}
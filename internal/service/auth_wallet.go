@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// WalletChallenge is the message a wallet signs to be linked to an account
+type WalletChallenge struct {
+	Nonce     string
+	Message   string
+	ExpiresAt time.Time
+}
+
+// LinkWalletRequest proves control of a wallet by signing a WalletChallenge
+type LinkWalletRequest struct {
+	Address   string
+	Signature string // 0x-prefixed 65-byte personal_sign signature of the challenge message
+	Nonce     string
+}
+
+// IssueWalletChallenge issues a single-use challenge for linking a wallet to the user's account
+func (s *AuthService) IssueWalletChallenge(userID uint) (*WalletChallenge, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	nonceTTL, _ := time.ParseDuration(s.config.SIWE.NonceTTL)
+
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.nonceRepo.DeleteExpired(now); err != nil {
+		return nil, err
+	}
+	// The expiry is part of the signed message, so it is kept at second precision
+	record := &models.WalletNonce{
+		Nonce:     nonce,
+		Purpose:   models.NoncePurposeLink,
+		UserID:    user.ID,
+		ExpiresAt: now.Add(nonceTTL).UTC().Truncate(time.Second),
+	}
+	if err := s.nonceRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	return &WalletChallenge{
+		Nonce:     nonce,
+		Message:   s.linkChallengeMessage(user, record),
+		ExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// LinkWallet links the wallet that signed the user's challenge to the user's account
+func (s *AuthService) LinkWallet(userID uint, req *LinkWalletRequest) (*models.User, error) {
+	addr, err := ethereum.HexToAddress(req.Address)
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrInvalidFormat, "Invalid wallet address")
+	}
+	address := addr.Hex()
+
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidChallenge := apperrors.New(apperrors.ErrInvalidToken, "Challenge is invalid, expired or already used")
+	record, err := s.nonceRepo.GetByNonce(req.Nonce)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidChallenge
+		}
+		return nil, err
+	}
+	if record.Purpose != models.NoncePurposeLink || record.UserID != user.ID {
+		return nil, invalidChallenge
+	}
+
+	if err := verifyPersonalSignature(s.linkChallengeMessage(user, record), req.Signature, address); err != nil {
+		return nil, err
+	}
+
+	consumed, err := s.nonceRepo.Consume(record.Nonce, models.NoncePurposeLink, user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, invalidChallenge
+	}
+
+	if err := s.setWallet(user, address); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnlinkWallet removes the wallet from the user's account. It is refused while
+// NFTs of the user are being minted, as they are minted to the linked wallet.
+func (s *AuthService) UnlinkWallet(userID uint) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.WalletAddr == nil || *user.WalletAddr == "" {
+		return apperrors.NotFound("Linked wallet")
+	}
+
+	pending, err := s.mintJobRepo.CountPendingForUser(user.ID)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return apperrors.Conflict(fmt.Sprintf("Wallet cannot be unlinked while %d NFT mints are pending", pending))
+	}
+
+	if err := s.userRepo.SetWalletAddress(user.ID, nil); err != nil {
+		return err
+	}
+	logger.Info("Wallet unlinked", "user_id", user.ID, "wallet", *user.WalletAddr)
+	return nil
+}
+
+// setWallet links address to user, which must not have another wallet linked
+func (s *AuthService) setWallet(user *models.User, address string) error {
+	if user.WalletAddr != nil && *user.WalletAddr != "" {
+		if strings.EqualFold(*user.WalletAddr, address) {
+			return nil
+		}
+		return apperrors.Conflict("Account already has a linked wallet; unlink it first")
+	}
+
+	owner, err := s.userRepo.GetByWalletAddress(address)
+	if err == nil && owner.ID != user.ID {
+		return apperrors.Conflict("Wallet is linked to another account")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// The unique constraint still catches a concurrent link of the same wallet
+	if err := s.userRepo.SetWalletAddress(user.ID, &address); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return apperrors.Conflict("Wallet is linked to another account")
+		}
+		return err
+	}
+	user.WalletAddr = &address
+
+	logger.Info("Wallet linked", "user_id", user.ID, "wallet", address)
+	return nil
+}
+
+// linkChallengeMessage renders the text signed to link a wallet. It is derived
+// from the stored nonce so it can be rebuilt when the signature comes back.
+func (s *AuthService) linkChallengeMessage(user *models.User, record *models.WalletNonce) string {
+	return fmt.Sprintf("%s asks you to link this wallet to account %s.\n\n"+
+		"Signing does not send a transaction or cost any gas.\n\n"+
+		"Account ID: %d\nNonce: %s\nExpires At: %s",
+		s.config.SIWE.Domain, user.Email, user.ID, record.Nonce, record.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+func (s *AuthService) getUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrUserNotFound, "User not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/decision"
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/validation"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// decisionActions is the lifecycle transition each editorial decision applies
+var decisionActions = map[string]PaperAction{
+	decision.Accept:        PaperActionAccept,
+	decision.MinorRevision: PaperActionRequestRevision,
+	decision.MajorRevision: PaperActionRequestRevision,
+	decision.Reject:        PaperActionReject,
+}
+
+// DecisionService drafts editorial decisions from the reviews of a paper under
+// the configured decision policy, and records the decisions editors confirm.
+// A round of reviews consists of the reviews written or updated in the
+// paper's current review round, so resubmitted papers are decided on fresh
+// reviews.
+type DecisionService struct {
+	decisionRepo *repository.EditorialDecisionRepository
+	reviewRepo   *repository.ReviewRepository
+	paperRepo    *repository.PaperRepository
+	settingRepo  *repository.SettingRepository
+}
+
+// PaperDecisionDraft is the decision drafted for a paper
+type PaperDecisionDraft struct {
+	PaperID     uint   `json:"paper_id"`
+	PaperStatus string `json:"paper_status"`
+	decision.Draft
+}
+
+// ConfirmDecisionRequest confirms the drafted decision on a paper, or
+// overrides it with a comment explaining why
+type ConfirmDecisionRequest struct {
+	Decision string `json:"decision"` // The drafted decision if empty
+	Comment  string `json:"comment"`  // Required when overriding the draft
+}
+
+func NewDecisionService(decisionRepo *repository.EditorialDecisionRepository, reviewRepo *repository.ReviewRepository, paperRepo *repository.PaperRepository, settingRepo *repository.SettingRepository) *DecisionService {
+	return &DecisionService{
+		decisionRepo: decisionRepo,
+		reviewRepo:   reviewRepo,
+		paperRepo:    paperRepo,
+		settingRepo:  settingRepo,
+	}
+}
+
+// Policy returns the decision policy in effect
+func (s *DecisionService) Policy() (*decision.Policy, error) {
+	value, err := s.settingRepo.Get(models.SettingDecisionPolicy)
+	if err != nil {
+		return nil, err
+	}
+	policy := decision.DefaultPolicy()
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &policy); err != nil {
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
+
+// SetPolicy replaces the decision policy
+func (s *DecisionService) SetPolicy(policy decision.Policy) error {
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set(models.SettingDecisionPolicy, string(value)); err != nil {
+		return err
+	}
+	logger.Info("Decision policy changed", "policy", string(value))
+	return nil
+}
+
+// DraftDecision proposes a decision on a paper from its current round of reviews
+func (s *DecisionService) DraftDecision(paperID uint) (*PaperDecisionDraft, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+	return s.draft(paper)
+}
+
+// ConfirmDecision records an editor's decision on a paper under review and
+// moves the paper on: accepted, back to its owner for revision, or rejected
+func (s *DecisionService) ConfirmDecision(paperID uint, req *ConfirmDecisionRequest, editorID uint) (*models.EditorialDecision, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors cannot make editorial decisions on their own papers
+	if paper.OwnerID == editorID {
+		return nil, apperrors.Forbidden("authors cannot decide on their own papers")
+	}
+
+	// Decisions end a round of review
+	if paper.Status != models.PaperStatusUnderReview {
+		return nil, apperrors.New(apperrors.ErrInvalidTransition,
+			fmt.Sprintf("cannot decide on a paper in %s status", paper.Status))
+	}
+
+	draft, err := s.draft(paper)
+	if err != nil {
+		return nil, err
+	}
+	if !draft.Ready {
+		return nil, apperrors.New(apperrors.ErrReviewsIncomplete,
+			fmt.Sprintf("%d of %d required reviews complete", draft.Reviews, draft.RequiredReviews))
+	}
+
+	chosen := req.Decision
+	if chosen == "" {
+		chosen = draft.Decision
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if chosen != draft.Decision && comment == "" {
+		return nil, apperrors.Validation("Validation failed", validation.ValidationErrors{
+			{Field: "comment", Message: "is required when overriding the drafted decision: " + draft.Decision},
+		})
+	}
+	next, err := NextPaperStatus(paper.Status, decisionActions[chosen])
+	if err != nil {
+		return nil, err
+	}
+
+	rationale, err := json.Marshal(draft.Rationale)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.EditorialDecision{
+		PaperID:     paper.ID,
+		EditorID:    editorID,
+		Decision:    chosen,
+		Proposed:    draft.Decision,
+		Score:       draft.Score,
+		ScoreMethod: draft.ScoreMethod,
+		Round:       paper.ReviewRound,
+		Reviews:     draft.Reviews,
+		Rationale:   rationale,
+		Comment:     comment,
+		PaperStatus: next,
+	}
+	// Another editor may have decided meanwhile
+	recorded, err := s.decisionRepo.Record(record, paper.Status)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		return nil, apperrors.New(apperrors.ErrInvalidTransition, "paper is no longer under review")
+	}
+
+	logger.Info("Editorial decision confirmed", "paper_id", paper.ID, "decision", chosen, "proposed", draft.Decision, "editor_id", editorID)
+	return record, nil
+}
+
+// ListDecisions returns the decisions on a paper, oldest first, to its owner or an editor
+func (s *DecisionService) ListDecisions(paperID uint, viewer Viewer) ([]models.EditorialDecision, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.Editor && paper.OwnerID != viewer.UserID {
+		return nil, apperrors.Forbidden("only the author and editors can see decisions on a paper")
+	}
+
+	return s.decisionRepo.ListByPaper(paperID)
+}
+
+// draft proposes a decision on paper from the reviews of its current round
+func (s *DecisionService) draft(paper *models.Paper) (*PaperDecisionDraft, error) {
+	policy, err := s.Policy()
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.GetByPaperID(paper.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	round := make([]decision.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Round != paper.ReviewRound {
+			continue
+		}
+		round = append(round, decision.Review{
+			Score:          review.Score,
+			Confidence:     review.Confidence,
+			Recommendation: review.Recommendation,
+		})
+	}
+
+	return &PaperDecisionDraft{
+		PaperID:     paper.ID,
+		PaperStatus: paper.Status,
+		Draft:       *decision.Propose(*policy, round),
+	}, nil
+}
+
+// getPaper loads a paper and reports a missing one as PAPER_NOT_FOUND
+func (s *DecisionService) getPaper(id uint) (*models.Paper, error) {
+	paper, err := s.paperRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrPaperNotFound, "Paper not found")
+		}
+		return nil, err
+	}
+	return paper, nil
+}
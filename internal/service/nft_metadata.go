@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// ipfsScheme prefixes the URIs of published documents
+const ipfsScheme = "ipfs://"
+
+// maxMetadataSize bounds stored metadata documents read back for serving
+const maxMetadataSize = 1 << 20
+
+// GetTokenMetadata returns the ERC-721 metadata document of a minted token.
+// Tokens whose metadata was not published at mint time get it published now.
+func (s *NFTService) GetTokenMetadata(ctx context.Context, nftType string, tokenID uint) ([]byte, error) {
+	record, err := s.getNFT(nftType, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.MetadataURI != "" {
+		doc, err := s.readDocument(ctx, record.MetadataURI)
+		if err == nil {
+			return doc, nil
+		}
+		logger.Warn("Failed to read published NFT metadata, republishing", "uri", record.MetadataURI, "error", err)
+	}
+
+	uri, doc, err := s.publishMetadata(ctx, nftType, record.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	record.MetadataURI = uri
+	if err := s.nftRepo.Update(record); err != nil {
+		logger.Error("Failed to record NFT metadata URI", "id", record.ID, "error", err)
+	}
+
+	return doc, nil
+}
+
+// publishMetadata renders the token image and metadata document of a paper or
+// review, stores both and returns the document's ipfs:// URI
+func (s *NFTService) publishMetadata(ctx context.Context, nftType string, referenceID uint) (string, []byte, error) {
+	var (
+		build func(opts nft.MetadataOptions) *nft.TokenMetadata
+		card  []byte
+		path  string
+	)
+
+	switch nftType {
+	case models.NFTTypePaper:
+		paper, err := s.getPaper(referenceID)
+		if err != nil {
+			return "", nil, err
+		}
+		reviews, err := s.reviewRepo.GetByPaperID(paper.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		build = func(opts nft.MetadataOptions) *nft.TokenMetadata { return nft.PaperMetadata(paper, reviews, opts) }
+		card = nft.CardSVG("Paper", paper.Title, paper.Category)
+		path = fmt.Sprintf("/api/v1/papers/%d", paper.ID)
+
+	case models.NFTTypeReview:
+		review, err := s.getReview(referenceID)
+		if err != nil {
+			return "", nil, err
+		}
+		build = func(opts nft.MetadataOptions) *nft.TokenMetadata { return nft.ReviewMetadata(review, opts) }
+		card = nft.CardSVG("Review", review.Paper.Title, fmt.Sprintf("Score %d/10 · %s", review.Score, review.Recommendation))
+		path = fmt.Sprintf("/api/v1/reviews/%d", review.ID)
+
+	default:
+		return "", nil, apperrors.BadRequest("unknown NFT type: " + nftType)
+	}
+
+	image, err := s.storage.Put(ctx, nftType+".svg", bytes.NewReader(card))
+	if err != nil {
+		return "", nil, apperrors.Wrap(err, apperrors.ErrStorage, "failed to store NFT image")
+	}
+
+	doc, err := json.Marshal(build(nft.MetadataOptions{
+		Image:       ipfsScheme + image.CID,
+		ExternalURL: strings.TrimRight(s.config.App.PublicURL, "/") + path,
+	}))
+	if err != nil {
+		return "", nil, err
+	}
+
+	object, err := s.storage.Put(ctx, "metadata.json", bytes.NewReader(doc))
+	if err != nil {
+		return "", nil, apperrors.Wrap(err, apperrors.ErrStorage, "failed to store NFT metadata")
+	}
+
+	return ipfsScheme + object.CID, doc, nil
+}
+
+// pinReview publishes the metadata of a review about to be minted, so the
+// token can reference it on chain the way paper tokens reference their manuscript
+func (s *NFTService) pinReview(ctx context.Context, review *models.Review) error {
+	if review.IPFSHash != "" {
+		return nil
+	}
+
+	uri, _, err := s.publishMetadata(ctx, models.NFTTypeReview, review.ID)
+	if err != nil {
+		return err
+	}
+	cid := strings.TrimPrefix(uri, ipfsScheme)
+	if err := s.reviewRepo.SetIPFSHash(review.ID, cid); err != nil {
+		return err
+	}
+	review.IPFSHash = cid
+	return nil
+}
+
+// readDocument reads a published document back from storage
+func (s *NFTService) readDocument(ctx context.Context, uri string) ([]byte, error) {
+	cid, ok := strings.CutPrefix(uri, ipfsScheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported metadata URI: %s", uri)
+	}
+
+	r, err := s.storage.Get(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, maxMetadataSize))
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"gorm.io/gorm"
+)
+
+// NFTDetail is a token together with the paper or review it represents. It
+// must be shown through ReviewPolicies.NFTView, which hides identities the
+// paper's review policy keeps from the viewer.
+type NFTDetail struct {
+	models.NFTMetadata
+	Paper  *models.Paper  `json:"paper,omitempty"`
+	Review *models.Review `json:"review,omitempty"`
+}
+
+type TransferNFTRequest struct {
+	Type      string `json:"type"`
+	TokenID   uint   `json:"token_id"`
+	ToAddress string `json:"to_address"`
+}
+
+// ListNFTs lists minted tokens matching filter
+func (s *NFTService) ListNFTs(filter repository.NFTFilter, page, limit int) ([]models.NFTMetadata, error) {
+	if filter.Type != "" {
+		if err := validateNFTType(filter.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	offset := (page - 1) * limit
+	return s.nftRepo.List(filter, limit, offset)
+}
+
+// GetUserNFTs lists the tokens held by a user's linked wallet
+func (s *NFTService) GetUserNFTs(userID uint, page, limit int) ([]models.NFTMetadata, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrUserNotFound, "User not found")
+		}
+		return nil, err
+	}
+
+	if user.WalletAddr == nil || *user.WalletAddr == "" {
+		return []models.NFTMetadata{}, nil
+	}
+
+	return s.ListNFTs(repository.NFTFilter{OwnerAddr: *user.WalletAddr}, page, limit)
+}
+
+// GetNFT returns a token with the paper or review it was minted for
+func (s *NFTService) GetNFT(nftType string, tokenID uint) (*NFTDetail, error) {
+	record, err := s.getNFT(nftType, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &NFTDetail{NFTMetadata: *record}
+	switch record.Type {
+	case models.NFTTypePaper:
+		detail.Paper, err = s.getPaper(record.ReferenceID)
+	case models.NFTTypeReview:
+		detail.Review, err = s.getReview(record.ReferenceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+// GetTransferHistory returns the ownership history of a token, starting with its mint
+func (s *NFTService) GetTransferHistory(nftType string, tokenID uint) ([]models.NFTTransfer, error) {
+	record, err := s.getNFT(nftType, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return s.nftRepo.GetTransfers(record.ID)
+}
+
+// TransferNFT moves a token held by the user's wallet to another address on
+// chain and records the transfer
+func (s *NFTService) TransferNFT(ctx context.Context, req *TransferNFTRequest, userID uint) (*models.NFTTransfer, error) {
+	if !ethereum.IsHexAddress(req.ToAddress) {
+		return nil, apperrors.New(apperrors.ErrInvalidFormat, "to_address must be a 0x-prefixed 20-byte hex address")
+	}
+
+	record, err := s.getNFT(req.Type, req.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user holds the token
+	if user.WalletAddr == nil || !strings.EqualFold(*user.WalletAddr, record.OwnerAddr) {
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to transfer this NFT")
+	}
+	if strings.EqualFold(record.OwnerAddr, req.ToAddress) {
+		return nil, apperrors.BadRequest("recipient already owns this NFT")
+	}
+
+	transferer, ok := s.minter.(nft.Transferer)
+	if !ok {
+		return nil, apperrors.New(apperrors.ErrBlockchain, "the configured chain backend does not support transfers")
+	}
+
+	result, err := transferer.Transfer(ctx, record.Type, record.TokenID, record.OwnerAddr, req.ToAddress)
+	if err != nil {
+		if errors.Is(err, nft.ErrNotTokenOwner) {
+			return nil, apperrors.Wrap(err, apperrors.ErrConflict, "ownership on chain differs from the registry")
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrBlockchain, "failed to transfer NFT")
+	}
+
+	transfer := &models.NFTTransfer{
+		FromAddr:    record.OwnerAddr,
+		ToAddr:      req.ToAddress,
+		TxHash:      result.TxHash,
+		BlockNumber: result.BlockNumber,
+		InitiatedBy: &userID,
+	}
+	if err := s.nftRepo.RecordTransfer(record, transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *NFTService) getNFT(nftType string, tokenID uint) (*models.NFTMetadata, error) {
+	if err := validateNFTType(nftType); err != nil {
+		return nil, err
+	}
+
+	record, err := s.nftRepo.GetByToken(nftType, tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("NFT")
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func validateNFTType(nftType string) error {
+	if nftType != models.NFTTypePaper && nftType != models.NFTTypeReview {
+		return apperrors.BadRequest("type must be paper or review")
+	}
+	return nil
+}
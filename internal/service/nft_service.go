@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrUnresumableMint is returned for a job whose transaction was broadcast by a
+// minter that cannot pick it up again; it needs manual reconciliation.
+var ErrUnresumableMint = errors.New("mint transaction was sent but the minter cannot resume it")
+
+type NFTService struct {
+	nftRepo     *repository.NFTRepository
+	userRepo    *repository.UserRepository
+	paperRepo   *repository.PaperRepository
+	reviewRepo  *repository.ReviewRepository
+	mintJobRepo *repository.MintJobRepository
+	minter      nft.Minter
+	storage     storage.Storage
+	config      *config.Config
+}
+
+func NewNFTService(
+	nftRepo *repository.NFTRepository,
+	userRepo *repository.UserRepository,
+	paperRepo *repository.PaperRepository,
+	reviewRepo *repository.ReviewRepository,
+	mintJobRepo *repository.MintJobRepository,
+	minter nft.Minter,
+	fileStorage storage.Storage,
+	cfg *config.Config,
+) *NFTService {
+	return &NFTService{
+		nftRepo:     nftRepo,
+		userRepo:    userRepo,
+		paperRepo:   paperRepo,
+		reviewRepo:  reviewRepo,
+		mintJobRepo: mintJobRepo,
+		minter:      minter,
+		storage:     fileStorage,
+		config:      cfg,
+	}
+}
+
+// RequestPaperMint queues minting of a published paper. The token goes to the
+// owner's wallet; userID is the admin requesting the mint.
+func (s *NFTService) RequestPaperMint(paperID, userID uint) (*models.MintJob, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPaperMintable(paper); err != nil {
+		return nil, err
+	}
+
+	return s.mintJobRepo.Enqueue(models.NFTTypePaper, paper.ID, userID, s.config.MintQueue.MaxAttempts)
+}
+
+// RequestReviewMint queues minting of a review of a published paper. The token
+// goes to the reviewer's wallet; userID is the admin requesting the mint.
+func (s *NFTService) RequestReviewMint(reviewID, userID uint) (*models.MintJob, error) {
+	review, err := s.getReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReviewMintable(review); err != nil {
+		return nil, err
+	}
+
+	return s.mintJobRepo.Enqueue(models.NFTTypeReview, review.ID, userID, s.config.MintQueue.MaxAttempts)
+}
+
+// GetMintJob returns a mint job requested by userID
+func (s *NFTService) GetMintJob(id, userID uint) (*models.MintJob, error) {
+	job, err := s.mintJobRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("Mint job")
+		}
+		return nil, err
+	}
+
+	if job.RequestedBy != userID {
+		return nil, apperrors.NotFound("Mint job")
+	}
+
+	return job, nil
+}
+
+// ExecuteMintJob mints the token a job refers to and records it. It is safe to
+// call repeatedly for the same job: an already recorded token is returned as
+// is, and a job whose transaction was already sent is resumed instead of
+// minting again.
+func (s *NFTService) ExecuteMintJob(ctx context.Context, job *models.MintJob) (*models.NFTMetadata, error) {
+	existing, err := s.nftRepo.GetByReference(job.Type, job.ReferenceID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var result *nft.MintResult
+	if job.TxHash != "" {
+		resumer, ok := s.minter.(nft.Resumer)
+		if !ok {
+			return nil, ErrUnresumableMint
+		}
+		result, err = resumer.ResumeMint(ctx, job.Type, job.TxHash)
+	} else {
+		result, err = s.mint(ctx, job)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &models.NFTMetadata{
+		TokenID:      result.TokenID,
+		Type:         job.Type,
+		ReferenceID:  job.ReferenceID,
+		ContractAddr: result.ContractAddr,
+		TxHash:       result.TxHash,
+		BlockNumber:  result.BlockNumber,
+		OwnerAddr:    result.Owner,
+	}
+
+	// The token exists on chain at this point, so a storage outage must not fail
+	// the job; the metadata is published on first request instead.
+	if uri, _, err := s.publishMetadata(ctx, job.Type, job.ReferenceID); err != nil {
+		logger.Warn("Failed to publish NFT metadata", "type", job.Type, "reference_id", job.ReferenceID, "error", err)
+	} else {
+		metadata.MetadataURI = uri
+	}
+
+	if err := s.nftRepo.RecordMint(metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// mint re-checks that the referenced paper or review is still mintable and sends the mint
+func (s *NFTService) mint(ctx context.Context, job *models.MintJob) (*nft.MintResult, error) {
+	switch job.Type {
+	case models.NFTTypePaper:
+		paper, err := s.getPaper(job.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPaperMintable(paper); err != nil {
+			return nil, err
+		}
+
+		result, err := s.minter.MintPaper(ctx, paper)
+		if err != nil {
+			return nil, mintError(err, "failed to mint paper NFT")
+		}
+		return result, nil
+
+	case models.NFTTypeReview:
+		review, err := s.getReview(job.ReferenceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkReviewMintable(review); err != nil {
+			return nil, err
+		}
+		if err := s.pinReview(ctx, review); err != nil {
+			return nil, err
+		}
+
+		result, err := s.minter.MintReview(ctx, review)
+		if err != nil {
+			return nil, mintError(err, "failed to mint review NFT")
+		}
+		return result, nil
+
+	default:
+		return nil, apperrors.BadRequest("unknown NFT type: " + job.Type)
+	}
+}
+
+func (s *NFTService) getPaper(id uint) (*models.Paper, error) {
+	paper, err := s.paperRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrPaperNotFound, "Paper not found")
+		}
+		return nil, err
+	}
+	return paper, nil
+}
+
+func (s *NFTService) getReview(id uint) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrReviewNotFound, "Review not found")
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+func checkPaperMintable(paper *models.Paper) error {
+	if paper.Status != models.PaperStatusPublished {
+		return apperrors.New(apperrors.ErrNotPublished, "only published papers can be minted")
+	}
+	if paper.NFTTokenID != nil {
+		return apperrors.New(apperrors.ErrAlreadyMinted, "paper has already been minted")
+	}
+	return nil
+}
+
+func checkReviewMintable(review *models.Review) error {
+	if review.Paper.Status != models.PaperStatusPublished {
+		return apperrors.New(apperrors.ErrNotPublished, "only reviews of published papers can be minted")
+	}
+	if review.NFTTokenID != nil {
+		return apperrors.New(apperrors.ErrAlreadyMinted, "review has already been minted")
+	}
+	return nil
+}
+
+// mintError converts a Minter failure into an application error
+func mintError(err error, message string) error {
+	if errors.Is(err, nft.ErrNoWallet) {
+		return apperrors.Wrap(err, apperrors.ErrNoWallet, "a linked wallet address is required to receive the NFT")
+	}
+	return apperrors.Wrap(err, apperrors.ErrBlockchain, message)
+}
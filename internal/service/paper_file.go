@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// Content types accepted as manuscripts
+var manuscriptTypes = map[string]bool{
+	"application/pdf": true,
+}
+
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+var errFileTooLarge = errors.New("file exceeds the maximum upload size")
+
+// UploadFileRequest is a manuscript being uploaded for a paper
+type UploadFileRequest struct {
+	FileName string
+	Content  io.Reader
+}
+
+// UploadFile streams a manuscript to storage and records its CID, size, SHA-256
+// and content type on the paper. The previous manuscript, if any, is unpinned.
+func (s *PaperService) UploadFile(ctx context.Context, id, userID uint, req *UploadFileRequest) (*models.Paper, error) {
+	paper, err := s.GetPaper(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user owns the paper
+	if paper.OwnerID != userID {
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to upload a file for this paper")
+	}
+
+	// The manuscript is frozen while it is being reviewed and after publication
+	if paper.Status != models.PaperStatusDraft && paper.Status != models.PaperStatusRevisionRequested {
+		return nil, apperrors.Conflict("the manuscript can only be replaced while the paper is a draft or awaiting revision")
+	}
+
+	// Detect the content type from the leading bytes rather than trusting the client
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(req.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, apperrors.BadRequest("Failed to read uploaded file")
+	}
+	if n == 0 {
+		return nil, apperrors.BadRequest("Uploaded file is empty")
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !manuscriptTypes[contentType] {
+		return nil, apperrors.New(apperrors.ErrUnsupportedMediaType, "unsupported file type: "+contentType).
+			WithDetails(map[string]interface{}{"allowed": []string{"application/pdf"}})
+	}
+
+	content := &manuscriptReader{
+		r:     io.MultiReader(bytes.NewReader(head[:n]), req.Content),
+		hash:  sha256.New(),
+		limit: s.maxFileSize,
+	}
+
+	fileName := filepath.Base(req.FileName)
+	if fileName == "." || fileName == string(filepath.Separator) {
+		fileName = fmt.Sprintf("paper-%d.pdf", paper.ID)
+	}
+
+	object, err := s.storage.Put(ctx, fileName, content)
+	if err != nil {
+		if errors.Is(content.err, errFileTooLarge) {
+			return nil, apperrors.New(apperrors.ErrFileTooLarge, errFileTooLarge.Error()).
+				WithDetails(map[string]interface{}{"max_bytes": s.maxFileSize})
+		}
+		if content.err != nil {
+			return nil, apperrors.BadRequest("Failed to read uploaded file")
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrStorage, "failed to store file")
+	}
+
+	previous := paper.IPFSHash
+	paper.IPFSHash = object.CID
+	paper.FileName = fileName
+	paper.FileSize = content.size
+	paper.FileSHA256 = hex.EncodeToString(content.hash.Sum(nil))
+	paper.FileType = contentType
+
+	if err := s.paperRepo.Update(paper); err != nil {
+		return nil, err
+	}
+
+	if previous != "" && previous != object.CID {
+		if err := s.storage.Unpin(ctx, previous); err != nil {
+			logger.Warn("Failed to unpin replaced manuscript", "paper_id", paper.ID, "cid", previous, "error", err)
+		}
+	}
+
+	return paper, nil
+}
+
+// OpenFile returns a paper and a seekable reader over its manuscript. The caller must close the reader.
+func (s *PaperService) OpenFile(ctx context.Context, id uint) (*models.Paper, io.ReadSeekCloser, error) {
+	paper, err := s.GetPaper(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if paper.IPFSHash == "" {
+		return nil, nil, apperrors.NotFound("Paper file")
+	}
+
+	content, err := s.storage.Get(ctx, paper.IPFSHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, apperrors.NotFound("Paper file")
+		}
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrStorage, "failed to read file")
+	}
+
+	return paper, content, nil
+}
+
+// manuscriptReader hashes and counts the bytes read through it, failing once
+// more than limit bytes have been read. It remembers the first read error so
+// callers can tell it apart from errors of the upload itself.
+type manuscriptReader struct {
+	r     io.Reader
+	hash  hash.Hash
+	size  int64
+	limit int64
+	err   error
+}
+
+func (m *manuscriptReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	m.hash.Write(p[:n])
+	m.size += int64(n)
+
+	if m.limit > 0 && m.size > m.limit {
+		err = errFileTooLarge
+	}
+	if err != nil && err != io.EOF && m.err == nil {
+		m.err = err
+	}
+	return n, err
+}
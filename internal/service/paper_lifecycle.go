@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+)
+
+// PaperAction is a lifecycle transition that can be applied to a paper
+type PaperAction string
+
+const (
+	PaperActionSubmit          PaperAction = "submit"
+	PaperActionWithdraw        PaperAction = "withdraw"
+	PaperActionStartReview     PaperAction = "start_review"
+	PaperActionRequestRevision PaperAction = "request_revision"
+	PaperActionAccept          PaperAction = "accept"
+	PaperActionReject          PaperAction = "reject"
+	PaperActionPublish         PaperAction = "publish"
+)
+
+// paperTransitions maps each action to the statuses it may be applied from and
+// the status it leads to:
+//
+//	draft -> submitted -> under_review -> revision_requested | accepted | rejected
+//	revision_requested -> submitted (resubmission) or draft (withdrawn for rework)
+//	accepted -> published
+var paperTransitions = map[PaperAction]map[string]string{
+	PaperActionSubmit: {
+		models.PaperStatusDraft:             models.PaperStatusSubmitted,
+		models.PaperStatusRevisionRequested: models.PaperStatusSubmitted,
+	},
+	PaperActionWithdraw: {
+		models.PaperStatusSubmitted:         models.PaperStatusDraft,
+		models.PaperStatusUnderReview:       models.PaperStatusDraft,
+		models.PaperStatusRevisionRequested: models.PaperStatusDraft,
+	},
+	PaperActionStartReview: {
+		models.PaperStatusSubmitted: models.PaperStatusUnderReview,
+	},
+	PaperActionRequestRevision: {
+		models.PaperStatusUnderReview: models.PaperStatusRevisionRequested,
+	},
+	PaperActionAccept: {
+		models.PaperStatusUnderReview: models.PaperStatusAccepted,
+	},
+	PaperActionReject: {
+		models.PaperStatusUnderReview: models.PaperStatusRejected,
+	},
+	PaperActionPublish: {
+		models.PaperStatusAccepted: models.PaperStatusPublished,
+	},
+}
+
+// NextPaperStatus returns the status a paper moves to when action is applied in
+// status current, or an INVALID_STATUS_TRANSITION error if the move is illegal.
+func NextPaperStatus(current string, action PaperAction) (string, error) {
+	from, ok := paperTransitions[action]
+	if !ok {
+		return "", apperrors.BadRequest(fmt.Sprintf("unknown paper action: %s", action))
+	}
+
+	next, ok := from[current]
+	if !ok {
+		allowed := make([]string, 0, len(from))
+		for status := range from {
+			allowed = append(allowed, status)
+		}
+		sort.Strings(allowed)
+
+		return "", apperrors.New(apperrors.ErrInvalidTransition,
+			fmt.Sprintf("cannot %s a paper in %s status", action, current)).
+			WithDetails(map[string]interface{}{
+				"action":         action,
+				"current_status": current,
+				"allowed_from":   allowed,
+			})
+	}
+
+	return next, nil
+}
+
+// CanTransition reports whether action is legal for a paper in status current
+func CanTransition(current string, action PaperAction) bool {
+	_, err := NextPaperStatus(current, action)
+	return err == nil
+}
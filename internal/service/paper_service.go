@@ -4,13 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"time"
 
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"gorm.io/gorm"
 )
 
 type PaperService struct {
-	paperRepo *repository.PaperRepository
+	paperRepo      *repository.PaperRepository
+	userRepo       *repository.UserRepository
+	assignmentRepo *repository.ReviewAssignmentRepository
+	storage        storage.Storage
+	maxFileSize    int64
 }
 
 type CreatePaperRequest struct {
@@ -29,9 +37,19 @@ type UpdatePaperRequest struct {
 	Category string   `json:"category"`
 }
 
-func NewPaperService(paperRepo *repository.PaperRepository) *PaperService {
+// SetReviewPolicyRequest overrides the review policy of a paper; an empty
+// policy makes the paper follow its category again
+type SetReviewPolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+func NewPaperService(paperRepo *repository.PaperRepository, userRepo *repository.UserRepository, assignmentRepo *repository.ReviewAssignmentRepository, fileStorage storage.Storage, maxFileSize int64) *PaperService {
 	return &PaperService{
-		paperRepo: paperRepo,
+		paperRepo:      paperRepo,
+		userRepo:       userRepo,
+		assignmentRepo: assignmentRepo,
+		storage:        fileStorage,
+		maxFileSize:    maxFileSize,
 	}
 }
 
@@ -54,7 +72,7 @@ func (s *PaperService) CreatePaper(req *CreatePaperRequest, ownerID uint) (*mode
 		Keywords: keywordsJSON,
 		Category: req.Category,
 		OwnerID:  ownerID,
-		Status:   "draft",
+		Status:   models.PaperStatusDraft,
 	}
 
 	if err := s.paperRepo.Create(paper); err != nil {
@@ -65,18 +83,25 @@ func (s *PaperService) CreatePaper(req *CreatePaperRequest, ownerID uint) (*mode
 }
 
 func (s *PaperService) GetPaper(id uint) (*models.Paper, error) {
-	return s.paperRepo.GetByID(id)
+	paper, err := s.paperRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrPaperNotFound, "Paper not found")
+		}
+		return nil, err
+	}
+	return paper, nil
 }
 
 func (s *PaperService) UpdatePaper(id uint, req *UpdatePaperRequest, userID uint) (*models.Paper, error) {
-	paper, err := s.paperRepo.GetByID(id)
+	paper, err := s.GetPaper(id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if user owns the paper
 	if paper.OwnerID != userID {
-		return nil, errors.New("unauthorized to update this paper")
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to update this paper")
 	}
 
 	// Update fields if provided
@@ -112,14 +137,14 @@ func (s *PaperService) UpdatePaper(id uint, req *UpdatePaperRequest, userID uint
 }
 
 func (s *PaperService) DeletePaper(id, userID uint) error {
-	paper, err := s.paperRepo.GetByID(id)
+	paper, err := s.GetPaper(id)
 	if err != nil {
 		return err
 	}
 
 	// Check if user owns the paper
 	if paper.OwnerID != userID {
-		return errors.New("unauthorized to delete this paper")
+		return apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to delete this paper")
 	}
 
 	return s.paperRepo.Delete(id)
@@ -141,22 +166,104 @@ func (s *PaperService) SearchPapers(query string, page, limit int) ([]models.Pap
 }
 
 func (s *PaperService) SubmitForReview(id, userID uint) (*models.Paper, error) {
-	paper, err := s.paperRepo.GetByID(id)
+	paper, err := s.GetPaper(id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if user owns the paper
 	if paper.OwnerID != userID {
-		return nil, errors.New("unauthorized to submit this paper")
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to submit this paper")
 	}
 
-	// Check if paper is in draft status
-	if paper.Status != "draft" {
-		return nil, errors.New("paper is not in draft status")
+	owner, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if owner.EmailVerifiedAt == nil {
+		return nil, apperrors.New(apperrors.ErrEmailNotVerified, "verify your email address before submitting papers")
 	}
 
-	paper.Status = "submitted"
+	paper, err = s.applyTransition(paper, PaperActionSubmit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reviewers of a paper decided on before owe the revision a new look,
+	// however it came back: their assignments are accepted again until they
+	// update their reviews for the current round
+	if paper.ReviewRound > 0 {
+		if err := s.assignmentRepo.ReopenCompleted(paper.ID, paper.ReviewRound); err != nil {
+			return nil, err
+		}
+	}
+
+	return paper, nil
+}
+
+// WithdrawPaper moves a submitted or in-review paper back to draft so the owner can rework it
+func (s *PaperService) WithdrawPaper(id, userID uint) (*models.Paper, error) {
+	paper, err := s.GetPaper(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user owns the paper
+	if paper.OwnerID != userID {
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to withdraw this paper")
+	}
+
+	return s.applyTransition(paper, PaperActionWithdraw)
+}
+
+// PublishPaper publishes an accepted paper
+func (s *PaperService) PublishPaper(id, editorID uint) (*models.Paper, error) {
+	paper, err := s.GetPaper(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors cannot make editorial decisions on their own papers
+	if paper.OwnerID == editorID {
+		return nil, apperrors.Forbidden("authors cannot publish their own papers")
+	}
+
+	return s.applyTransition(paper, PaperActionPublish)
+}
+
+// SetReviewPolicy overrides the review policy of a paper, or clears the
+// override if policy is ""
+func (s *PaperService) SetReviewPolicy(id uint, policy string, editorID uint) (*models.Paper, error) {
+	paper, err := s.GetPaper(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors cannot choose how their own papers are reviewed
+	if paper.OwnerID == editorID {
+		return nil, apperrors.Forbidden("authors cannot set the review policy of their own papers")
+	}
+
+	paper.ReviewPolicy = policy
+	if err := s.paperRepo.Update(paper); err != nil {
+		return nil, err
+	}
+
+	return paper, nil
+}
+
+// applyTransition validates action against the paper lifecycle and persists the new status
+func (s *PaperService) applyTransition(paper *models.Paper, action PaperAction) (*models.Paper, error) {
+	next, err := NextPaperStatus(paper.Status, action)
+	if err != nil {
+		return nil, err
+	}
+
+	paper.Status = next
+	if next == models.PaperStatusPublished {
+		now := time.Now()
+		paper.PublishedAt = &now
+	}
 	if err := s.paperRepo.Update(paper); err != nil {
 		return nil, err
 	}
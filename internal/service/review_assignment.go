@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type AssignReviewerRequest struct {
+	ReviewerID uint      `json:"reviewer_id" binding:"required"`
+	DueAt      time.Time `json:"due_at" binding:"required"`
+}
+
+type DeclineAssignmentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AssignReviewer invites a reviewer to review a submitted paper by a due date
+func (s *ReviewService) AssignReviewer(paperID uint, req *AssignReviewerRequest, editorID uint) (*models.ReviewAssignment, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReviewable(paper) {
+		return nil, apperrors.BadRequest("paper is not available for review")
+	}
+	if !req.DueAt.After(time.Now()) {
+		return nil, apperrors.BadRequest("due_at must be in the future")
+	}
+
+	reviewer, err := s.userRepo.GetByID(req.ReviewerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrUserNotFound, "User not found")
+		}
+		return nil, err
+	}
+	if reviewer.Role != models.RoleReviewer && reviewer.Role != models.RoleAdmin {
+		return nil, apperrors.BadRequest("user is not a reviewer")
+	}
+	if paper.OwnerID == reviewer.ID {
+		return nil, apperrors.BadRequest("authors cannot review their own papers")
+	}
+
+	existing, err := s.assignmentRepo.GetOpen(paper.ID, reviewer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperrors.New(apperrors.ErrAlreadyAssigned, "reviewer is already assigned to this paper")
+	}
+
+	assignment := &models.ReviewAssignment{
+		PaperID:    paper.ID,
+		ReviewerID: reviewer.ID,
+		EditorID:   editorID,
+		Status:     models.AssignmentStatusInvited,
+		DueAt:      req.DueAt,
+	}
+	if err := s.assignmentRepo.Create(assignment); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Reviewer invited", "paper_id", paper.ID, "reviewer_id", reviewer.ID, "editor_id", editorID, "assignment_id", assignment.ID)
+	return s.GetAssignment(assignment.ID)
+}
+
+func (s *ReviewService) GetAssignment(id uint) (*models.ReviewAssignment, error) {
+	assignment, err := s.assignmentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrAssignmentNotFound, "Assignment not found")
+		}
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// GetPaperAssignments returns every reviewer invited to a paper
+func (s *ReviewService) GetPaperAssignments(paperID uint) ([]models.ReviewAssignment, error) {
+	if _, err := s.getPaper(paperID); err != nil {
+		return nil, err
+	}
+	return s.assignmentRepo.ListByPaper(paperID)
+}
+
+// GetReviewerAssignments returns the assignments of a reviewer, optionally
+// only those with status
+func (s *ReviewService) GetReviewerAssignments(reviewerID uint, status string, page, limit int) ([]models.ReviewAssignment, error) {
+	offset := (page - 1) * limit
+	return s.assignmentRepo.ListByReviewer(reviewerID, status, limit, offset)
+}
+
+// AcceptAssignment accepts an invitation, committing the reviewer to review the paper
+func (s *ReviewService) AcceptAssignment(id, reviewerID uint) (*models.ReviewAssignment, error) {
+	assignment, err := s.getOwnAssignment(id, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReviewable(&assignment.Paper) {
+		return nil, apperrors.BadRequest("paper is not available for review")
+	}
+
+	return s.respondToAssignment(assignment, []string{models.AssignmentStatusInvited}, models.AssignmentStatusAccepted, "")
+}
+
+// DeclineAssignment turns down an invitation, or withdraws from an accepted
+// assignment before the review is submitted
+func (s *ReviewService) DeclineAssignment(id, reviewerID uint, reason string) (*models.ReviewAssignment, error) {
+	assignment, err := s.getOwnAssignment(id, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.respondToAssignment(assignment,
+		[]string{models.AssignmentStatusInvited, models.AssignmentStatusAccepted}, models.AssignmentStatusDeclined, reason)
+}
+
+// CancelAssignment withdraws an invitation that was not completed yet
+func (s *ReviewService) CancelAssignment(id, editorID uint) (*models.ReviewAssignment, error) {
+	assignment, err := s.GetAssignment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.assignmentRepo.Cancel(assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, apperrors.Conflict("cannot cancel a " + assignment.Status + " assignment")
+	}
+
+	logger.Info("Review assignment cancelled", "assignment_id", assignment.ID, "editor_id", editorID)
+	return s.GetAssignment(assignment.ID)
+}
+
+// getOwnAssignment loads an assignment of reviewerID; assignments of other
+// reviewers are reported as missing
+func (s *ReviewService) getOwnAssignment(id, reviewerID uint) (*models.ReviewAssignment, error) {
+	assignment, err := s.GetAssignment(id)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.ReviewerID != reviewerID {
+		return nil, apperrors.New(apperrors.ErrAssignmentNotFound, "Assignment not found")
+	}
+	return assignment, nil
+}
+
+func (s *ReviewService) respondToAssignment(assignment *models.ReviewAssignment, from []string, status, reason string) (*models.ReviewAssignment, error) {
+	updated, err := s.assignmentRepo.Respond(assignment.ID, assignment.ReviewerID, from, status, reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, apperrors.Conflict("assignment is already " + assignment.Status)
+	}
+
+	logger.Info("Review assignment "+status, "assignment_id", assignment.ID, "reviewer_id", assignment.ReviewerID)
+	return s.GetAssignment(assignment.ID)
+}
+
+// acceptedAssignment returns the accepted assignment of a reviewer to a paper
+func (s *ReviewService) acceptedAssignment(paperID, reviewerID uint) (*models.ReviewAssignment, error) {
+	assignment, err := s.assignmentRepo.GetOpen(paperID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment == nil || assignment.Status == models.AssignmentStatusInvited {
+		return nil, apperrors.New(apperrors.ErrNotAssigned, "you have no accepted assignment to review this paper")
+	}
+	if assignment.Status == models.AssignmentStatusCompleted {
+		return nil, apperrors.New(apperrors.ErrAlreadyReviewed, "you have already reviewed this paper")
+	}
+	return assignment, nil
+}
+
+// isReviewable reports whether a paper is waiting for reviews
+func isReviewable(paper *models.Paper) bool {
+	return paper.Status == models.PaperStatusSubmitted || paper.Status == models.PaperStatusUnderReview
+}
+
+// checkReviewOpen refuses changes to the reviews of a paper once they were
+// decided on: reviews only change while the paper is under review, or
+// submitted again for a new round
+func checkReviewOpen(paper *models.Paper, action string) error {
+	if !isReviewable(paper) {
+		return apperrors.New(apperrors.ErrInvalidTransition,
+			fmt.Sprintf("cannot %s a review of a paper in %s status", action, paper.Status))
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/datatypes"
+)
+
+// defaultReviewPolicy applies until an admin sets a platform default
+const defaultReviewPolicy = models.ReviewPolicySingleBlind
+
+// Viewer is the user a paper or review is shown to
+type Viewer struct {
+	UserID uint
+	Editor bool // Editors make decisions on papers and see every identity
+}
+
+// PaperView is a paper as shown to a viewer. The fields shadow those of the
+// embedded paper, so hidden identities are left out of the JSON.
+type PaperView struct {
+	models.Paper
+	OwnerID       *uint          `json:"owner_id,omitempty"`
+	Owner         *models.User   `json:"owner,omitempty"`
+	Authors       datatypes.JSON `json:"authors,omitempty"`
+	AuthorsHidden bool           `json:"authors_hidden,omitempty"`
+	ReviewPolicy  string         `json:"review_policy"` // The policy in effect, inherited or not
+	Reviews       []ReviewView   `json:"reviews,omitempty"`
+}
+
+// ReviewView is a review as shown to a viewer. Hidden reviewers are only
+// named by their alias, and confidential comments only reach editors.
+type ReviewView struct {
+	models.Review
+	ReviewerID    *uint        `json:"reviewer_id,omitempty"`
+	Reviewer      *models.User `json:"reviewer,omitempty"`
+	ReviewerAlias string       `json:"reviewer_alias"` // "Reviewer 2", stable for the paper
+	Paper         *PaperView   `json:"paper,omitempty"`
+	// Shown to editors and the reviewer who wrote them only
+	ConfidentialComments string `json:"confidential_comments,omitempty"`
+}
+
+// AssignmentView is a review assignment as shown to a viewer
+type AssignmentView struct {
+	models.ReviewAssignment
+	Paper *PaperView `json:"paper,omitempty"`
+}
+
+// NFTView is a token as shown to a viewer, with its paper or review shown
+// under the paper's review policy
+type NFTView struct {
+	models.NFTMetadata
+	Paper  *PaperView  `json:"paper,omitempty"`
+	Review *ReviewView `json:"review,omitempty"`
+}
+
+// ReviewPolicySettings are the platform default and per-category review policies
+type ReviewPolicySettings struct {
+	Default    string            `json:"default"`
+	Categories map[string]string `json:"categories"`
+}
+
+// ReviewPolicies decides which review policy applies to a paper and hides
+// identities from viewers accordingly. Under single-blind review, reviewers
+// are shown to everyone but themselves and editors as "Reviewer N"; under
+// double-blind review, the owner and authors of a paper are also hidden from
+// everyone but the owner and editors until the paper is published.
+type ReviewPolicies struct {
+	settingRepo    *repository.SettingRepository
+	paperRepo      *repository.PaperRepository
+	assignmentRepo *repository.ReviewAssignmentRepository
+}
+
+func NewReviewPolicies(settingRepo *repository.SettingRepository, paperRepo *repository.PaperRepository, assignmentRepo *repository.ReviewAssignmentRepository) *ReviewPolicies {
+	return &ReviewPolicies{
+		settingRepo:    settingRepo,
+		paperRepo:      paperRepo,
+		assignmentRepo: assignmentRepo,
+	}
+}
+
+// Settings returns the platform default and per-category review policies
+func (p *ReviewPolicies) Settings() (*ReviewPolicySettings, error) {
+	def, err := p.settingRepo.Get(models.SettingReviewPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if def == "" {
+		def = defaultReviewPolicy
+	}
+
+	settings, err := p.settingRepo.ListByPrefix(models.SettingCategoryReviewPolicy)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		categories[strings.TrimPrefix(setting.Key, models.SettingCategoryReviewPolicy)] = setting.Value
+	}
+
+	return &ReviewPolicySettings{Default: def, Categories: categories}, nil
+}
+
+// SetDefault sets the review policy of papers whose category has none
+func (p *ReviewPolicies) SetDefault(policy string) error {
+	if err := p.settingRepo.Set(models.SettingReviewPolicy, policy); err != nil {
+		return err
+	}
+	logger.Info("Default review policy changed", "policy", policy)
+	return nil
+}
+
+// SetCategoryPolicy sets the review policy of a category, or removes it if
+// policy is "" so the category follows the default
+func (p *ReviewPolicies) SetCategoryPolicy(category, policy string) error {
+	key := models.SettingCategoryReviewPolicy + normalizeCategory(category)
+	var err error
+	if policy == "" {
+		err = p.settingRepo.Delete(key)
+	} else {
+		err = p.settingRepo.Set(key, policy)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("Category review policy changed", "category", category, "policy", policy)
+	return nil
+}
+
+// PaperView shows a paper and its loaded reviews to viewer
+func (p *ReviewPolicies) PaperView(paper *models.Paper, viewer Viewer) (*PaperView, error) {
+	settings, err := p.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	view := settings.paperView(paper, viewer)
+	if len(paper.Reviews) > 0 {
+		aliases, err := p.aliases(paper.ID, paper.Reviews)
+		if err != nil {
+			return nil, err
+		}
+		view.Reviews = make([]ReviewView, 0, len(paper.Reviews))
+		for i := range paper.Reviews {
+			view.Reviews = append(view.Reviews, reviewView(&paper.Reviews[i], view.ReviewPolicy, aliases, viewer))
+		}
+	}
+	return view, nil
+}
+
+// PaperViews shows papers to viewer, without their reviews
+func (p *ReviewPolicies) PaperViews(papers []models.Paper, viewer Viewer) ([]PaperView, error) {
+	settings, err := p.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]PaperView, 0, len(papers))
+	for i := range papers {
+		view := settings.paperView(&papers[i], viewer)
+		view.Reviews = nil
+		views = append(views, *view)
+	}
+	return views, nil
+}
+
+// ReviewViews shows reviews, and their papers if loaded, to viewer
+func (p *ReviewPolicies) ReviewViews(reviews []models.Review, viewer Viewer) ([]ReviewView, error) {
+	settings, err := p.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[uint]string)
+	aliases := make(map[uint]map[uint]int)
+	views := make([]ReviewView, 0, len(reviews))
+	for i := range reviews {
+		review := &reviews[i]
+		if _, ok := policies[review.PaperID]; !ok {
+			paper := &review.Paper
+			if paper.ID == 0 {
+				if paper, err = p.paperRepo.GetByID(review.PaperID); err != nil {
+					return nil, err
+				}
+			}
+			policies[review.PaperID] = settings.policyOf(paper)
+			if aliases[review.PaperID], err = p.aliases(review.PaperID, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		view := reviewView(review, policies[review.PaperID], aliases[review.PaperID], viewer)
+		if review.Paper.ID != 0 {
+			view.Paper = settings.paperView(&review.Paper, viewer)
+			view.Paper.Reviews = nil
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ReviewView shows a review, and its paper if loaded, to viewer
+func (p *ReviewPolicies) ReviewView(review *models.Review, viewer Viewer) (*ReviewView, error) {
+	views, err := p.ReviewViews([]models.Review{*review}, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// AssignmentViews shows review assignments, and their papers if loaded, to viewer
+func (p *ReviewPolicies) AssignmentViews(assignments []models.ReviewAssignment, viewer Viewer) ([]AssignmentView, error) {
+	settings, err := p.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]AssignmentView, 0, len(assignments))
+	for i := range assignments {
+		view := AssignmentView{ReviewAssignment: assignments[i]}
+		if assignments[i].Paper.ID != 0 {
+			view.Paper = settings.paperView(&assignments[i].Paper, viewer)
+			view.Paper.Reviews = nil
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// AssignmentView shows a review assignment, and its paper if loaded, to viewer
+func (p *ReviewPolicies) AssignmentView(assignment *models.ReviewAssignment, viewer Viewer) (*AssignmentView, error) {
+	views, err := p.AssignmentViews([]models.ReviewAssignment{*assignment}, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// NFTView shows a token, and the paper or review it was minted for, to viewer
+func (p *ReviewPolicies) NFTView(detail *NFTDetail, viewer Viewer) (*NFTView, error) {
+	view := &NFTView{NFTMetadata: detail.NFTMetadata}
+	var err error
+	if detail.Paper != nil {
+		if view.Paper, err = p.PaperView(detail.Paper, viewer); err != nil {
+			return nil, err
+		}
+	}
+	if detail.Review != nil {
+		if view.Review, err = p.ReviewView(detail.Review, viewer); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// aliases numbers the reviewers of a paper in the order they were invited,
+// followed by any reviewers of reviews who were never invited
+func (p *ReviewPolicies) aliases(paperID uint, reviews []models.Review) (map[uint]int, error) {
+	assignments, err := p.assignmentRepo.ListByPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[uint]int, len(assignments))
+	for _, assignment := range assignments {
+		if _, ok := aliases[assignment.ReviewerID]; !ok {
+			aliases[assignment.ReviewerID] = len(aliases) + 1
+		}
+	}
+	for _, review := range reviews {
+		if _, ok := aliases[review.ReviewerID]; !ok {
+			aliases[review.ReviewerID] = len(aliases) + 1
+		}
+	}
+	return aliases, nil
+}
+
+// policyOf returns the review policy in effect for paper
+func (s *ReviewPolicySettings) policyOf(paper *models.Paper) string {
+	if paper.ReviewPolicy != "" {
+		return paper.ReviewPolicy
+	}
+	if policy, ok := s.Categories[normalizeCategory(paper.Category)]; ok {
+		return policy
+	}
+	return s.Default
+}
+
+func (s *ReviewPolicySettings) paperView(paper *models.Paper, viewer Viewer) *PaperView {
+	view := &PaperView{
+		Paper:        *paper,
+		ReviewPolicy: s.policyOf(paper),
+	}
+
+	hidden := view.ReviewPolicy == models.ReviewPolicyDoubleBlind &&
+		paper.Status != models.PaperStatusPublished &&
+		!viewer.Editor && viewer.UserID != paper.OwnerID
+	if hidden {
+		view.AuthorsHidden = true
+		return view
+	}
+
+	ownerID := paper.OwnerID
+	view.OwnerID = &ownerID
+	view.Authors = paper.Authors
+	if paper.Owner.ID != 0 {
+		view.Owner = &paper.Owner
+	}
+	return view
+}
+
+func reviewView(review *models.Review, policy string, aliases map[uint]int, viewer Viewer) ReviewView {
+	view := ReviewView{
+		Review:        *review,
+		ReviewerAlias: fmt.Sprintf("Reviewer %d", aliases[review.ReviewerID]),
+	}
+
+	if viewer.Editor || viewer.UserID == review.ReviewerID {
+		view.ConfidentialComments = review.ConfidentialComments
+	}
+
+	visible := policy == models.ReviewPolicyOpen || viewer.Editor || viewer.UserID == review.ReviewerID
+	if visible {
+		reviewerID := review.ReviewerID
+		view.ReviewerID = &reviewerID
+		if review.Reviewer.ID != 0 {
+			view.Reviewer = &review.Reviewer
+		}
+	}
+	return view
+}
+
+// normalizeCategory makes category policies apply regardless of case
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/rubric"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// ReviewRubricSettings are the platform default and per-category rubrics
+type ReviewRubricSettings struct {
+	Default    rubric.Rubric            `json:"default"`
+	Categories map[string]rubric.Rubric `json:"categories"`
+}
+
+// ReviewRubrics stores the rubrics reviews are filled in on. A paper is
+// reviewed on the rubric of its category, or the platform default. Saving a
+// rubric gives it a new version, recorded with every review written on it.
+type ReviewRubrics struct {
+	settingRepo *repository.SettingRepository
+}
+
+func NewReviewRubrics(settingRepo *repository.SettingRepository) *ReviewRubrics {
+	return &ReviewRubrics{settingRepo: settingRepo}
+}
+
+// Settings returns the platform default and per-category rubrics
+func (r *ReviewRubrics) Settings() (*ReviewRubricSettings, error) {
+	def, _, err := r.RubricOf("")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := r.settingRepo.ListByPrefix(models.SettingCategoryReviewRubric)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[string]rubric.Rubric, len(settings))
+	for _, setting := range settings {
+		var categoryRubric rubric.Rubric
+		if err := json.Unmarshal([]byte(setting.Value), &categoryRubric); err != nil {
+			return nil, err
+		}
+		categories[strings.TrimPrefix(setting.Key, models.SettingCategoryReviewRubric)] = categoryRubric
+	}
+
+	return &ReviewRubricSettings{Default: *def, Categories: categories}, nil
+}
+
+// RubricOf returns the rubric papers of category are reviewed on, and the
+// category it was configured for ("" for the default)
+func (r *ReviewRubrics) RubricOf(category string) (*rubric.Rubric, string, error) {
+	if category = normalizeCategory(category); category != "" {
+		found, err := r.load(models.SettingCategoryReviewRubric + category)
+		if err != nil || found != nil {
+			return found, category, err
+		}
+	}
+
+	found, err := r.load(models.SettingReviewRubric)
+	if err != nil {
+		return nil, "", err
+	}
+	if found == nil {
+		def := rubric.Default()
+		found = &def
+	}
+	return found, "", nil
+}
+
+// SetDefault saves the rubric of papers whose category has none and returns
+// it with its new version
+func (r *ReviewRubrics) SetDefault(next rubric.Rubric) (*rubric.Rubric, error) {
+	if err := r.save(models.SettingReviewRubric, &next); err != nil {
+		return nil, err
+	}
+	logger.Info("Default review rubric changed", "version", next.Version)
+	return &next, nil
+}
+
+// SetCategoryRubric saves the rubric of a category and returns it with its new version
+func (r *ReviewRubrics) SetCategoryRubric(category string, next rubric.Rubric) (*rubric.Rubric, error) {
+	if err := r.save(models.SettingCategoryReviewRubric+normalizeCategory(category), &next); err != nil {
+		return nil, err
+	}
+	logger.Info("Category review rubric changed", "category", category, "version", next.Version)
+	return &next, nil
+}
+
+// DeleteCategoryRubric makes a category use the default rubric again
+func (r *ReviewRubrics) DeleteCategoryRubric(category string) error {
+	if err := r.settingRepo.Delete(models.SettingCategoryReviewRubric + normalizeCategory(category)); err != nil {
+		return err
+	}
+	logger.Info("Category review rubric removed", "category", category)
+	return nil
+}
+
+// load returns the rubric saved under key, or nil if none is
+func (r *ReviewRubrics) load(key string) (*rubric.Rubric, error) {
+	value, err := r.settingRepo.Get(key)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var saved rubric.Rubric
+	if err := json.Unmarshal([]byte(value), &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// save stores next under key with the version after the last one assigned,
+// so versions are never reused even when rubrics are removed or saved at once
+func (r *ReviewRubrics) save(key string, next *rubric.Rubric) error {
+	// The built-in default is version 1
+	return r.settingRepo.SetVersioned(models.SettingReviewRubricVersion, 1, key, func(version int) (string, error) {
+		next.Version = version
+		value, err := json.Marshal(next)
+		return string(value), err
+	})
+}
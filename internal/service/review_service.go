@@ -3,21 +3,33 @@ package service
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
 	"github.com/nshmdayo/nft-platform-sample/internal/models"
 	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/rubric"
+	"gorm.io/gorm"
 )
 
 type ReviewService struct {
-	reviewRepo *repository.ReviewRepository
-	paperRepo  *repository.PaperRepository
+	reviewRepo     *repository.ReviewRepository
+	assignmentRepo *repository.ReviewAssignmentRepository
+	paperRepo      *repository.PaperRepository
+	userRepo       *repository.UserRepository
+	rubrics        *ReviewRubrics
 }
 
+// CreateReviewRequest is a review filled in on the rubric of the paper's
+// category. The score is computed from the criteria.
 type CreateReviewRequest struct {
-	PaperID        uint   `json:"paper_id" binding:"required"`
-	Comment        string `json:"comment" binding:"required"`
-	Score          int    `json:"score" binding:"required,min=1,max=10"`
-	Recommendation string `json:"recommendation" binding:"required,oneof=accept reject revision"`
+	PaperID              uint              `json:"paper_id" binding:"required"`
+	Comment              string            `json:"comment" binding:"required"`
+	Criteria             map[string]int    `json:"criteria" binding:"required"` // Score of each rubric criterion by key
+	Sections             map[string]string `json:"sections"`                    // Text of each rubric section by key
+	ConfidentialComments string            `json:"confidential_comments"`       // For editors only, if the rubric allows
+	Confidence           int               `json:"confidence"`                  // Reviewer's confidence in 1-5, optional
+	Recommendation       string            `json:"recommendation" binding:"required,oneof=accept reject revision"`
 }
 
 type ReviewResponse struct {
@@ -32,71 +44,77 @@ type ReviewResponse struct {
 	UpdatedAt      string                 `json:"updated_at"`
 }
 
-func NewReviewService(reviewRepo *repository.ReviewRepository, paperRepo *repository.PaperRepository) *ReviewService {
+func NewReviewService(reviewRepo *repository.ReviewRepository, assignmentRepo *repository.ReviewAssignmentRepository, paperRepo *repository.PaperRepository, userRepo *repository.UserRepository, rubrics *ReviewRubrics) *ReviewService {
 	return &ReviewService{
-		reviewRepo: reviewRepo,
-		paperRepo:  paperRepo,
+		reviewRepo:     reviewRepo,
+		assignmentRepo: assignmentRepo,
+		paperRepo:      paperRepo,
+		userRepo:       userRepo,
+		rubrics:        rubrics,
 	}
 }
 
 func (s *ReviewService) CreateReview(req *CreateReviewRequest, reviewerID uint) (*models.Review, error) {
 	// Check if paper exists and is in submitted status
-	paper, err := s.paperRepo.GetByID(req.PaperID)
+	paper, err := s.getPaper(req.PaperID)
 	if err != nil {
 		return nil, err
 	}
 
-	if paper.Status != "submitted" && paper.Status != "under_review" {
-		return nil, errors.New("paper is not available for review")
+	if !isReviewable(paper) {
+		return nil, apperrors.BadRequest("paper is not available for review")
 	}
 
-	// Check if reviewer already reviewed this paper
+	// Only reviewers who accepted an invitation can review, once
+	assignment, err := s.acceptedAssignment(req.PaperID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
 	existingReview, _ := s.reviewRepo.GetByPaperAndReviewer(req.PaperID, reviewerID)
 	if existingReview != nil {
-		return nil, errors.New("you have already reviewed this paper")
+		return nil, apperrors.New(apperrors.ErrAlreadyReviewed, "you have already reviewed this paper")
 	}
 
-	// Create review metadata
-	metadata := &models.ReviewMetadata{
-		ReviewCriteria: map[string]interface{}{
-			"originality":  0,
-			"methodology":  0,
-			"clarity":      0,
-			"significance": 0,
-		},
-		ReviewType: "peer_review",
-		Version:    1,
+	review := &models.Review{
+		PaperID:    req.PaperID,
+		ReviewerID: reviewerID,
+		Round:      paper.ReviewRound,
 	}
-
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
+	if err := s.fillIn(review, paper, req); err != nil {
 		return nil, err
 	}
 
-	review := &models.Review{
-		PaperID:        req.PaperID,
-		ReviewerID:     reviewerID,
-		Comment:        req.Comment,
-		Score:          req.Score,
-		Recommendation: req.Recommendation,
-		Metadata:       metadataJSON,
-	}
-
-	if err := s.reviewRepo.Create(review); err != nil {
+	// The assignment may have been cancelled or declined meanwhile
+	next := startReview(paper)
+	submitted, err := s.reviewRepo.Submit(review, assignment.ID, paper.Status, next)
+	if err != nil {
 		return nil, err
 	}
-
-	// Update paper status to under_review if it was submitted
-	if paper.Status == "submitted" {
-		paper.Status = "under_review"
-		s.paperRepo.Update(paper)
+	if !submitted {
+		return nil, apperrors.New(apperrors.ErrNotAssigned, "you have no accepted assignment to review this paper")
 	}
 
 	return review, nil
 }
 
+// startReview returns the status a paper moves to when a review of it comes
+// in: the first review of a round moves a submitted paper to under_review
+func startReview(paper *models.Paper) string {
+	if next, err := NextPaperStatus(paper.Status, PaperActionStartReview); err == nil {
+		return next
+	}
+	return paper.Status
+}
+
 func (s *ReviewService) GetReview(id uint) (*models.Review, error) {
-	return s.reviewRepo.GetByID(id)
+	review, err := s.reviewRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrReviewNotFound, "Review not found")
+		}
+		return nil, err
+	}
+	return review, nil
 }
 
 func (s *ReviewService) GetPaperReviews(paperID uint) ([]models.Review, error) {
@@ -109,40 +127,55 @@ func (s *ReviewService) GetReviewerReviews(reviewerID uint, page, limit int) ([]
 }
 
 func (s *ReviewService) UpdateReview(id uint, req *CreateReviewRequest, reviewerID uint) (*models.Review, error) {
-	review, err := s.reviewRepo.GetByID(id)
+	review, err := s.GetReview(id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if user owns the review
 	if review.ReviewerID != reviewerID {
-		return nil, errors.New("unauthorized to update this review")
+		return nil, apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to update this review")
+	}
+	if err := checkReviewOpen(&review.Paper, "update"); err != nil {
+		return nil, err
 	}
 
-	// Update fields
-	review.Comment = req.Comment
-	review.Score = req.Score
-	review.Recommendation = req.Recommendation
+	// Update fields, on the rubric now in effect, for the current round
+	if err := s.fillIn(review, &review.Paper, req); err != nil {
+		return nil, err
+	}
+	review.Round = review.Paper.ReviewRound
 
-	if err := s.reviewRepo.Update(review); err != nil {
+	// Updating a review of a resubmitted paper fulfils the reopened
+	// assignment, and the first such update starts the new round of review
+	next := startReview(&review.Paper)
+	if err := s.reviewRepo.Revise(review, review.Paper.Status, next); err != nil {
 		return nil, err
 	}
+	review.Paper.Status = next
 
 	return review, nil
 }
 
 func (s *ReviewService) DeleteReview(id, reviewerID uint) error {
-	review, err := s.reviewRepo.GetByID(id)
+	review, err := s.GetReview(id)
 	if err != nil {
 		return err
 	}
 
 	// Check if user owns the review
 	if review.ReviewerID != reviewerID {
-		return errors.New("unauthorized to delete this review")
+		return apperrors.New(apperrors.ErrInvalidOwner, "unauthorized to delete this review")
+	}
+	if err := checkReviewOpen(&review.Paper, "delete"); err != nil {
+		return err
 	}
 
-	return s.reviewRepo.Delete(id)
+	if err := s.reviewRepo.Delete(id); err != nil {
+		return err
+	}
+	// The reviewer still owes the paper a review
+	return s.assignmentRepo.Reopen(id)
 }
 
 func (s *ReviewService) CalculatePaperScore(paperID uint) (float64, error) {
@@ -164,34 +197,81 @@ func (s *ReviewService) CalculatePaperScore(paperID uint) (float64, error) {
 }
 
 func (s *ReviewService) CheckReviewEligibility(paperID, userID uint) error {
-	paper, err := s.paperRepo.GetByID(paperID)
+	paper, err := s.getPaper(paperID)
 	if err != nil {
 		return err
 	}
 
 	// Authors cannot review their own papers
 	if paper.OwnerID == userID {
-		return errors.New("authors cannot review their own papers")
+		return apperrors.Forbidden("authors cannot review their own papers")
 	}
 
 	// Check if paper is in a reviewable status
-	if paper.Status != "submitted" && paper.Status != "under_review" {
-		return errors.New("paper is not available for review")
+	if !isReviewable(paper) {
+		return apperrors.BadRequest("paper is not available for review")
 	}
 
-	// Check if user already reviewed this paper
-	existingReview, _ := s.reviewRepo.GetByPaperAndReviewer(paperID, userID)
-	if existingReview != nil {
-		return errors.New("you have already reviewed this paper")
+	// Check if user was invited and accepted, and did not review yet
+	_, err = s.acceptedAssignment(paperID, userID)
+	return err
+}
+
+// GetPaperRubric returns the rubric reviews of a paper are filled in on
+func (s *ReviewService) GetPaperRubric(paperID uint) (*rubric.Rubric, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+	found, _, err := s.rubrics.RubricOf(paper.Category)
+	return found, err
+}
+
+// fillIn evaluates req on the rubric of paper and sets the fields of review
+// from it, including the score computed from the criteria
+func (s *ReviewService) fillIn(review *models.Review, paper *models.Paper, req *CreateReviewRequest) error {
+	paperRubric, category, err := s.rubrics.RubricOf(paper.Category)
+	if err != nil {
+		return err
+	}
+
+	metadata, errs := paperRubric.Evaluate(rubric.Submission{
+		Criteria:             req.Criteria,
+		Sections:             req.Sections,
+		ConfidentialComments: req.ConfidentialComments,
+	}, category)
+	if len(errs) > 0 {
+		return apperrors.Validation("Review does not follow the rubric", errs)
 	}
 
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	review.Comment = req.Comment
+	review.Score = rubric.ReviewScore(metadata.OverallScore, metadata.Scale)
+	review.Recommendation = req.Recommendation
+	review.Confidence = req.Confidence
+	review.ConfidentialComments = strings.TrimSpace(req.ConfidentialComments)
+	review.Metadata = metadataJSON
 	return nil
 }
 
-func (s *ReviewService) GetPendingReviews(reviewerID uint, page, limit int) ([]models.Paper, error) {
-	// This would typically involve a more complex query
-	// For now, we'll get papers that are submitted or under_review
-	// and exclude papers already reviewed by this reviewer
-	offset := (page - 1) * limit
-	return s.paperRepo.GetPendingReviews(reviewerID, limit, offset)
+// getPaper loads a paper and reports a missing one as PAPER_NOT_FOUND
+func (s *ReviewService) getPaper(id uint) (*models.Paper, error) {
+	paper, err := s.paperRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.New(apperrors.ErrPaperNotFound, "Paper not found")
+		}
+		return nil, err
+	}
+	return paper, nil
+}
+
+// GetPendingReviews returns the accepted assignments a reviewer has not
+// submitted a review for yet, soonest due first
+func (s *ReviewService) GetPendingReviews(reviewerID uint, page, limit int) ([]models.ReviewAssignment, error) {
+	return s.GetReviewerAssignments(reviewerID, models.AssignmentStatusAccepted, page, limit)
 }
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/matching"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+)
+
+// ReviewerSuggestions ranks the reviewers who could be invited to a paper
+type ReviewerSuggestions struct {
+	PaperID     uint                  `json:"paper_id"`
+	Suggestions []matching.Suggestion `json:"suggestions"`
+	Conflicts   []matching.Conflict   `json:"conflicts"` // Reviewers excluded for conflicts of interest
+}
+
+// SuggestReviewers ranks up to limit reviewers for a paper under review by
+// how well their interests and own papers match its keywords and category,
+// excluding conflicts of interest and favouring reviewers with fewer open
+// assignments
+func (s *ReviewService) SuggestReviewers(paperID uint, limit int) (*ReviewerSuggestions, error) {
+	paper, err := s.getPaper(paperID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReviewable(paper) {
+		return nil, apperrors.BadRequest("paper is not available for review")
+	}
+
+	reviewers, err := s.userRepo.ListByRoles([]string{models.RoleReviewer, models.RoleAdmin})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		ids = append(ids, reviewer.ID)
+	}
+
+	papers, err := s.paperRepo.GetByOwnerIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	publications := make(map[uint][]matching.Publication)
+	for _, p := range papers {
+		if p.ID == paper.ID {
+			continue
+		}
+		publications[p.OwnerID] = append(publications[p.OwnerID], matching.Publication{
+			Authors:  jsonStrings(p.Authors),
+			Keywords: jsonStrings(p.Keywords),
+			Category: p.Category,
+		})
+	}
+
+	load, err := s.assignmentRepo.CountOpenByReviewers(ids)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.assignmentRepo.ListByPaper(paper.ID)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[uint]bool)
+	for _, assignment := range assignments {
+		switch assignment.Status {
+		case models.AssignmentStatusInvited, models.AssignmentStatusAccepted, models.AssignmentStatusCompleted:
+			assigned[assignment.ReviewerID] = true
+		}
+	}
+
+	candidates := make([]matching.Candidate, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		candidates = append(candidates, matching.Candidate{
+			ID:              reviewer.ID,
+			Name:            reviewer.Name,
+			Institution:     reviewer.Institution,
+			Interests:       reviewer.InterestList(),
+			Publications:    publications[reviewer.ID],
+			OpenAssignments: load[reviewer.ID],
+			Assigned:        assigned[reviewer.ID],
+		})
+	}
+
+	suggestions, conflicts := matching.Rank(matching.Paper{
+		OwnerID:          paper.OwnerID,
+		OwnerInstitution: paper.Owner.Institution,
+		Authors:          jsonStrings(paper.Authors),
+		Keywords:         jsonStrings(paper.Keywords),
+		Category:         paper.Category,
+	}, candidates, limit)
+
+	return &ReviewerSuggestions{
+		PaperID:     paper.ID,
+		Suggestions: suggestions,
+		Conflicts:   conflicts,
+	}, nil
+}
+
+// jsonStrings decodes a JSON string array column, ignoring malformed values
+func jsonStrings(raw []byte) []string {
+	var values []string
+	if len(raw) == 0 || json.Unmarshal(raw, &values) != nil {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs"
+)
+
+// IPFSStorage stores content on a Kubo node
+type IPFSStorage struct {
+	client *ipfs.Client
+}
+
+func NewIPFSStorage(client *ipfs.Client) *IPFSStorage {
+	return &IPFSStorage{client: client}
+}
+
+func (s *IPFSStorage) Put(ctx context.Context, name string, r io.Reader) (*Object, error) {
+	counter := &countingReader{r: r}
+	result, err := s.client.Add(ctx, name, counter)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{CID: result.Hash, Size: counter.n}, nil
+}
+
+// Get returns a reader that streams the content with /cat, reopening the
+// stream at the new offset after every seek
+func (s *IPFSStorage) Get(ctx context.Context, cid string) (io.ReadSeekCloser, error) {
+	obj, err := s.Stat(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	return &ipfsReader{ctx: ctx, client: s.client, cid: cid, size: obj.Size}, nil
+}
+
+func (s *IPFSStorage) Stat(ctx context.Context, cid string) (*Object, error) {
+	stat, err := s.client.Stat(ctx, cid)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &Object{CID: cid, Size: stat.Size}, nil
+}
+
+func (s *IPFSStorage) Pin(ctx context.Context, cid string) error {
+	return notFound(s.client.Pin(ctx, cid))
+}
+
+func (s *IPFSStorage) Unpin(ctx context.Context, cid string) error {
+	return notFound(s.client.Unpin(ctx, cid))
+}
+
+// notFound maps Kubo's "not found" errors, which it reports as HTTP 500, to ErrNotFound
+func notFound(err error) error {
+	var apiErr *ipfs.Error
+	if errors.As(err, &apiErr) && (strings.Contains(apiErr.Message, "not found") || strings.Contains(apiErr.Message, "not pinned")) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ipfsReader is a seekable view of content on a Kubo node
+type ipfsReader struct {
+	ctx    context.Context
+	client *ipfs.Client
+	cid    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (r *ipfsReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.client.CatRange(r.ctx, r.cid, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *ipfsReader) Seek(offset int64, whence int) (int64, error) {
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = r.offset + offset
+	case io.SeekEnd:
+		next = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if next < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if next != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = next
+	return next, nil
+}
+
+func (r *ipfsReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/cid"
+)
+
+// LocalStorage stores content on the filesystem under its CIDv1 (raw, sha2-256),
+// the identifier IPFS assigns to single-block files, so stored identifiers stay
+// valid after migrating the content to an IPFS node.
+//
+// Content lives in <root>/blobs/<last two CID characters>/<cid> and pins are
+// marker files in <root>/pins. There is no garbage collector: unpinned content
+// is removed immediately.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates a filesystem store rooted at root, creating it if needed
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	for _, dir := range []string{"blobs", "pins", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Put writes r to a temporary file while hashing it and moves it into place under its CID
+func (s *LocalStorage) Put(ctx context.Context, name string, r io.Reader) (*Object, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "put-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := cid.FromSHA256(sum(h))
+	path := s.blobPath(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	if err := s.Pin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &Object{CID: id, Size: size}, nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, id string) (io.ReadSeekCloser, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, id string) (*Object, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{CID: id, Size: info.Size()}, nil
+}
+
+func (s *LocalStorage) Pin(ctx context.Context, id string) error {
+	if _, err := s.Stat(ctx, id); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.root, "pins", id), nil, 0o644)
+}
+
+// Unpin removes the pin and the content of id
+func (s *LocalStorage) Unpin(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(s.root, "pins", id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path validates id and returns the file it is stored in. Only canonical CIDs
+// are accepted, so user input can never escape the storage root.
+func (s *LocalStorage) path(id string) (string, error) {
+	digest, err := cid.ParseSHA256(id)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	if cid.FromSHA256(digest) != id {
+		return "", ErrNotFound
+	}
+	return s.blobPath(id), nil
+}
+
+func (s *LocalStorage) blobPath(id string) string {
+	return filepath.Join(s.root, "blobs", id[len(id)-2:], id)
+}
+
+func sum(h hash.Hash) [sha256.Size]byte {
+	var digest [sha256.Size]byte
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// contextReader stops reading once ctx is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs"
+)
+
+// ErrNotFound is returned when no content is stored under a CID
+var ErrNotFound = errors.New("content not found")
+
+// Object describes stored content
+type Object struct {
+	CID  string `json:"cid"`
+	Size int64  `json:"size"`
+}
+
+// Storage stores immutable content addressed by CID.
+//
+// Put pins the content it stores; Unpin releases it so the backend may discard
+// it. Get returns a seekable reader so downloads can serve range requests.
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader) (*Object, error)
+	Get(ctx context.Context, cid string) (io.ReadSeekCloser, error)
+	Stat(ctx context.Context, cid string) (*Object, error)
+	Pin(ctx context.Context, cid string) error
+	Unpin(ctx context.Context, cid string) error
+}
+
+// New creates the storage backend selected by cfg.Backend
+func New(cfg config.IPFSConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "ipfs":
+		return NewIPFSStorage(ipfs.NewClient(cfg.APIURL)), nil
+	case "local":
+		return NewLocalStorage(cfg.LocalPath)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Backend)
+	}
+}
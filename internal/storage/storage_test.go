@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/cid"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs/ipfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "%PDF-1.4 0123456789 %%EOF"
+
+// testBackends runs fn against every backend
+func testBackends(t *testing.T, fn func(t *testing.T, s Storage)) {
+	t.Run("local", func(t *testing.T) {
+		s, err := NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+		fn(t, s)
+	})
+	t.Run("ipfs", func(t *testing.T) {
+		node := ipfstest.NewServer()
+		defer node.Close()
+		fn(t, NewIPFSStorage(ipfs.NewClient(node.URL)))
+	})
+}
+
+func TestPutGetStat(t *testing.T) {
+	testBackends(t, func(t *testing.T, s Storage) {
+		ctx := context.Background()
+
+		obj, err := s.Put(ctx, "paper.pdf", strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, cid.Sum([]byte(content)), obj.CID)
+		assert.Equal(t, int64(len(content)), obj.Size)
+
+		stat, err := s.Stat(ctx, obj.CID)
+		require.NoError(t, err)
+		assert.Equal(t, obj, stat)
+
+		r, err := s.Get(ctx, obj.CID)
+		require.NoError(t, err)
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+
+		// Seek back into the middle, as range requests do
+		pos, err := r.Seek(9, io.SeekStart)
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), pos)
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(buf))
+
+		end, err := r.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), end)
+	})
+}
+
+func TestUnpinAndNotFound(t *testing.T) {
+	testBackends(t, func(t *testing.T, s Storage) {
+		ctx := context.Background()
+		missing := cid.Sum([]byte("missing"))
+
+		_, err := s.Stat(ctx, missing)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = s.Get(ctx, missing)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, s.Unpin(ctx, missing), ErrNotFound)
+
+		obj, err := s.Put(ctx, "paper.pdf", strings.NewReader(content))
+		require.NoError(t, err)
+		assert.NoError(t, s.Unpin(ctx, obj.CID))
+		assert.ErrorIs(t, s.Unpin(ctx, obj.CID), ErrNotFound)
+	})
+}
+
+func TestLocalStorageLayout(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	obj, err := s.Put(ctx, "paper.pdf", strings.NewReader(content))
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(root, "blobs", obj.CID[len(obj.CID)-2:], obj.CID))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "pins", obj.CID))
+	assert.NoError(t, err)
+
+	tmp, err := os.ReadDir(filepath.Join(root, "tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, tmp)
+
+	// Unpinning removes the content immediately
+	require.NoError(t, s.Unpin(ctx, obj.CID))
+	_, err = s.Stat(ctx, obj.CID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Only canonical CIDs map to files
+	for _, id := range []string{"../pins/" + obj.CID, strings.ToUpper(obj.CID), "bafkrei"} {
+		_, err := s.Get(ctx, id)
+		assert.ErrorIs(t, err, ErrNotFound, id)
+	}
+}
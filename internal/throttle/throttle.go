@@ -0,0 +1,100 @@
+// Package throttle slows down and temporarily locks out subjects that fail
+// repeatedly, such as password guesses against one account or from one
+// address. State is kept in the database so every instance enforces it.
+package throttle
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Policy decides how a subject is throttled
+type Policy struct {
+	MaxFailures int           // Failures within Window that lock the subject out, 0 never locks
+	Window      time.Duration // Failures older than this are forgotten
+	Lockout     time.Duration // How long a lockout lasts
+	BaseDelay   time.Duration // Wait required after the second failure, doubled for each further one
+	MaxDelay    time.Duration // Upper bound of the wait, 0 leaves it unbounded
+}
+
+// Delay returns the wait required after failures recent failures
+func (p Policy) Delay(failures int) time.Duration {
+	if p.BaseDelay <= 0 || failures < 2 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 2; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Wait returns how long a subject in state must wait at now before its next attempt
+func (p Policy) Wait(state *models.ThrottleState, now time.Time) time.Duration {
+	if state.LockedUntil != nil && now.Before(*state.LockedUntil) {
+		return state.LockedUntil.Sub(now)
+	}
+	if now.Sub(state.LastFailureAt) > p.Window {
+		return 0
+	}
+	if next := state.LastFailureAt.Add(p.Delay(state.Failures)); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// Throttle applies policies to subjects
+type Throttle struct {
+	repo *repository.ThrottleRepository
+}
+
+// New creates a throttle keeping its state in repo
+func New(repo *repository.ThrottleRepository) *Throttle {
+	return &Throttle{repo: repo}
+}
+
+// Wait returns how long subject must wait at now before its next attempt, 0 if it may go ahead
+func (t *Throttle) Wait(subject string, policy Policy, now time.Time) (time.Duration, error) {
+	state, err := t.repo.Get(subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return policy.Wait(state, now), nil
+}
+
+// Fail counts a failure of subject at now. It reports whether the failure
+// locked the subject out.
+func (t *Throttle) Fail(subject string, policy Policy, now time.Time) (bool, error) {
+	state, err := t.repo.AddFailure(subject, now, now.Add(-policy.Window))
+	if err != nil {
+		return false, err
+	}
+	if policy.MaxFailures <= 0 || state.Failures < policy.MaxFailures {
+		return false, nil
+	}
+	return t.repo.Lock(subject, policy.MaxFailures, now.Add(policy.Lockout))
+}
+
+// Reset forgets the failures and lockout of subject
+func (t *Throttle) Reset(subject string) error {
+	return t.repo.Delete(subject)
+}
+
+// Prune removes state that no policy with a window up to maxWindow still needs
+func (t *Throttle) Prune(now time.Time, maxWindow time.Duration) error {
+	return t.repo.DeleteStale(now.Add(-maxWindow))
+}
@@ -0,0 +1,69 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Duration(0), policy.Delay(1))
+	assert.Equal(t, time.Second, policy.Delay(2))
+	assert.Equal(t, 2*time.Second, policy.Delay(3))
+	assert.Equal(t, 4*time.Second, policy.Delay(4))
+	assert.Equal(t, 5*time.Second, policy.Delay(5))
+	assert.Equal(t, 5*time.Second, policy.Delay(100))
+}
+
+func TestThrottle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ThrottleState{}))
+	throttle := New(repository.NewThrottleRepository(db))
+
+	policy := Policy{MaxFailures: 3, Window: time.Minute, Lockout: 10 * time.Minute, BaseDelay: time.Second}
+	now := time.Now()
+
+	wait, err := throttle.Wait("subject", policy, now)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The second failure requires a wait
+	for i := 0; i < 2; i++ {
+		locked, err := throttle.Fail("subject", policy, now)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	}
+	wait, err = throttle.Wait("subject", policy, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, wait)
+
+	// The third locks the subject out
+	locked, err := throttle.Fail("subject", policy, now.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.True(t, locked)
+	wait, err = throttle.Wait("subject", policy, now.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, wait)
+
+	// Once the lockout has ended, failures start over
+	later := now.Add(time.Hour)
+	locked, err = throttle.Fail("subject", policy, later)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	wait, err = throttle.Wait("subject", policy, later)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	require.NoError(t, throttle.Prune(later.Add(2*time.Minute), time.Minute))
+	_, err = repository.NewThrottleRepository(db).Get("subject")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -5,34 +5,85 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/nshmdayo/nft-platform-sample/internal/keyset"
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid,omitempty"`
+	MFASetup  bool   `json:"mfa_setup,omitempty"` // Only valid for setting up required two-factor authentication
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email, role, secret string, expiresIn time.Duration) (string, error) {
+// JWTOptions describes how access tokens are signed and verified
+type JWTOptions struct {
+	Keys     *keyset.KeySet
+	Issuer   string
+	Audience string
+}
+
+// GenerateJWT signs an access token for a session and returns it with its
+// unique ID (jti). Tokens issued with mfaSetup are limited to setting up
+// two-factor authentication.
+func GenerateJWT(opts JWTOptions, userID uint, email, role string, sessionID uint, mfaSetup bool, expiresIn time.Duration) (string, string, error) {
+	key := opts.Keys.Signing()
+	if key == nil {
+		return "", "", errors.New("no signing key available")
+	}
+
+	jti, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		MFASetup:  mfaSetup,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			Issuer:    opts.Issuer,
+			Audience:  jwt.ClaimStrings{opts.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.PrivateKey())
+	if err != nil {
+		return "", "", err
+	}
+	return signed, jti, nil
 }
 
-func ValidateJWT(tokenString, secret string) (*Claims, error) {
+// ValidateJWT verifies an access token against the keyset. Only the asymmetric
+// algorithms of the keyset are accepted, and the token's alg must match the
+// algorithm of the key named by its kid, which rules out "none" and tricking
+// the verifier into treating a public key as an HMAC secret.
+func ValidateJWT(tokenString string, opts JWTOptions) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := opts.Keys.Lookup(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("signing algorithm does not match key")
+		}
+		return key.PublicKey(), nil
+	},
+		jwt.WithValidMethods([]string{keyset.AlgRS256, keyset.AlgEdDSA}),
+		jwt.WithIssuer(opts.Issuer),
+		jwt.WithAudience(opts.Audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,276 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// IndexerCheckpoint is the name of the checkpoint kept by the chain indexer
+const IndexerCheckpoint = "nft-transfers"
+
+// ChainIndexerConfig tunes the chain indexer
+type ChainIndexerConfig struct {
+	Contracts     map[string]string // Contract address to the NFT type it mints
+	Confirmations uint64            // Blocks a log must be buried under before it is applied
+	StartBlock    uint64            // First block scanned when there is no checkpoint
+	BatchBlocks   uint64            // Maximum blocks requested per eth_getLogs call
+	PollInterval  time.Duration     // How often the chain head is polled once caught up
+}
+
+// ChainIndexer follows the Transfer events of the paper and review contracts
+// and reconciles token ownership with transfers and burns made outside the API.
+//
+// Only blocks with at least Confirmations blocks on top are processed, so
+// shallow reorganisations never reach the database. If the checkpointed block
+// is nevertheless replaced, the indexer reverts the transfers of the last
+// Confirmations blocks and scans them again.
+type ChainIndexer struct {
+	cfg            ChainIndexerConfig
+	client         *ethereum.Client
+	nftRepo        *repository.NFTRepository
+	checkpointRepo *repository.CheckpointRepository
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewChainIndexer creates a chain indexer; call Start to begin following the chain
+func NewChainIndexer(cfg ChainIndexerConfig, client *ethereum.Client, nftRepo *repository.NFTRepository, checkpointRepo *repository.CheckpointRepository) *ChainIndexer {
+	if cfg.BatchBlocks == 0 {
+		cfg.BatchBlocks = 1000
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 15 * time.Second
+	}
+
+	contracts := make(map[string]string, len(cfg.Contracts))
+	for addr, nftType := range cfg.Contracts {
+		contracts[strings.ToLower(addr)] = nftType
+	}
+	cfg.Contracts = contracts
+
+	return &ChainIndexer{
+		cfg:            cfg,
+		client:         client,
+		nftRepo:        nftRepo,
+		checkpointRepo: checkpointRepo,
+	}
+}
+
+// Start launches the indexer goroutine
+func (i *ChainIndexer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	i.wg.Add(1)
+	go i.run(ctx)
+
+	logger.Info("Chain indexer started", "confirmations", i.cfg.Confirmations, "start_block", i.cfg.StartBlock)
+}
+
+// Stop stops the indexer and waits for it to exit or ctx to expire
+func (i *ChainIndexer) Stop(ctx context.Context) error {
+	if i.cancel == nil {
+		return nil
+	}
+	i.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Chain indexer stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (i *ChainIndexer) run(ctx context.Context) {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Catch up with the chain before sleeping again
+		for ctx.Err() == nil {
+			progressed, err := i.SyncNext(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("Chain indexer failed", "error", err)
+				}
+				break
+			}
+			if !progressed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncNext processes the next batch of confirmed blocks, or rewinds if the
+// checkpointed block was reorganised away. It reports whether the checkpoint moved.
+func (i *ChainIndexer) SyncNext(ctx context.Context) (bool, error) {
+	head, err := i.client.BlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+	if head < i.cfg.Confirmations {
+		return false, nil
+	}
+	safe := head - i.cfg.Confirmations
+
+	from := i.cfg.StartBlock
+	checkpoint, err := i.checkpointRepo.Get(IndexerCheckpoint)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return false, err
+	default:
+		header, err := i.client.HeaderByNumber(ctx, checkpoint.BlockNumber)
+		if err != nil {
+			return false, err
+		}
+		if header == nil || !strings.EqualFold(header.Hash, checkpoint.BlockHash) {
+			return true, i.rewind(ctx, checkpoint)
+		}
+		from = checkpoint.BlockNumber + 1
+	}
+
+	if from > safe {
+		return false, nil
+	}
+	to := from + i.cfg.BatchBlocks - 1
+	if to > safe {
+		to = safe
+	}
+
+	logs, err := i.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: i.contracts(),
+		Topics:    [][]string{{nft.TransferTopic}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, log := range logs {
+		if err := i.apply(log); err != nil {
+			return false, err
+		}
+	}
+
+	if err := i.saveCheckpoint(ctx, to); err != nil {
+		return false, err
+	}
+
+	logger.Debug("Chain indexer processed blocks", "from", from, "to", to, "logs", len(logs))
+	return true, nil
+}
+
+// apply records the ownership change of a Transfer log
+func (i *ChainIndexer) apply(log ethereum.Log) error {
+	if log.Removed || !nft.IsTransferLog(log) {
+		return nil
+	}
+	nftType, ok := i.cfg.Contracts[strings.ToLower(log.Address)]
+	if !ok {
+		return nil
+	}
+
+	event, err := nft.DecodeTransferLog(log)
+	if err != nil {
+		// A malformed log would otherwise block the indexer forever
+		logger.Warn("Skipping undecodable Transfer log", "tx_hash", log.TxHash, "error", err)
+		return nil
+	}
+
+	applied, err := i.nftRepo.ApplyChainTransfer(nftType, event.TokenID, &models.NFTTransfer{
+		FromAddr:    event.From.Hex(),
+		ToAddr:      event.To.Hex(),
+		TxHash:      event.TxHash,
+		BlockNumber: event.BlockNumber,
+	})
+	if err != nil {
+		return fmt.Errorf("apply transfer %s of %s token %d: %w", event.TxHash, nftType, event.TokenID, err)
+	}
+	if applied {
+		logger.Info("Indexed NFT transfer", "type", nftType, "token_id", event.TokenID, "from", event.From.Hex(), "to", event.To.Hex(), "tx_hash", event.TxHash)
+	}
+	return nil
+}
+
+// rewind reverts the transfers of the last Confirmations blocks up to the
+// checkpoint and moves the checkpoint before them so they are scanned again
+func (i *ChainIndexer) rewind(ctx context.Context, checkpoint *models.ChainCheckpoint) error {
+	depth := i.cfg.Confirmations
+	if depth == 0 {
+		depth = 1
+	}
+
+	if checkpoint.BlockNumber < i.cfg.StartBlock+depth {
+		// Everything processed so far is rewound
+		reverted, err := i.nftRepo.RevertTransfersFrom(i.cfg.StartBlock)
+		if err != nil {
+			return err
+		}
+		logger.Warn("Chain reorganisation detected, rescanning from start block", "checkpoint", checkpoint.BlockNumber, "reverted_transfers", reverted)
+		return i.checkpointRepo.Delete(IndexerCheckpoint)
+	}
+
+	keep := checkpoint.BlockNumber - depth
+	reverted, err := i.nftRepo.RevertTransfersFrom(keep + 1)
+	if err != nil {
+		return err
+	}
+	logger.Warn("Chain reorganisation detected, rewinding indexer", "checkpoint", checkpoint.BlockNumber, "rescan_from", keep+1, "reverted_transfers", reverted)
+
+	return i.saveCheckpoint(ctx, keep)
+}
+
+func (i *ChainIndexer) saveCheckpoint(ctx context.Context, blockNumber uint64) error {
+	header, err := i.client.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return fmt.Errorf("block %d not found", blockNumber)
+	}
+
+	return i.checkpointRepo.Save(&models.ChainCheckpoint{
+		Name:        IndexerCheckpoint,
+		BlockNumber: blockNumber,
+		BlockHash:   header.Hash,
+	})
+}
+
+func (i *ChainIndexer) contracts() []string {
+	addresses := make([]string, 0, len(i.cfg.Contracts))
+	for addr := range i.cfg.Contracts {
+		addresses = append(addresses, addr)
+	}
+	return addresses
+}
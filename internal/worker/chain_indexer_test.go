@@ -0,0 +1,248 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testPaperContract  = "0x00000000000000000000000000000000000000a1"
+	testReviewContract = "0x00000000000000000000000000000000000000a2"
+)
+
+// fakeChain is a JSON-RPC node serving blocks and Transfer logs. Reorganising
+// it replaces the hashes and logs from a block onwards.
+type fakeChain struct {
+	mu         sync.Mutex
+	head       uint64
+	generation map[uint64]int
+	logs       []ethereum.Log
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{generation: make(map[uint64]int)}
+}
+
+func (c *fakeChain) hash(number uint64) string {
+	return fmt.Sprintf("0x%062x%02x", number, c.generation[number])
+}
+
+// transfer emits a Transfer log of tokenID in block number
+func (c *fakeChain) transfer(contract string, number uint64, from, to string, tokenID uint, txHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logs = append(c.logs, ethereum.Log{
+		Address:     contract,
+		Topics:      []string{nft.TransferTopic, addressTopic(from), addressTopic(to), fmt.Sprintf("0x%064x", tokenID)},
+		Data:        "0x",
+		BlockNumber: ethereum.EncodeQuantity(number),
+		TxHash:      txHash,
+	})
+}
+
+// reorg replaces block number and its descendants with blocks without logs
+func (c *fakeChain) reorg(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for n := number; n <= c.head; n++ {
+		c.generation[n]++
+	}
+	kept := c.logs[:0]
+	for _, log := range c.logs {
+		if n, _ := ethereum.DecodeQuantity(log.BlockNumber); n < number {
+			kept = append(kept, log)
+		}
+	}
+	c.logs = kept
+}
+
+func (c *fakeChain) setHead(head uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head = head
+}
+
+func (c *fakeChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result interface{}
+	switch req.Method {
+	case "eth_blockNumber":
+		result = ethereum.EncodeQuantity(c.head)
+	case "eth_getBlockByNumber":
+		var quantity string
+		json.Unmarshal(req.Params[0], &quantity)
+		number, _ := ethereum.DecodeQuantity(quantity)
+		if number <= c.head {
+			result = map[string]string{"number": quantity, "hash": c.hash(number)}
+		}
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock string   `json:"fromBlock"`
+			ToBlock   string   `json:"toBlock"`
+			Address   []string `json:"address"`
+		}
+		json.Unmarshal(req.Params[0], &filter)
+		from, _ := ethereum.DecodeQuantity(filter.FromBlock)
+		to, _ := ethereum.DecodeQuantity(filter.ToBlock)
+
+		logs := []ethereum.Log{}
+		for _, log := range c.logs {
+			n, _ := ethereum.DecodeQuantity(log.BlockNumber)
+			if n < from || n > to || !containsFold(filter.Address, log.Address) {
+				continue
+			}
+			log.BlockHash = c.hash(n)
+			logs = append(logs, log)
+		}
+		result = logs
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0", "id": req.ID,
+			"error": map[string]interface{}{"code": -32601, "message": "method not found"},
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func addressTopic(addr string) string {
+	return "0x000000000000000000000000" + strings.TrimPrefix(addr, "0x")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestChainIndexerReconcilesTransfersAndReorgs(t *testing.T) {
+	const (
+		alice = "0x1111111111111111111111111111111111111111"
+		bob   = "0x2222222222222222222222222222222222222222"
+		carol = "0x3333333333333333333333333333333333333333"
+		dave  = "0x4444444444444444444444444444444444444444"
+	)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Paper{}, &models.Review{}, &models.NFTMetadata{}, &models.NFTTransfer{}, &models.ChainCheckpoint{}))
+
+	nftRepo := repository.NewNFTRepository(db)
+	checkpointRepo := repository.NewCheckpointRepository(db)
+
+	// Token 1 was minted to alice through the API in block 2
+	minted := &models.NFTMetadata{TokenID: 1, Type: models.NFTTypePaper, ReferenceID: 1, TxHash: "0xmint", BlockNumber: 2, OwnerAddr: alice}
+	require.NoError(t, nftRepo.RecordMint(minted))
+
+	chain := newFakeChain()
+	chain.transfer(testPaperContract, 2, models.ZeroAddress, alice, 1, "0xmint")
+	chain.transfer(testPaperContract, 4, alice, bob, 1, "0xt1")
+	chain.transfer(testReviewContract, 5, alice, bob, 7, "0xforeign") // not minted by the platform
+	chain.transfer(testPaperContract, 9, bob, carol, 1, "0xt2")
+	chain.setHead(10)
+
+	server := httptest.NewServer(chain)
+	t.Cleanup(server.Close)
+
+	newIndexer := func() *ChainIndexer {
+		return NewChainIndexer(ChainIndexerConfig{
+			Contracts: map[string]string{
+				testPaperContract:  models.NFTTypePaper,
+				testReviewContract: models.NFTTypeReview,
+			},
+			Confirmations: 3,
+			BatchBlocks:   4,
+		}, ethereum.NewClient(server.URL), nftRepo, checkpointRepo)
+	}
+	catchUp := func(indexer *ChainIndexer) {
+		for {
+			progressed, err := indexer.SyncNext(context.Background())
+			require.NoError(t, err)
+			if !progressed {
+				return
+			}
+		}
+	}
+	owner := func() string {
+		record, err := nftRepo.GetByToken(models.NFTTypePaper, 1)
+		require.NoError(t, err)
+		return strings.ToLower(record.OwnerAddr)
+	}
+	history := func() []string {
+		transfers, err := nftRepo.GetTransfers(minted.ID)
+		require.NoError(t, err)
+		var hashes []string
+		for _, transfer := range transfers {
+			hashes = append(hashes, transfer.TxHash)
+		}
+		return hashes
+	}
+
+	indexer := newIndexer()
+	catchUp(indexer)
+
+	// Blocks up to 7 are confirmed; the transfer in block 9 is not yet applied
+	checkpoint, err := checkpointRepo.Get(IndexerCheckpoint)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), checkpoint.BlockNumber)
+	assert.Equal(t, bob, owner())
+	assert.Equal(t, []string{"0xmint", "0xt1"}, history())
+
+	// A restarted indexer continues from the checkpoint
+	chain.setHead(12)
+	indexer = newIndexer()
+	catchUp(indexer)
+	assert.Equal(t, carol, owner())
+	assert.Equal(t, []string{"0xmint", "0xt1", "0xt2"}, history())
+
+	// Block 9 is reorganised away and the token goes to dave in block 10 instead
+	chain.reorg(9)
+	chain.transfer(testPaperContract, 10, bob, dave, 1, "0xt3")
+	chain.setHead(14)
+	catchUp(indexer)
+	assert.Equal(t, dave, owner())
+	assert.Equal(t, []string{"0xmint", "0xt1", "0xt3"}, history())
+
+	// Burns move the token to the zero address
+	chain.transfer(testPaperContract, 12, dave, models.ZeroAddress, 1, "0xburn")
+	chain.setHead(15)
+	catchUp(indexer)
+	assert.Equal(t, models.ZeroAddress, owner())
+
+	var foreign int64
+	db.Model(&models.NFTMetadata{}).Where("type = ?", models.NFTTypeReview).Count(&foreign)
+	assert.Zero(t, foreign)
+}
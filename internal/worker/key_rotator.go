@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/keyset"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// KeyRotatorConfig tunes the signing key rotator
+type KeyRotatorConfig struct {
+	Interval      time.Duration // Age at which the signing key is replaced, 0 disables rotation
+	Retention     time.Duration // How long replaced keys keep verifying tokens
+	CheckInterval time.Duration // How often the key directory is checked
+}
+
+// KeyRotator periodically replaces the JWT signing key and prunes retired keys.
+// Each check also reloads the key directory, so instances sharing it pick up
+// keys generated by one another.
+type KeyRotator struct {
+	cfg  KeyRotatorConfig
+	keys *keyset.KeySet
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewKeyRotator creates a key rotator; call Start to begin rotating
+func NewKeyRotator(cfg KeyRotatorConfig, keys *keyset.KeySet) *KeyRotator {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Minute
+	}
+	return &KeyRotator{cfg: cfg, keys: keys}
+}
+
+// Start launches the rotator goroutine
+func (k *KeyRotator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+
+	k.wg.Add(1)
+	go k.run(ctx)
+
+	logger.Info("Key rotator started", "interval", k.cfg.Interval, "retention", k.cfg.Retention)
+}
+
+// Stop stops the rotator and waits for it to exit or ctx to expire
+func (k *KeyRotator) Stop(ctx context.Context) error {
+	if k.cancel == nil {
+		return nil
+	}
+	k.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		k.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Key rotator stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (k *KeyRotator) run(ctx context.Context) {
+	defer k.wg.Done()
+
+	ticker := time.NewTicker(k.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := k.RotateNext(time.Now()); err != nil {
+			logger.Error("Key rotation failed", "error", err)
+		}
+	}
+}
+
+// RotateNext rotates the signing key if it is due and prunes retired keys
+func (k *KeyRotator) RotateNext(now time.Time) error {
+	rotated, err := k.keys.RotateIfDue(now, k.cfg.Interval, k.cfg.Retention)
+	if err != nil {
+		return err
+	}
+	if rotated {
+		logger.Info("JWT signing key rotated", "kid", k.keys.Signing().ID)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	apperrors "github.com/nshmdayo/nft-platform-sample/internal/errors"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// MintWorkerConfig tunes the mint worker pool
+type MintWorkerConfig struct {
+	Workers      int           // Number of concurrent workers
+	PollInterval time.Duration // How often idle workers look for due jobs
+	Lease        time.Duration // How long a claimed job is reserved for its worker
+	BaseBackoff  time.Duration // Delay before the first retry; doubled on every further attempt
+	MaxBackoff   time.Duration // Upper bound of the retry delay
+}
+
+// MintWorker processes queued mint jobs in the background
+type MintWorker struct {
+	cfg        MintWorkerConfig
+	jobRepo    *repository.MintJobRepository
+	nftService *service.NFTService
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewMintWorker creates a mint worker pool; call Start to begin processing
+func NewMintWorker(cfg MintWorkerConfig, jobRepo *repository.MintJobRepository, nftService *service.NFTService) *MintWorker {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 5 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+
+	return &MintWorker{
+		cfg:        cfg,
+		jobRepo:    jobRepo,
+		nftService: nftService,
+	}
+}
+
+// Start launches the worker goroutines
+func (w *MintWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	for i := 0; i < w.cfg.Workers; i++ {
+		w.wg.Add(1)
+		go w.run(ctx, i)
+	}
+
+	logger.Info("Mint worker started", "workers", w.cfg.Workers)
+}
+
+// Stop cancels in-flight jobs and waits for the workers to exit or ctx to expire.
+// Cancelled jobs are left queued (or submitted) and picked up on the next start.
+func (w *MintWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Mint worker stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *MintWorker) run(ctx context.Context, id int) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain all due jobs before sleeping again
+		for ctx.Err() == nil {
+			processed, err := w.ProcessNext(ctx)
+			if err != nil {
+				logger.Error("Failed to claim mint job", "worker", id, "error", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessNext claims and processes one due job. It reports whether a job was processed.
+func (w *MintWorker) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := w.jobRepo.ClaimNext(time.Now(), w.cfg.Lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	w.process(ctx, job)
+	return true, nil
+}
+
+func (w *MintWorker) process(ctx context.Context, job *models.MintJob) {
+	job.Attempts++
+
+	// Record the transaction hash as soon as it is broadcast so a crash while
+	// waiting for confirmation resumes the transaction instead of minting twice.
+	hooked := nft.WithSubmittedHook(ctx, func(txHash string) {
+		job.Status = models.MintJobStatusSubmitted
+		job.TxHash = txHash
+		if err := w.jobRepo.Update(job); err != nil {
+			logger.Error("Failed to record submitted mint job", "job_id", job.ID, "error", err)
+		}
+	})
+
+	metadata, err := w.nftService.ExecuteMintJob(hooked, job)
+	job.LockedUntil = nil
+
+	switch {
+	case err == nil:
+		job.Status = models.MintJobStatusConfirmed
+		job.TokenID = &metadata.TokenID
+		job.TxHash = metadata.TxHash
+		job.LastError = ""
+		logger.Info("Mint job confirmed", "job_id", job.ID, "type", job.Type, "reference_id", job.ReferenceID, "token_id", metadata.TokenID)
+
+	case ctx.Err() != nil:
+		// Shutting down: release the job without counting the attempt
+		job.Attempts--
+		job.NextRunAt = time.Now()
+		logger.Info("Mint job interrupted by shutdown", "job_id", job.ID)
+
+	case isPermanent(err) || job.Attempts >= job.MaxAttempts:
+		job.Status = models.MintJobStatusFailed
+		job.LastError = err.Error()
+		logger.Error("Mint job dead-lettered", "job_id", job.ID, "attempts", job.Attempts, "error", err)
+
+	default:
+		job.LastError = err.Error()
+		job.NextRunAt = time.Now().Add(w.backoff(job.Attempts))
+		logger.Warn("Mint job failed, retrying", "job_id", job.ID, "attempts", job.Attempts, "next_run_at", job.NextRunAt, "error", err)
+	}
+
+	if err := w.jobRepo.Update(job); err != nil {
+		logger.Error("Failed to update mint job", "job_id", job.ID, "error", err)
+	}
+}
+
+// backoff returns the delay before the given attempt is retried
+func (w *MintWorker) backoff(attempts int) time.Duration {
+	delay := w.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < w.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.cfg.MaxBackoff {
+		delay = w.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// isPermanent reports whether retrying err cannot succeed, e.g. the paper is no
+// longer published or the recipient has no wallet
+func isPermanent(err error) bool {
+	if errors.Is(err, service.ErrUnresumableMint) {
+		return true
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.StatusCode < http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/config"
+	"github.com/nshmdayo/nft-platform-sample/internal/models"
+	"github.com/nshmdayo/nft-platform-sample/internal/nft"
+	"github.com/nshmdayo/nft-platform-sample/internal/repository"
+	"github.com/nshmdayo/nft-platform-sample/internal/service"
+	"github.com/nshmdayo/nft-platform-sample/internal/storage"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// flakyMinter fails the first failures mints, then delegates to a simulated chain
+type flakyMinter struct {
+	*nft.SimulatedChain
+	failures int
+	calls    int
+}
+
+func (m *flakyMinter) MintPaper(ctx context.Context, paper *models.Paper) (*nft.MintResult, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, errors.New("node unavailable")
+	}
+	return m.SimulatedChain.MintPaper(ctx, paper)
+}
+
+func setupWorker(t *testing.T, minter nft.Minter, maxAttempts int) (*MintWorker, *repository.MintJobRepository, *service.NFTService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.User{}, &models.Paper{}, &models.Review{}, &models.NFTMetadata{}, &models.NFTTransfer{}, &models.MintJob{}))
+
+	wallet := "0x1111111111111111111111111111111111111111"
+	owner := models.User{Email: "author@example.com", Name: "Author", WalletAddr: &wallet}
+	assert.NoError(t, db.Create(&owner).Error)
+	assert.NoError(t, db.Create(&models.Paper{Title: "Paper", OwnerID: owner.ID, Status: models.PaperStatusPublished}).Error)
+
+	jobRepo := repository.NewMintJobRepository(db)
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+	nftService := service.NewNFTService(
+		repository.NewNFTRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewPaperRepository(db),
+		repository.NewReviewRepository(db),
+		jobRepo,
+		minter,
+		fileStorage,
+		&config.Config{MintQueue: config.MintQueueConfig{MaxAttempts: maxAttempts}},
+	)
+
+	// Near-zero backoff so retried jobs are due again immediately
+	w := NewMintWorker(MintWorkerConfig{BaseBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}, jobRepo, nftService)
+	return w, jobRepo, nftService
+}
+
+func TestMintWorkerRetriesWithBackoff(t *testing.T) {
+	minter := &flakyMinter{SimulatedChain: nft.NewSimulatedChain("", ""), failures: 2}
+	w, jobRepo, nftService := setupWorker(t, minter, 5)
+
+	job, err := nftService.RequestPaperMint(1, 1)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		processed, err := w.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, processed)
+	}
+
+	processed, err := w.ProcessNext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, processed)
+
+	job, err = jobRepo.GetByID(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MintJobStatusConfirmed, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, uint(1), *job.TokenID)
+	assert.NotEmpty(t, job.TxHash)
+	assert.Empty(t, job.LastError)
+}
+
+func TestMintWorkerDeadLettersAfterMaxAttempts(t *testing.T) {
+	minter := &flakyMinter{SimulatedChain: nft.NewSimulatedChain("", ""), failures: 10}
+	w, jobRepo, nftService := setupWorker(t, minter, 2)
+
+	job, err := nftService.RequestPaperMint(1, 1)
+	assert.NoError(t, err)
+
+	for {
+		processed, err := w.ProcessNext(context.Background())
+		assert.NoError(t, err)
+		if !processed {
+			break
+		}
+	}
+
+	job, err = jobRepo.GetByID(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MintJobStatusFailed, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Contains(t, job.LastError, "node unavailable")
+	assert.Equal(t, 2, minter.calls)
+
+	// Requesting again revives a dead-lettered job that never reached the chain
+	job, err = nftService.RequestPaperMint(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MintJobStatusQueued, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+}
+
+func TestMintWorkerDoesNotResendSubmittedJob(t *testing.T) {
+	chain := nft.NewSimulatedChain("", "")
+	w, jobRepo, nftService := setupWorker(t, chain, 5)
+
+	job, err := nftService.RequestPaperMint(1, 1)
+	assert.NoError(t, err)
+
+	// Simulate a worker that crashed after broadcasting the transaction
+	job.Status = models.MintJobStatusSubmitted
+	job.TxHash = "0xabc"
+	assert.NoError(t, jobRepo.Update(job))
+
+	processed, err := w.ProcessNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	job, err = jobRepo.GetByID(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MintJobStatusFailed, job.Status)
+	assert.Equal(t, service.ErrUnresumableMint.Error(), job.LastError)
+	assert.Equal(t, uint64(0), chain.BlockNumber())
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/internal/throttle"
+	"github.com/nshmdayo/nft-platform-sample/pkg/logger"
+)
+
+// ThrottlePrunerConfig tunes the throttle pruner
+type ThrottlePrunerConfig struct {
+	MaxWindow time.Duration // Longest window or lockout of any throttle policy
+	Interval  time.Duration // How often stale state is removed
+}
+
+// ThrottlePruner periodically removes throttle state that has expired, so
+// addresses and emails that failed once do not accumulate forever
+type ThrottlePruner struct {
+	cfg      ThrottlePrunerConfig
+	throttle *throttle.Throttle
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewThrottlePruner creates a throttle pruner; call Start to begin pruning
+func NewThrottlePruner(cfg ThrottlePrunerConfig, t *throttle.Throttle) *ThrottlePruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	return &ThrottlePruner{cfg: cfg, throttle: t}
+}
+
+// Start launches the pruner goroutine
+func (p *ThrottlePruner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.run(ctx)
+
+	logger.Info("Throttle pruner started", "interval", p.cfg.Interval, "max_window", p.cfg.MaxWindow)
+}
+
+// Stop stops the pruner and waits for it to exit or ctx to expire
+func (p *ThrottlePruner) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Throttle pruner stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *ThrottlePruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.throttle.Prune(time.Now(), p.cfg.MaxWindow); err != nil {
+			logger.Error("Throttle pruning failed", "error", err)
+		}
+	}
+}
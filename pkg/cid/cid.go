@@ -0,0 +1,61 @@
+// Package cid implements version 1 content identifiers for raw sha2-256
+// content, the form IPFS assigns to single-block files added with
+// --cid-version=1, so identifiers computed locally match those of an IPFS node.
+package cid
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+)
+
+const (
+	version1     = 0x01
+	codecRaw     = 0x55
+	hashSHA2_256 = 0x12
+
+	// multibase prefix of lowercase RFC 4648 base32 without padding
+	base32Prefix = 'b'
+)
+
+// ErrInvalid is returned for strings that are not CIDv1 raw sha2-256 identifiers
+var ErrInvalid = errors.New("not a CIDv1 raw sha2-256 identifier")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// prefix is the binary CID header: version, codec, multihash code and digest length.
+// All values are below 0x80 and therefore encode as single-byte varints.
+var prefix = []byte{version1, codecRaw, hashSHA2_256, sha256.Size}
+
+// Sum returns the CID of data
+func Sum(data []byte) string {
+	return FromSHA256(sha256.Sum256(data))
+}
+
+// FromSHA256 returns the CID of content with the given sha2-256 digest
+func FromSHA256(digest [sha256.Size]byte) string {
+	raw := append(append([]byte{}, prefix...), digest[:]...)
+	return string(base32Prefix) + strings.ToLower(encoding.EncodeToString(raw))
+}
+
+// ParseSHA256 validates s and returns the sha2-256 digest it identifies
+func ParseSHA256(s string) ([sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	if len(s) < 2 || s[0] != base32Prefix {
+		return digest, ErrInvalid
+	}
+
+	raw, err := encoding.DecodeString(strings.ToUpper(s[1:]))
+	if err != nil || len(raw) != len(prefix)+sha256.Size {
+		return digest, ErrInvalid
+	}
+	for i, b := range prefix {
+		if raw[i] != b {
+			return digest, ErrInvalid
+		}
+	}
+
+	copy(digest[:], raw[len(prefix):])
+	return digest, nil
+}
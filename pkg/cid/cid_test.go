@@ -0,0 +1,35 @@
+package cid
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Identifiers as reported by `ipfs add --cid-version=1`
+func TestSumMatchesIPFS(t *testing.T) {
+	assert.Equal(t, "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku", Sum(nil))
+	assert.Equal(t, "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e", Sum([]byte("hello world")))
+}
+
+func TestParseSHA256(t *testing.T) {
+	data := []byte("manuscript")
+	digest, err := ParseSHA256(Sum(data))
+	require.NoError(t, err)
+	assert.Equal(t, sha256.Sum256(data), digest)
+
+	for _, invalid := range []string{
+		"",
+		"b",
+		"QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG",              // CIDv0
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", // dag-pb
+		"bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyk",  // truncated
+		"bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyk/", // path separator
+		"../../etc/passwd",
+	} {
+		_, err := ParseSHA256(invalid)
+		assert.ErrorIs(t, err, ErrInvalid, invalid)
+	}
+}
@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FunctionSelector returns the 4-byte selector of a canonical function signature
+// such as "mintPaper(address,string,string)"
+func FunctionSelector(signature string) []byte {
+	return Keccak256([]byte(signature))[:4]
+}
+
+// EventTopic returns the topic hash of a canonical event signature
+func EventTopic(signature string) string {
+	return EncodeHex(Keccak256([]byte(signature)))
+}
+
+// EncodeCall ABI-encodes a contract call. Supported argument types are
+// Address, *big.Int, uint64, uint8 and string, which covers the PaperNFT and
+// ReviewNFT contract methods.
+func EncodeCall(signature string, args ...interface{}) ([]byte, error) {
+	head := make([]byte, 0, 32*len(args))
+	var tail []byte
+
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case Address:
+			head = append(head, leftPad32(v[:])...)
+		case *big.Int:
+			if v.Sign() < 0 {
+				return nil, fmt.Errorf("abi: argument %d: negative integers are not supported", i)
+			}
+			head = append(head, leftPad32(v.Bytes())...)
+		case uint64:
+			head = append(head, leftPad32(new(big.Int).SetUint64(v).Bytes())...)
+		case uint8:
+			head = append(head, leftPad32([]byte{v})...)
+		case string:
+			// Dynamic types store the offset of their data, measured from the start of the arguments
+			offset := uint64(32*len(args) + len(tail))
+			head = append(head, leftPad32(new(big.Int).SetUint64(offset).Bytes())...)
+			tail = append(tail, leftPad32(new(big.Int).SetInt64(int64(len(v))).Bytes())...)
+			tail = append(tail, rightPad32([]byte(v))...)
+		default:
+			return nil, fmt.Errorf("abi: argument %d: unsupported type %T", i, arg)
+		}
+	}
+
+	data := append([]byte{}, FunctionSelector(signature)...)
+	data = append(data, head...)
+	return append(data, tail...), nil
+}
+
+func leftPad32(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
+
+func rightPad32(b []byte) []byte {
+	size := (len(b) + 31) / 32 * 32
+	out := make([]byte, size)
+	copy(out, b)
+	return out
+}
@@ -0,0 +1,260 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Client is a minimal Ethereum JSON-RPC client
+type Client struct {
+	url    string
+	http   *http.Client
+	nextID atomic.Uint64
+}
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// CallMsg describes a call used for eth_estimateGas and eth_call
+type CallMsg struct {
+	From  Address
+	To    Address
+	Data  []byte
+	Value *big.Int
+}
+
+// Log is an event log entry as returned by the node
+type Log struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockNumber string   `json:"blockNumber"`
+	BlockHash   string   `json:"blockHash"`
+	TxHash      string   `json:"transactionHash"`
+	LogIndex    string   `json:"logIndex"`
+	Removed     bool     `json:"removed"`
+}
+
+// Header is the subset of a block header needed to follow the chain
+type Header struct {
+	Number     string `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+}
+
+// FilterQuery selects the logs returned by eth_getLogs
+type FilterQuery struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Addresses []string
+	Topics    [][]string // Accepted values per topic position; an empty position matches anything
+}
+
+// Receipt is a transaction receipt as returned by the node
+type Receipt struct {
+	TxHash      string `json:"transactionHash"`
+	BlockNumber string `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	Status      string `json:"status"`
+	GasUsed     string `json:"gasUsed"`
+	Logs        []Log  `json:"logs"`
+}
+
+// Succeeded reports whether the transaction executed without reverting
+func (r *Receipt) Succeeded() bool {
+	status, err := DecodeQuantity(r.Status)
+	return err == nil && status == 1
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// NewClient creates a client for the node at url
+func NewClient(url string) *Client {
+	return &Client{
+		url:  url,
+		http: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Call invokes a JSON-RPC method and decodes the result into result.
+// A JSON null result leaves result untouched.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected HTTP status %d", method, resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil || len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// ChainID returns the chain ID used for EIP-155 signing
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var hex string
+	if err := c.Call(ctx, &hex, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return DecodeBigQuantity(hex)
+}
+
+// BlockNumber returns the number of the latest block
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var hex string
+	if err := c.Call(ctx, &hex, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return DecodeQuantity(hex)
+}
+
+// PendingNonceAt returns the next nonce for account, including pending transactions
+func (c *Client) PendingNonceAt(ctx context.Context, account Address) (uint64, error) {
+	var hex string
+	if err := c.Call(ctx, &hex, "eth_getTransactionCount", account.Hex(), "pending"); err != nil {
+		return 0, err
+	}
+	return DecodeQuantity(hex)
+}
+
+// GasPrice returns the node's suggested gas price
+func (c *Client) GasPrice(ctx context.Context) (*big.Int, error) {
+	var hex string
+	if err := c.Call(ctx, &hex, "eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	return DecodeBigQuantity(hex)
+}
+
+// EstimateGas estimates the gas needed to execute msg
+func (c *Client) EstimateGas(ctx context.Context, msg CallMsg) (uint64, error) {
+	var hex string
+	if err := c.Call(ctx, &hex, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return DecodeQuantity(hex)
+}
+
+// SendRawTransaction broadcasts a signed transaction and returns its hash
+func (c *Client) SendRawTransaction(ctx context.Context, raw []byte) (string, error) {
+	var hash string
+	if err := c.Call(ctx, &hash, "eth_sendRawTransaction", EncodeHex(raw)); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// TransactionReceipt returns the receipt of a mined transaction, or nil while it is pending
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*Receipt, error) {
+	var receipt *Receipt
+	if err := c.Call(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// HeaderByNumber returns the header of a block, or nil if the node does not have it
+func (c *Client) HeaderByNumber(ctx context.Context, number uint64) (*Header, error) {
+	var header *Header
+	if err := c.Call(ctx, &header, "eth_getBlockByNumber", EncodeQuantity(number), false); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// FilterLogs returns the logs matching q in the order they were emitted
+func (c *Client) FilterLogs(ctx context.Context, q FilterQuery) ([]Log, error) {
+	filter := map[string]interface{}{
+		"fromBlock": EncodeQuantity(q.FromBlock),
+		"toBlock":   EncodeQuantity(q.ToBlock),
+	}
+	if len(q.Addresses) > 0 {
+		filter["address"] = q.Addresses
+	}
+	if len(q.Topics) > 0 {
+		topics := make([]interface{}, len(q.Topics))
+		for i, alternatives := range q.Topics {
+			if len(alternatives) > 0 {
+				topics[i] = alternatives
+			}
+		}
+		filter["topics"] = topics
+	}
+
+	var logs []Log
+	if err := c.Call(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func toCallArg(msg CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From.Hex(),
+		"to":   msg.To.Hex(),
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = EncodeHex(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = EncodeBigQuantity(msg.Value)
+	}
+	return arg
+}
@@ -0,0 +1,154 @@
+// Package ethereum provides the minimal Ethereum primitives the platform needs:
+// keccak hashing, addresses, secp256k1 signing, RLP and ABI encoding, and a
+// JSON-RPC client. It intentionally avoids pulling in go-ethereum.
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressLength is the length of an Ethereum address in bytes
+const AddressLength = 20
+
+// Address is a 20-byte Ethereum account or contract address
+type Address [AddressLength]byte
+
+// ZeroAddress is the all-zero address used as the sender of mint Transfer events
+var ZeroAddress Address
+
+// Keccak256 returns the legacy Keccak-256 hash of the concatenated data
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// HexToAddress parses a 0x-prefixed, 40 hex character address
+func HexToAddress(s string) (Address, error) {
+	var addr Address
+
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(raw) != AddressLength*2 {
+		return addr, fmt.Errorf("invalid address length: %q", s)
+	}
+
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return addr, fmt.Errorf("invalid address: %q", s)
+	}
+
+	copy(addr[:], b)
+	return addr, nil
+}
+
+// IsHexAddress reports whether s is a syntactically valid address
+func IsHexAddress(s string) bool {
+	_, err := HexToAddress(s)
+	return err == nil
+}
+
+// Hex returns the EIP-55 mixed-case checksum encoding of the address
+func (a Address) Hex() string {
+	lower := hex.EncodeToString(a[:])
+	hash := Keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i := range out {
+		if out[i] < 'a' {
+			continue
+		}
+		// Each hex character is upper-cased when the matching nibble of the hash is >= 8
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] -= 'a' - 'A'
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+func (a Address) String() string {
+	return a.Hex()
+}
+
+// PrivateKeyFromHex parses a hex encoded secp256k1 private key, with or without 0x prefix
+func PrivateKeyFromHex(s string) (*secp256k1.PrivateKey, error) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	b, err := hex.DecodeString(raw)
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("private key must be 32 hex encoded bytes")
+	}
+	return secp256k1.PrivKeyFromBytes(b), nil
+}
+
+// PubkeyToAddress derives the address controlled by a public key
+func PubkeyToAddress(pub *secp256k1.PublicKey) Address {
+	var addr Address
+	// Uncompressed encoding is 0x04 || X || Y; the address is the last 20 bytes of keccak(X || Y)
+	hash := Keccak256(pub.SerializeUncompressed()[1:])
+	copy(addr[:], hash[12:])
+	return addr
+}
+
+// Sign signs a 32-byte hash and returns the 65-byte [R || S || V] signature with V in {0, 1}
+func Sign(hash []byte, key *secp256k1.PrivateKey) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+
+	compact := ecdsa.SignCompact(key, hash, false)
+
+	// Compact signatures are [27 + V || R || S]
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = compact[0] - 27
+	return sig, nil
+}
+
+// HashPersonalMessage returns the hash signed by personal_sign (EIP-191 version 0x45):
+// keccak256("\x19Ethereum Signed Message:\n" || len(message) || message)
+func HashPersonalMessage(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// RecoverAddress returns the address whose key produced a 65-byte [R || S || V]
+// signature of hash. V may be given as {0, 1} or, as wallets do, {27, 28}.
+func RecoverAddress(hash, sig []byte) (Address, error) {
+	if len(hash) != 32 {
+		return Address{}, fmt.Errorf("hash must be 32 bytes, got %d", len(hash))
+	}
+	if len(sig) != 65 {
+		return Address{}, fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return Address{}, errors.New("invalid signature recovery id")
+	}
+
+	compact := make([]byte, 65)
+	compact[0] = v + 27
+	copy(compact[1:], sig[:64])
+
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return Address{}, err
+	}
+	return PubkeyToAddress(pub), nil
+}
@@ -0,0 +1,82 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The example transaction from EIP-155
+func TestSignLegacyTxEIP155Vector(t *testing.T) {
+	key, err := PrivateKeyFromHex("0x4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+
+	to, err := HexToAddress("0x3535353535353535353535353535353535353535")
+	require.NoError(t, err)
+
+	value, _ := new(big.Int).SetString("1000000000000000000", 10)
+	raw, _, err := SignLegacyTx(&LegacyTx{
+		Nonce:    9,
+		GasPrice: big.NewInt(20000000000),
+		Gas:      21000,
+		To:       &to,
+		Value:    value,
+	}, big.NewInt(1), key)
+	require.NoError(t, err)
+
+	assert.Equal(t, "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83", hex.EncodeToString(raw))
+	assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", PubkeyToAddress(key.PubKey()).Hex())
+}
+
+func TestAddressChecksum(t *testing.T) {
+	addr, err := HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	require.NoError(t, err)
+	assert.Equal(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", addr.Hex())
+
+	assert.False(t, IsHexAddress("0x1234"))
+}
+
+func TestRecoverPersonalSignature(t *testing.T) {
+	key, err := PrivateKeyFromHex("0x4646464646464646464646464646464646464646464646464646464646464646")
+	require.NoError(t, err)
+
+	hash := HashPersonalMessage([]byte("hello"))
+	sig, err := Sign(hash, key)
+	require.NoError(t, err)
+
+	addr, err := RecoverAddress(hash, sig)
+	require.NoError(t, err)
+	assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", addr.Hex())
+
+	// Wallets return V as 27 or 28
+	sig[64] += 27
+	addr, err = RecoverAddress(hash, sig)
+	require.NoError(t, err)
+	assert.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", addr.Hex())
+
+	// A signature of another message recovers another address
+	addr, err = RecoverAddress(HashPersonalMessage([]byte("hello!")), sig)
+	if err == nil {
+		assert.NotEqual(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", addr.Hex())
+	}
+
+	sig[64] = 5
+	_, err = RecoverAddress(hash, sig)
+	assert.Error(t, err)
+}
+
+func TestEncodeCall(t *testing.T) {
+	to, _ := HexToAddress("0x00000000000000000000000000000000000000ff")
+	data, err := EncodeCall("mintPaper(address,string,string)", to, "Title", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, FunctionSelector("mintPaper(address,string,string)"), data[:4])
+	// selector + 3 head words + (length + 1 data word) + (length word)
+	assert.Len(t, data, 4+32*3+32*2+32)
+	// First string offset points right after the head
+	assert.Equal(t, byte(0x60), data[4+32*2-1])
+	assert.Equal(t, "Title", string(data[4+32*4:4+32*4+5]))
+}
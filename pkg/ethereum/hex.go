@@ -0,0 +1,55 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// EncodeHex encodes bytes as 0x-prefixed hex
+func EncodeHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// DecodeHex decodes 0x-prefixed hex data
+func DecodeHex(s string) ([]byte, error) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(raw)%2 == 1 {
+		raw = "0" + raw
+	}
+	return hex.DecodeString(raw)
+}
+
+// EncodeQuantity encodes an integer as a JSON-RPC quantity, e.g. 0x1a
+func EncodeQuantity(v uint64) string {
+	return "0x" + strconv.FormatUint(v, 16)
+}
+
+// EncodeBigQuantity encodes a big integer as a JSON-RPC quantity
+func EncodeBigQuantity(v *big.Int) string {
+	return "0x" + v.Text(16)
+}
+
+// DecodeQuantity decodes a JSON-RPC quantity into a uint64
+func DecodeQuantity(s string) (uint64, error) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if raw == "" {
+		return 0, fmt.Errorf("invalid quantity: %q", s)
+	}
+	return strconv.ParseUint(raw, 16, 64)
+}
+
+// DecodeBigQuantity decodes a JSON-RPC quantity or 32-byte word into a big integer
+func DecodeBigQuantity(s string) (*big.Int, error) {
+	raw := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if raw == "" {
+		return nil, fmt.Errorf("invalid quantity: %q", s)
+	}
+	v, ok := new(big.Int).SetString(raw, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantity: %q", s)
+	}
+	return v, nil
+}
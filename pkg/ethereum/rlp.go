@@ -0,0 +1,62 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// encodeRLP encodes []byte, string, uint64, *big.Int, Address, *Address and
+// []interface{} values using Ethereum's recursive length prefix encoding.
+func encodeRLP(item interface{}) ([]byte, error) {
+	switch v := item.(type) {
+	case []byte:
+		return rlpBytes(v), nil
+	case string:
+		return rlpBytes([]byte(v)), nil
+	case uint64:
+		return rlpBytes(new(big.Int).SetUint64(v).Bytes()), nil
+	case *big.Int:
+		if v == nil {
+			return rlpBytes(nil), nil
+		}
+		if v.Sign() < 0 {
+			return nil, fmt.Errorf("rlp: cannot encode negative integer %s", v)
+		}
+		return rlpBytes(v.Bytes()), nil
+	case Address:
+		return rlpBytes(v[:]), nil
+	case *Address:
+		// A nil recipient encodes as the empty string (contract creation)
+		if v == nil {
+			return rlpBytes(nil), nil
+		}
+		return rlpBytes(v[:]), nil
+	case []interface{}:
+		var payload []byte
+		for _, elem := range v {
+			enc, err := encodeRLP(elem)
+			if err != nil {
+				return nil, err
+			}
+			payload = append(payload, enc...)
+		}
+		return append(rlpHeader(0xc0, len(payload)), payload...), nil
+	default:
+		return nil, fmt.Errorf("rlp: unsupported type %T", item)
+	}
+}
+
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	lenBytes := new(big.Int).SetInt64(int64(length)).Bytes()
+	return append([]byte{offset + 55 + byte(len(lenBytes))}, lenBytes...)
+}
@@ -0,0 +1,54 @@
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// LegacyTx is a pre-EIP-1559 transaction, signed with EIP-155 replay protection
+type LegacyTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *Address
+	Value    *big.Int
+	Data     []byte
+}
+
+// SignLegacyTx signs tx for chainID and returns the raw RLP encoded
+// transaction together with its 0x-prefixed hash.
+func SignLegacyTx(tx *LegacyTx, chainID *big.Int, key *secp256k1.PrivateKey) ([]byte, string, error) {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	// EIP-155 signing payload: [nonce, gasPrice, gas, to, value, data, chainID, 0, 0]
+	unsigned, err := encodeRLP([]interface{}{
+		tx.Nonce, tx.GasPrice, tx.Gas, tx.To, value, tx.Data,
+		chainID, uint64(0), uint64(0),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sig, err := Sign(Keccak256(unsigned), key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// v = recovery id + chainID * 2 + 35
+	v := new(big.Int).Mul(chainID, big.NewInt(2))
+	v.Add(v, big.NewInt(35+int64(sig[64])))
+
+	raw, err := encodeRLP([]interface{}{
+		tx.Nonce, tx.GasPrice, tx.Gas, tx.To, value, tx.Data,
+		v, new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return raw, EncodeHex(Keccak256(raw)), nil
+}
@@ -0,0 +1,190 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client is a minimal client for the Kubo (go-ipfs) HTTP RPC API
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// Error is an error returned by the Kubo API
+type Error struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"Message"`
+	Code       int    `json:"Code"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ipfs api error (HTTP %d): %s", e.StatusCode, e.Message)
+}
+
+// AddResult is the response of /api/v0/add
+type AddResult struct {
+	Name string `json:"Name"`
+	Hash string `json:"Hash"` // CID of the added content
+	Size string `json:"Size"` // Size of the DAG in bytes, as a decimal string
+}
+
+// NewClient creates a client for the Kubo API at apiURL, e.g. http://localhost:5001.
+// Requests have no overall timeout since uploads and downloads are streamed;
+// bound them with the request context instead.
+func NewClient(apiURL string) *Client {
+	return &Client{
+		url:  strings.TrimRight(apiURL, "/"),
+		http: &http.Client{},
+	}
+}
+
+// Add streams r to the node as a file named name, pins it and returns its CIDv1
+func (c *Client) Add(ctx context.Context, name string, r io.Reader) (*AddResult, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	// Write the multipart body while the request is being sent
+	go func() {
+		part, err := mw.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	params := url.Values{}
+	params.Set("cid-version", "1")
+	params.Set("pin", "true")
+	params.Set("progress", "false")
+
+	resp, err := c.post(ctx, "add", params, pr, mw.FormDataContentType())
+	if err != nil {
+		// Unblock the writer goroutine if the request failed before draining the body
+		pr.CloseWithError(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result AddResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("add: invalid response: %w", err)
+	}
+	if result.Hash == "" {
+		return nil, fmt.Errorf("add: response has no hash")
+	}
+
+	return &result, nil
+}
+
+// FileStat is the response of /api/v0/files/stat
+type FileStat struct {
+	Hash           string `json:"Hash"`
+	Size           int64  `json:"Size"`           // Size of the file content in bytes
+	CumulativeSize int64  `json:"CumulativeSize"` // Size of the file including DAG overhead
+	Type           string `json:"Type"`           // file or directory
+}
+
+// Cat returns a reader over the content of cid. The caller must close it.
+func (c *Client) Cat(ctx context.Context, cid string) (io.ReadCloser, error) {
+	return c.CatRange(ctx, cid, 0, -1)
+}
+
+// CatRange returns a reader over length bytes of cid starting at offset.
+// A negative length reads to the end. The caller must close it.
+func (c *Client) CatRange(ctx context.Context, cid string, offset, length int64) (io.ReadCloser, error) {
+	params := url.Values{"arg": {cid}}
+	if offset > 0 {
+		params.Set("offset", strconv.FormatInt(offset, 10))
+	}
+	if length >= 0 {
+		params.Set("length", strconv.FormatInt(length, 10))
+	}
+
+	resp, err := c.post(ctx, "cat", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat returns the size and type of the content identified by cid
+func (c *Client) Stat(ctx context.Context, cid string) (*FileStat, error) {
+	resp, err := c.post(ctx, "files/stat", url.Values{"arg": {"/ipfs/" + cid}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var stat FileStat
+	if err := json.NewDecoder(resp.Body).Decode(&stat); err != nil {
+		return nil, fmt.Errorf("files/stat: invalid response: %w", err)
+	}
+	return &stat, nil
+}
+
+// Pin pins cid recursively on the node
+func (c *Client) Pin(ctx context.Context, cid string) error {
+	resp, err := c.post(ctx, "pin/add", url.Values{"arg": {cid}}, nil, "")
+	if err != nil {
+		return err
+	}
+	return drain(resp)
+}
+
+// Unpin removes the recursive pin of cid so the node may garbage collect it
+func (c *Client) Unpin(ctx context.Context, cid string) error {
+	resp, err := c.post(ctx, "pin/rm", url.Values{"arg": {cid}}, nil, "")
+	if err != nil {
+		return err
+	}
+	return drain(resp)
+}
+
+// post calls an API command. Kubo only accepts POST for RPC commands.
+func (c *Client) post(ctx context.Context, command string, params url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	endpoint := c.url + "/api/v0/" + command
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%s: %w", command, apiErr)
+	}
+
+	return resp, nil
+}
+
+func drain(resp *http.Response) error {
+	defer resp.Body.Close()
+	_, err := io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,90 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/ipfs/ipfstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCatAndPin(t *testing.T) {
+	node := ipfstest.NewServer()
+	defer node.Close()
+
+	client := NewClient(node.URL + "/")
+	ctx := context.Background()
+	content := "%PDF-1.4 manuscript"
+
+	result, err := client.Add(ctx, "paper.pdf", strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, ipfstest.CID([]byte(content)), result.Hash)
+	assert.Equal(t, "paper.pdf", result.Name)
+	assert.True(t, node.Pinned(result.Hash))
+
+	body, err := client.Cat(ctx, result.Hash)
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	body, err = client.CatRange(ctx, result.Hash, 5, 3)
+	require.NoError(t, err)
+	data, err = io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "1.4", string(data))
+
+	stat, err := client.Stat(ctx, result.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), stat.Size)
+	assert.Equal(t, "file", stat.Type)
+
+	require.NoError(t, client.Unpin(ctx, result.Hash))
+	assert.False(t, node.Pinned(result.Hash))
+
+	require.NoError(t, client.Pin(ctx, result.Hash))
+	assert.True(t, node.Pinned(result.Hash))
+}
+
+func TestAPIErrors(t *testing.T) {
+	node := ipfstest.NewServer()
+	defer node.Close()
+
+	client := NewClient(node.URL)
+
+	_, err := client.Cat(context.Background(), "bafkmissing")
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Message, "not found")
+
+	err = client.Unpin(context.Background(), "bafkmissing")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "not pinned or pinned indirectly", apiErr.Message)
+}
+
+// A failing reader aborts the upload instead of storing a truncated file
+func TestAddPropagatesReadError(t *testing.T) {
+	node := ipfstest.NewServer()
+	defer node.Close()
+
+	client := NewClient(node.URL)
+	readErr := errors.New("disk on fire")
+
+	_, err := client.Add(context.Background(), "paper.pdf", io.MultiReader(strings.NewReader("partial"), errReader{readErr}))
+	assert.Error(t, err)
+
+	_, stored := node.Content(ipfstest.CID([]byte("partial")))
+	assert.False(t, stored)
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,180 @@
+// Package ipfstest provides an in-memory fake of the Kubo HTTP RPC API for tests.
+package ipfstest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/cid"
+)
+
+// Server fakes the /api/v0/add, /cat, /files/stat, /pin/add and /pin/rm commands
+// of a Kubo node. Content is stored as a single raw block, so identifiers are
+// the CIDv1 a real node assigns to files that fit in one chunk.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	blocks map[string][]byte
+	pins   map[string]bool
+}
+
+// NewServer starts a fake Kubo node. Callers must call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		blocks: make(map[string][]byte),
+		pins:   make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/add", s.add)
+	mux.HandleFunc("POST /api/v0/cat", s.cat)
+	mux.HandleFunc("POST /api/v0/files/stat", s.stat)
+	mux.HandleFunc("POST /api/v0/pin/add", s.pinAdd)
+	mux.HandleFunc("POST /api/v0/pin/rm", s.pinRm)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// CID returns the identifier the fake assigns to data
+func CID(data []byte) string {
+	return cid.Sum(data)
+}
+
+// Content returns the data stored under cid
+func (s *Server) Content(cid string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blocks[cid]
+	return data, ok
+}
+
+// Pinned reports whether cid is pinned
+func (s *Server) Pinned(cid string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pins[cid]
+}
+
+func (s *Server) add(w http.ResponseWriter, r *http.Request) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	part, err := mr.NextPart()
+	if err != nil {
+		writeError(w, "file argument 'path' is required")
+		return
+	}
+	data, err := io.ReadAll(part)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	cid := CID(data)
+	s.mu.Lock()
+	s.blocks[cid] = data
+	if r.URL.Query().Get("pin") != "false" {
+		s.pins[cid] = true
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]string{
+		"Name": part.FileName(),
+		"Hash": cid,
+		"Size": strconv.Itoa(len(data)),
+	})
+}
+
+func (s *Server) cat(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data, ok := s.Content(query.Get("arg"))
+	if !ok {
+		writeError(w, "block was not found locally (offline): ipld: could not find node")
+		return
+	}
+
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil {
+		if offset > len(data) {
+			offset = len(data)
+		}
+		data = data[offset:]
+	}
+	if length, err := strconv.Atoi(query.Get("length")); err == nil && length < len(data) {
+		data = data[:length]
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write(data)
+}
+
+func (s *Server) stat(w http.ResponseWriter, r *http.Request) {
+	cid := strings.TrimPrefix(r.URL.Query().Get("arg"), "/ipfs/")
+	data, ok := s.Content(cid)
+	if !ok {
+		writeError(w, "block was not found locally (offline): ipld: could not find node")
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"Hash":           cid,
+		"Size":           len(data),
+		"CumulativeSize": len(data),
+		"Blocks":         0,
+		"Type":           "file",
+	})
+}
+
+func (s *Server) pinAdd(w http.ResponseWriter, r *http.Request) {
+	cid := r.URL.Query().Get("arg")
+	s.mu.Lock()
+	_, ok := s.blocks[cid]
+	if ok {
+		s.pins[cid] = true
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, "pin: block was not found locally (offline)")
+		return
+	}
+	writeJSON(w, map[string][]string{"Pins": {cid}})
+}
+
+func (s *Server) pinRm(w http.ResponseWriter, r *http.Request) {
+	cid := r.URL.Query().Get("arg")
+	s.mu.Lock()
+	pinned := s.pins[cid]
+	delete(s.pins, cid)
+	s.mu.Unlock()
+
+	if !pinned {
+		writeError(w, "not pinned or pinned indirectly")
+		return
+	}
+	writeJSON(w, map[string][]string{"Pins": {cid}})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError mimics Kubo's error envelope, which is always sent with HTTP 500
+func writeError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"Message": message,
+		"Code":    0,
+		"Type":    "error",
+	})
+}
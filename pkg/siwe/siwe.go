@@ -0,0 +1,227 @@
+// Package siwe parses and formats Sign-In with Ethereum messages (EIP-4361).
+// Checking the signature, nonce, domain and validity window is left to the
+// caller, which knows what it issued.
+package siwe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nshmdayo/nft-platform-sample/pkg/ethereum"
+)
+
+const (
+	headerSuffix = " wants you to sign in with your Ethereum account:"
+	version      = "1"
+)
+
+// Message is a Sign-In with Ethereum message
+type Message struct {
+	Domain         string
+	Address        string // EIP-55 checksummed
+	Statement      string // Optional
+	URI            string
+	Version        string
+	ChainID        uint64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time // Optional
+	NotBefore      *time.Time // Optional
+	RequestID      string     // Optional
+	Resources      []string   // Optional
+}
+
+// ParseMessage parses the text of a message as signed by the wallet
+func ParseMessage(text string) (*Message, error) {
+	p := &parser{lines: strings.Split(text, "\n")}
+	m := &Message{}
+
+	header := p.next()
+	if !strings.HasSuffix(header, headerSuffix) {
+		return nil, errors.New("missing sign-in header")
+	}
+	m.Domain = strings.TrimSuffix(header, headerSuffix)
+	if m.Domain == "" || strings.ContainsAny(m.Domain, " /") {
+		return nil, fmt.Errorf("invalid domain %q", m.Domain)
+	}
+
+	m.Address = p.next()
+	addr, err := ethereum.HexToAddress(m.Address)
+	if err != nil {
+		return nil, err
+	}
+	if addr.Hex() != m.Address {
+		return nil, errors.New("address must be EIP-55 checksummed")
+	}
+
+	if p.next() != "" {
+		return nil, errors.New("expected empty line after address")
+	}
+	// The statement, if any, is followed by an empty line
+	if line := p.peek(); line != "" && !strings.HasPrefix(line, "URI: ") {
+		m.Statement = p.next()
+	}
+	if p.next() != "" {
+		return nil, errors.New("expected empty line before fields")
+	}
+
+	if m.URI, err = p.field("URI", true); err != nil {
+		return nil, err
+	}
+	if m.Version, err = p.field("Version", true); err != nil {
+		return nil, err
+	}
+	if m.Version != version {
+		return nil, fmt.Errorf("unsupported version %q", m.Version)
+	}
+
+	chainID, err := p.field("Chain ID", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseUint(chainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid chain ID %q", chainID)
+	}
+
+	if m.Nonce, err = p.field("Nonce", true); err != nil {
+		return nil, err
+	}
+	if !validNonce(m.Nonce) {
+		return nil, errors.New("nonce must be at least 8 alphanumeric characters")
+	}
+
+	issuedAt, err := p.field("Issued At", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return nil, fmt.Errorf("invalid issued at time: %w", err)
+	}
+
+	if m.ExpirationTime, err = p.timeField("Expiration Time"); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = p.timeField("Not Before"); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = p.field("Request ID", false); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == "Resources:" {
+		p.next()
+		for strings.HasPrefix(p.peek(), "- ") {
+			m.Resources = append(m.Resources, strings.TrimPrefix(p.next(), "- "))
+		}
+	}
+
+	// A trailing newline is tolerated, anything else is not
+	if p.pos < len(p.lines) && !(p.pos == len(p.lines)-1 && p.lines[p.pos] == "") {
+		return nil, fmt.Errorf("unexpected line %q", p.lines[p.pos])
+	}
+	return m, nil
+}
+
+// String formats the message as presented to the wallet for signing
+func (m *Message) String() string {
+	var b strings.Builder
+	b.WriteString(m.Domain + headerSuffix + "\n")
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", m.Version)
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s", m.IssuedAt.UTC().Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		fmt.Fprintf(&b, "\nExpiration Time: %s", m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		fmt.Fprintf(&b, "\nNot Before: %s", m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			b.WriteString("\n- " + resource)
+		}
+	}
+	return b.String()
+}
+
+// Valid reports whether the message may be used at now
+func (m *Message) Valid(now time.Time) error {
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return errors.New("message has expired")
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return errors.New("message is not yet valid")
+	}
+	return nil
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.lines) {
+		return ""
+	}
+	return p.lines[p.pos]
+}
+
+func (p *parser) next() string {
+	line := p.peek()
+	p.pos++
+	return line
+}
+
+// field reads the "Name: value" line if it comes next
+func (p *parser) field(name string, required bool) (string, error) {
+	prefix := name + ": "
+	if !strings.HasPrefix(p.peek(), prefix) {
+		if required {
+			return "", fmt.Errorf("missing %s", name)
+		}
+		return "", nil
+	}
+	value := strings.TrimPrefix(p.next(), prefix)
+	if value == "" {
+		return "", fmt.Errorf("empty %s", name)
+	}
+	return value, nil
+}
+
+func (p *parser) timeField(name string) (*time.Time, error) {
+	value, err := p.field(name, false)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", strings.ToLower(name), err)
+	}
+	return &t, nil
+}
+
+func validNonce(nonce string) bool {
+	if len(nonce) < 8 {
+		return false
+	}
+	for _, c := range nonce {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package siwe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exampleMessage is the example from EIP-4361
+const exampleMessage = `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ServiceOrg Terms of Service: https://service.invalid/tos
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseMessage(t *testing.T) {
+	m, err := ParseMessage(exampleMessage)
+	require.NoError(t, err)
+
+	assert.Equal(t, "service.invalid", m.Domain)
+	assert.Equal(t, "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", m.Address)
+	assert.Equal(t, "I accept the ServiceOrg Terms of Service: https://service.invalid/tos", m.Statement)
+	assert.Equal(t, "https://service.invalid/login", m.URI)
+	assert.Equal(t, uint64(1), m.ChainID)
+	assert.Equal(t, "32891756", m.Nonce)
+	assert.Equal(t, time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC), m.IssuedAt)
+	assert.Len(t, m.Resources, 2)
+
+	// Formatting reproduces the signed text
+	assert.Equal(t, exampleMessage, m.String())
+}
+
+func TestParseMessageWithoutStatement(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &Message{
+		Domain:         "papers.example.com",
+		Address:        "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+		URI:            "https://papers.example.com",
+		Version:        "1",
+		ChainID:        11155111,
+		Nonce:          "abcdef0123456789",
+		IssuedAt:       time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC),
+		ExpirationTime: &expires,
+	}
+
+	parsed, err := ParseMessage(m.String())
+	require.NoError(t, err)
+	assert.Equal(t, m, parsed)
+
+	assert.NoError(t, parsed.Valid(expires.Add(-time.Second)))
+	assert.Error(t, parsed.Valid(expires))
+}
+
+func TestParseMessageRejectsMalformed(t *testing.T) {
+	for name, text := range map[string]string{
+		"lowercase address": `service.invalid wants you to sign in with your Ethereum account:
+0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z`,
+		"short nonce": `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 1234
+Issued At: 2021-09-30T16:25:24Z`,
+		"missing nonce": `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Issued At: 2021-09-30T16:25:24Z`,
+		"trailing data": exampleMessage + "\nExtra: field",
+		"not siwe":      "hello",
+	} {
+		_, err := ParseMessage(text)
+		assert.Error(t, err, name)
+	}
+}